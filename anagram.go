@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// runAnagram prints the words that can be made from a rack. It returns the
+// exit code.
+func runAnagram(args []string) int {
+	flags := newFlagSet("anagram", "[-preset name | -config config.yaml] -lexicon words.txt [-min 2] RACK")
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	minLength := flags.Int("min", 2, "minimum length of the words")
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 1) {
+		return exitUsage
+	}
+	return exit(anagram(rules, *lexiconPath, flags.Arg(0), *minLength, format))
+}
+
+func anagram(rules ruleFlags, lexiconPath, rackNotation string, minLength int, format formatFlag) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	_, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	// racks of any size can be anagrammed
+	rack, err := alphabet.ParseRack(rackNotation, len([]rune(rackNotation)), nil)
+	if err != nil {
+		return invalid(fmt.Errorf("rack %v: %w", rackNotation, err))
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+
+	words := []string{}
+	for _, word := range lex.Anagrams(*rack) {
+		if word.Length() >= minLength {
+			words = append(words, alphabet.FormatWord(word))
+		}
+	}
+	if *format.format == "json" {
+		return writeJSON(words)
+	}
+	for _, word := range words {
+		fmt.Println(word)
+	}
+	return nil
+}
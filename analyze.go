@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/api"
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// runAnalyze prints the highest scoring moves for a rack on a board. It
+// returns the exit code.
+func runAnalyze(args []string) int {
+	flags := newFlagSet("analyze", "[-preset name | -config config.yaml] -lexicon words.txt [-n 10] board.txt RACK")
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	limit := flags.Int("n", 10, "number of moves to print, 0 for every move")
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 2) {
+		return exitUsage
+	}
+	return exit(analyze(rules, *lexiconPath, flags.Arg(0), flags.Arg(1), *limit, format))
+}
+
+func analyze(rules ruleFlags, lexiconPath, boardPath, rackNotation string, limit int, format formatFlag) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	letterScores, err := alphabet.LetterMap(config.LetterScores)
+	if err != nil {
+		return err
+	}
+	letterCounts, err := alphabet.LetterMap(config.LetterCounts)
+	if err != nil {
+		return err
+	}
+	rack, err := alphabet.ParseRack(rackNotation, config.RackSize, letterCounts)
+	if err != nil {
+		return invalid(fmt.Errorf("rack %v: %w", rackNotation, err))
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+	board, err := loadBoard(boardPath, config, alphabet, lex, letterScores)
+	if err != nil {
+		return err
+	}
+	moveGenerator, err := newMoveGenerator(config, alphabet, lex)
+	if err != nil {
+		return err
+	}
+
+	moves := moveGenerator.GenerateMoves(board, *rack)
+	if limit > 0 && len(moves) > limit {
+		moves = moves[:limit]
+	}
+	if *format.format == "json" {
+		ranked := make([]api.RankedMove, len(moves))
+		for i, move := range moves {
+			ranked[i] = api.RankedMove{
+				Notation: alphabet.FormatMove(board, move),
+				Move:     alphabet.EncodeMove(move),
+			}
+		}
+		return writeJSON(ranked)
+	}
+	if len(moves) == 0 {
+		fmt.Println("no moves")
+	}
+	for i, move := range moves {
+		fmt.Printf("%3d. %-24v %4d\n", i+1, alphabet.FormatMove(board, move), move.Score)
+	}
+	return nil
+}
+
+// loadBoard loads a board written in the text format of FormatBoard, or the
+// JSON encoding of a board. The first move rules of the board are taken from
+// the configuration.
+func loadBoard(
+	path string,
+	config model.Configuration,
+	alphabet *model.Alphabet,
+	lex *lexicon.TrieNode,
+	letterScores map[rune]int,
+) (model.Board, error) {
+	boardBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return model.Board{}, err
+	}
+	var encoded model.BoardJSON
+	if bytes.HasPrefix(bytes.TrimSpace(boardBytes), []byte("{")) {
+		if err := json.Unmarshal(boardBytes, &encoded); err != nil {
+			return model.Board{}, invalid(fmt.Errorf("%v: %w", path, err))
+		}
+	} else {
+		board, err := alphabet.ParseBoard(string(boardBytes), lex, letterScores)
+		if err != nil {
+			return model.Board{}, invalid(fmt.Errorf("%v: %w", path, err))
+		}
+		encoded = alphabet.EncodeBoard(board)
+	}
+
+	encoded.StartSquares = config.StartSquares
+	encoded.FirstMoveAnywhere = config.FirstMoveAnywhere
+	encoded.MinFirstWordLength = config.MinFirstWordLength
+	board, err := alphabet.DecodeBoard(encoded, lex, letterScores)
+	if err != nil {
+		return model.Board{}, invalid(fmt.Errorf("%v: %w", path, err))
+	}
+	if rows, columns := config.Dimensions(); board.Rows() != rows || board.Columns() != columns {
+		return model.Board{}, invalid(fmt.Errorf(
+			"%v: board is %vx%v but the configuration's board is %vx%v",
+			path, board.Rows(), board.Columns(), rows, columns,
+		))
+	}
+	return board, nil
+}
@@ -1,9 +1,10 @@
 module example.com/unscrabble
 
-go 1.15
+go 1.16
 
 require (
 	github.com/golang/mock v1.6.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/terminal"
+)
+
+// runInteractive plays a game between the person at the terminal and a bot.
+// It returns the exit code.
+func runInteractive(args []string) int {
+	flags := newFlagSet("interactive", "[-preset name | -config config.yaml] -lexicon words.txt [-strategy spec] [-seed n]")
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	botStrategy := flags.String(
+		"strategy",
+		"highscore",
+		"strategy of the bot"+specsUsage,
+	)
+	seed := flags.Int64("seed", 0, "seed for drawing tiles and random strategies, 0 for a seed from the time")
+	name := flags.String("name", "You", "your name")
+	botFirst := flags.Bool("bot-first", false, "let the bot take the first turn")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	return exit(interactive(rules, *lexiconPath, *botStrategy, *seed, *name, *botFirst))
+}
+
+func interactive(rules ruleFlags, lexiconPath, botStrategy string, seed int64, name string, botFirst bool) error {
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	newPicker, err := newPickers([]string{botStrategy}, alphabet)
+	if err != nil {
+		return err
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+	moveGenerator, err := newMoveGenerator(config, alphabet, lex)
+	if err != nil {
+		return err
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	person := model.NewPlayer(name, nil)
+	bot := model.NewPlayer(fmt.Sprintf("Bot (%v)", botStrategy), newPicker[0](moveGenerator, random))
+	players, human := []*model.Player{person, bot}, 0
+	if botFirst {
+		players, human = []*model.Player{bot, person}, 1
+	}
+	game, err := model.NewSeededGame(config, lex, seed, players...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("seed %v\n", seed)
+	return terminal.NewSession(game, alphabet, human, moveGenerator, os.Stdin, os.Stdout).Run()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+
+	"example.com/unscrabble/lexicon"
+)
+
+// runLexicon runs the lexicon subcommands. It returns the exit code.
+func runLexicon(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "compile":
+			return runLexiconCompile(args[1:])
+		case "stats":
+			return runLexiconStats(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "usage: %v lexicon compile [flags] words.txt words.trie\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %v lexicon stats [flags] words.txt\n", os.Args[0])
+	return exitUsage
+}
+
+// runLexiconCompile compiles a word list into a lexicon which is faster to
+// load. It returns the exit code.
+func runLexiconCompile(args []string) int {
+	flags := newFlagSet("lexicon compile", "[-preset name | -config config.yaml] words.txt words.trie")
+	rules := addRuleFlags(flags)
+	if !parseFlags(flags, args, 2) {
+		return exitUsage
+	}
+	return exit(compileLexicon(rules, flags.Arg(0), flags.Arg(1)))
+}
+
+func compileLexicon(rules ruleFlags, wordListPath, compiledPath string) error {
+	_, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	root := lexicon.NewTrieNode()
+	if err := root.InsertWordsFromFile(wordListPath, alphabet); err != nil {
+		return invalid(fmt.Errorf("%v: %w", wordListPath, err))
+	}
+
+	file, err := os.Create(compiledPath)
+	if err != nil {
+		return err
+	}
+	if err := root.WriteCompiled(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("compiled %v words\n", root.Stats().Words)
+	return nil
+}
+
+// runLexiconStats prints the statistics of a word list or compiled lexicon.
+// It returns the exit code.
+func runLexiconStats(args []string) int {
+	flags := newFlagSet("lexicon stats", "[-preset name | -config config.yaml] words.txt")
+	rules := addRuleFlags(flags)
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 1) {
+		return exitUsage
+	}
+	return exit(lexiconStats(rules, flags.Arg(0), format))
+}
+
+func lexiconStats(rules ruleFlags, path string, format formatFlag) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	_, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	root, err := lexicon.Load(path, alphabet)
+	if err != nil {
+		return invalid(err)
+	}
+
+	stats := root.Stats()
+	if *format.format == "json" {
+		return writeJSON(stats)
+	}
+	writer := bufio.NewWriter(os.Stdout)
+	fmt.Fprintf(writer, "words: %v\n", stats.Words)
+	fmt.Fprintf(writer, "nodes: %v\n", stats.Nodes)
+	fmt.Fprintf(writer, "longest word: %v\n", stats.LongestWord)
+	fmt.Fprintln(writer, "words by length:")
+	lengths := make([]int, 0, len(stats.WordsByLength))
+	for length := range stats.WordsByLength {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	for _, length := range lengths {
+		fmt.Fprintf(writer, "  %2d: %v\n", length, stats.WordsByLength[length])
+	}
+	return writer.Flush()
+}
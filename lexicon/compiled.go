@@ -0,0 +1,196 @@
+package lexicon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// compiledHeader starts every compiled lexicon, so that compiled lexicons can
+// be told apart from word lists
+const compiledHeader = "unscrabble trie v1\n"
+
+// WriteCompiled writes the trie rooted at t in a compact binary format which
+// is much faster to load than a word list. The nodes are written depth first,
+// each as a terminal flag and the number of children followed by the letter
+// and node of each child in sorted order, so the same words always compile to
+// the same bytes. The letters are written as they are stored, so a lexicon
+// compiled with an alphabet of multi-character tiles must be loaded for
+// configurations with the same alphabet.
+func (t *TrieNode) WriteCompiled(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	if _, err := writer.WriteString(compiledHeader); err != nil {
+		return err
+	}
+	if err := t.writeNode(writer); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func (t *TrieNode) writeNode(writer *bufio.Writer) error {
+	buffer := make([]byte, binary.MaxVarintLen64)
+	terminal := byte(0)
+	if t.Terminal {
+		terminal = 1
+	}
+	if err := writer.WriteByte(terminal); err != nil {
+		return err
+	}
+	if _, err := writer.Write(buffer[:binary.PutUvarint(buffer, uint64(len(t.NextNodes)))]); err != nil {
+		return err
+	}
+
+	edges := make([]rune, 0, len(t.NextNodes))
+	for edge := range t.NextNodes {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i] < edges[j] })
+	for _, edge := range edges {
+		if _, err := writer.Write(buffer[:binary.PutUvarint(buffer, uint64(edge))]); err != nil {
+			return err
+		}
+		if err := t.NextNodes[edge].writeNode(writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadCompiled reads a trie written by WriteCompiled and returns its root
+func ReadCompiled(r io.Reader) (*TrieNode, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(compiledHeader))
+	if _, err := io.ReadFull(reader, header); err != nil || string(header) != compiledHeader {
+		return nil, errors.New("not a compiled lexicon")
+	}
+	root := NewTrieNode()
+	if err := root.readNode(reader); err != nil {
+		return nil, fmt.Errorf("compiled lexicon is corrupt: %w", err)
+	}
+	return root, nil
+}
+
+func (t *TrieNode) readNode(reader *bufio.Reader) error {
+	terminal, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	t.Terminal = terminal == 1
+	children, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < children; i++ {
+		edge, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return err
+		}
+		child := &TrieNode{
+			Label:     t.Label + string(rune(edge)),
+			NextNodes: make(map[rune]*TrieNode),
+		}
+		if err := child.readNode(reader); err != nil {
+			return err
+		}
+		t.NextNodes[rune(edge)] = child
+	}
+	return nil
+}
+
+// Load loads a lexicon from a file, which is either a compiled lexicon or a
+// word list. The words of a word list are tokenised with alphabet, see
+// InsertWordsFromReader.
+func Load(filePath string, alphabet *model.Alphabet) (*TrieNode, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if header, _ := reader.Peek(len(compiledHeader)); string(header) == compiledHeader {
+		root, err := ReadCompiled(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", filePath, err)
+		}
+		return root, nil
+	}
+	root := NewTrieNode()
+	if err := root.InsertWordsFromReader(reader, alphabet); err != nil {
+		return nil, fmt.Errorf("%v: %w", filePath, err)
+	}
+	return root, nil
+}
+
+// Stats describes the words in a trie
+type Stats struct {
+	Words         int         `json:"words"`
+	Nodes         int         `json:"nodes"`
+	LongestWord   int         `json:"longest_word"`
+	WordsByLength map[int]int `json:"words_by_length"`
+}
+
+// Stats counts the words and nodes of the trie rooted at t, including t
+func (t *TrieNode) Stats() Stats {
+	stats := Stats{WordsByLength: make(map[int]int)}
+	t.addStats(&stats)
+	return stats
+}
+
+func (t *TrieNode) addStats(stats *Stats) {
+	stats.Nodes++
+	if t.Terminal {
+		length := t.Length()
+		stats.Words++
+		stats.WordsByLength[length]++
+		if length > stats.LongestWord {
+			stats.LongestWord = length
+		}
+	}
+	for _, nextNode := range t.NextNodes {
+		nextNode.addStats(stats)
+	}
+}
+
+// Anagrams returns the words in the trie rooted at t which can be made from
+// some or all of the tiles on rack. A blank is only used for a letter when
+// there is not a tile for it left on the rack. The words are sorted from the
+// longest, and alphabetically for words of the same length.
+func (t *TrieNode) Anagrams(rack model.Rack) []model.Word {
+	var words []model.Word
+	t.addAnagrams(rack, nil, &words)
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Length() != words[j].Length() {
+			return words[i].Length() > words[j].Length()
+		}
+		return words[i].Chars < words[j].Chars
+	})
+	return words
+}
+
+func (t *TrieNode) addAnagrams(rack model.Rack, blankTiles []bool, words *[]model.Word) {
+	if t.Terminal && !t.IsRoot() {
+		*words = append(*words, model.Word{
+			Chars:      t.Label,
+			BlankTiles: append([]bool(nil), blankTiles...),
+		})
+	}
+	for edge, nextNode := range t.NextNodes {
+		remaining := rack.Copy()
+		switch {
+		case remaining.HasTile(edge):
+			remaining.RemoveLetter(edge)
+			nextNode.addAnagrams(remaining, append(blankTiles, false), words)
+		case remaining.HasTile(model.BlankTile):
+			remaining.RemoveLetter(model.BlankTile)
+			nextNode.addAnagrams(remaining, append(blankTiles, true), words)
+		}
+	}
+}
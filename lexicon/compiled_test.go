@@ -0,0 +1,86 @@
+package lexicon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	assert "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrie(words ...string) *TrieNode {
+	trie := NewTrieNode()
+	for _, word := range words {
+		trie.Insert(word)
+	}
+	return trie
+}
+
+func TestCompiledRoundTrip(t *testing.T) {
+	trie := newTrie("a", "at", "cat", "cats", "dog")
+
+	var compiled bytes.Buffer
+	require.NoError(t, trie.WriteCompiled(&compiled))
+	var again bytes.Buffer
+	require.NoError(t, newTrie("dog", "cats", "at", "cat", "a").WriteCompiled(&again))
+	assert.Equal(t, compiled.Bytes(), again.Bytes())
+
+	read, err := ReadCompiled(bytes.NewReader(compiled.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, trie, read)
+
+	_, err = ReadCompiled(strings.NewReader("cat\ndog\n"))
+	assert.EqualError(t, err, "not a compiled lexicon")
+	_, err = ReadCompiled(bytes.NewReader(compiled.Bytes()[:compiled.Len()-2]))
+	assert.Error(t, err)
+}
+
+func TestLoadReadsWordListsAndCompiledLexicons(t *testing.T) {
+	directory := t.TempDir()
+	wordList := filepath.Join(directory, "words.txt")
+	require.NoError(t, ioutil.WriteFile(wordList, []byte("CAT\nat\n"), 0644))
+	fromWordList, err := Load(wordList, model.EnglishAlphabet)
+	require.NoError(t, err)
+	assert.True(t, fromWordList.Contains("cat"))
+
+	var compiled bytes.Buffer
+	require.NoError(t, fromWordList.WriteCompiled(&compiled))
+	compiledPath := filepath.Join(directory, "words.trie")
+	require.NoError(t, ioutil.WriteFile(compiledPath, compiled.Bytes(), 0644))
+	fromCompiled, err := Load(compiledPath, model.EnglishAlphabet)
+	require.NoError(t, err)
+	assert.Equal(t, fromWordList, fromCompiled)
+}
+
+func TestStats(t *testing.T) {
+	stats := newTrie("a", "at", "cat", "cats").Stats()
+	assert.Equal(t, Stats{
+		Words:         4,
+		Nodes:         7,
+		LongestWord:   4,
+		WordsByLength: map[int]int{1: 1, 2: 1, 3: 1, 4: 1},
+	}, stats)
+}
+
+func TestAnagrams(t *testing.T) {
+	trie := newTrie("a", "at", "cat", "tact", "taco")
+	rack, err := model.ParseRack("TCA?", 7, nil)
+	require.NoError(t, err)
+
+	expected := []model.Word{
+		{Chars: "taco", BlankTiles: []bool{false, false, false, true}},
+		{Chars: "tact", BlankTiles: []bool{false, false, false, true}},
+		{Chars: "cat", BlankTiles: []bool{false, false, false}},
+		{Chars: "at", BlankTiles: []bool{false, false}},
+		{Chars: "a", BlankTiles: []bool{false}},
+	}
+	assert.Equal(t, expected, trie.Anagrams(*rack))
+
+	rack, err = model.ParseRack("TC", 7, nil)
+	require.NoError(t, err)
+	assert.Empty(t, trie.Anagrams(*rack))
+}
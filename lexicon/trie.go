@@ -2,8 +2,14 @@ package lexicon
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"unicode/utf8"
+
+	"example.com/unscrabble/unscrabble/model"
 )
 
 // NewTrieNode returns a pointer to a new empty root TrieNode with an initialised map for NextNodes
@@ -25,22 +31,39 @@ type TrieNode struct {
 }
 
 // InsertWordsFromFile inserts words from a file which has a single word on each line. It is
-// intended to be called on the root node.
-func (t *TrieNode) InsertWordsFromFile(filePath string) {
+// intended to be called on the root node. See InsertWordsFromReader.
+func (t *TrieNode) InsertWordsFromFile(filePath string, alphabet *model.Alphabet) error {
 	file, err := os.Open(filePath)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		t.Insert(scanner.Text())
-	}
+	return t.InsertWordsFromReader(file, alphabet)
+}
 
-	if err := scanner.Err(); err != nil {
-		panic(err)
+// InsertWordsFromReader inserts words from a reader which has a single word on each line. It is
+// intended to be called on the root node. If alphabet is not nil, each word is tokenised into
+// the letters of the alphabet before it is inserted, so words containing multi-character tiles
+// are stored with one edge per tile. Blank lines are ignored.
+func (t *TrieNode) InsertWordsFromReader(reader io.Reader, alphabet *model.Alphabet) error {
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		if alphabet != nil {
+			letters, err := alphabet.Tokenise(word)
+			if err != nil {
+				return fmt.Errorf("line %v: %w", lineNumber, err)
+			}
+			word = letters
+		}
+		t.Insert(word)
 	}
+
+	return scanner.Err()
 }
 
 // Insert inserts the provided word into the trie. It is intended to be called on the root node.
@@ -67,15 +90,43 @@ func (t *TrieNode) Insert(word string) {
 
 // Contains is used for identifying whether the provided word is in the trie rooted at t
 func (t *TrieNode) Contains(word string) bool {
+	node := t.Find(word)
+	return node != nil && node.Terminal
+}
+
+// Find returns the node whose label is prefix, or nil if no word in the trie rooted at t starts
+// with prefix.
+func (t *TrieNode) Find(prefix string) *TrieNode {
 	currNode := t
-	for _, char := range word {
+	for _, char := range prefix {
 		nextNode, ok := currNode.NextNodes[char]
 		if !ok {
-			return false
+			return nil
 		}
 		currNode = nextNode
 	}
-	return currNode.Terminal
+	return currNode
+}
+
+// Hooks returns the letters which can be added to the front and to the back of
+// word to make other words in the trie, in order. It is intended to be called on
+// a root node.
+func (t *TrieNode) Hooks(word string) (front, back []rune) {
+	for letter := range t.NextNodes {
+		if t.Contains(string(letter) + word) {
+			front = append(front, letter)
+		}
+	}
+	if node := t.Find(word); node != nil {
+		for letter, nextNode := range node.NextNodes {
+			if nextNode.Terminal {
+				back = append(back, letter)
+			}
+		}
+	}
+	sort.Slice(front, func(i, j int) bool { return front[i] < front[j] })
+	sort.Slice(back, func(i, j int) bool { return back[i] < back[j] })
+	return front, back
 }
 
 // Delete removes the word from the trie. It is intended to be called on a root node.
@@ -104,9 +155,9 @@ func (t *TrieNode) Delete(word string) {
 // ValidLettersBetweenPrefixAndSuffix returns the set of all letters '?'
 // for which there is a word in the trie that looks like: '{prefix}?{suffix}'.
 // It is inteded to be called on the root node.
-func (t *TrieNode) ValidLettersBetweenPrefixAndSuffix(prefix, suffix string) map[rune]bool {
+func (t *TrieNode) ValidLettersBetweenPrefixAndSuffix(prefix, suffix string) model.LetterSet {
 
-	validLetters := make(map[rune]bool)
+	validLetters := model.EmptyLetterSet
 	currNode := t
 	prefixInTrie := true
 
@@ -128,7 +179,7 @@ func (t *TrieNode) ValidLettersBetweenPrefixAndSuffix(prefix, suffix string) map
 			}
 		}
 		if wordInTrie && currNode.Terminal {
-			validLetters[middleLetter] = true
+			validLetters = validLetters.With(middleLetter)
 		}
 	}
 	return validLetters
@@ -145,7 +196,14 @@ func (t *TrieNode) IncomingEdge() rune {
 	if t.IsRoot() {
 		return 0
 	}
-	return []rune(t.Label)[len(t.Label)-1]
+	edge, _ := utf8.DecodeLastRuneInString(t.Label)
+	return edge
+}
+
+// Length returns the number of letters in the node's label, which is also the depth of the node
+// in the trie.
+func (t *TrieNode) Length() int {
+	return utf8.RuneCountInString(t.Label)
 }
 
 // EdgePruner is used for indicating which edges should be followed in a pruned traversal
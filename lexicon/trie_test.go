@@ -1,8 +1,10 @@
 package lexicon
 
 import (
+	"strings"
 	"testing"
 
+	"example.com/unscrabble/unscrabble/model"
 	assert "github.com/stretchr/testify/assert"
 )
 
@@ -263,9 +265,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("", "o")
 		assert.Equal(
 			t,
-			map[rune]bool{
-				'd': true,
-			},
+			model.NewLetterSet('d'),
 			crossSet,
 		)
 	})
@@ -273,9 +273,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("do", "")
 		assert.Equal(
 			t,
-			map[rune]bool{
-				'g': true,
-			},
+			model.NewLetterSet('g'),
 			crossSet,
 		)
 	})
@@ -283,7 +281,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("", "")
 		assert.Equal(
 			t,
-			map[rune]bool{'a': true},
+			model.NewLetterSet('a'),
 			crossSet,
 		)
 	})
@@ -291,10 +289,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("ca", "s")
 		assert.Equal(
 			t,
-			map[rune]bool{
-				'r': true,
-				't': true,
-			},
+			model.NewLetterSet('r', 't'),
 			crossSet,
 		)
 	})
@@ -302,7 +297,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("", "z")
 		assert.Equal(
 			t,
-			map[rune]bool{},
+			model.EmptyLetterSet,
 			crossSet,
 		)
 	})
@@ -310,7 +305,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("z", "")
 		assert.Equal(
 			t,
-			map[rune]bool{},
+			model.EmptyLetterSet,
 			crossSet,
 		)
 	})
@@ -318,7 +313,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("a", "")
 		assert.Equal(
 			t,
-			map[rune]bool{},
+			model.EmptyLetterSet,
 			crossSet,
 		)
 	})
@@ -326,7 +321,7 @@ func TestValidLettersBetweenPrefixAndSuffix(t *testing.T) {
 		crossSet := trie.ValidLettersBetweenPrefixAndSuffix("d", "n")
 		assert.Equal(
 			t,
-			map[rune]bool{},
+			model.EmptyLetterSet,
 			crossSet,
 		)
 	})
@@ -451,3 +446,48 @@ func createTrie() *TrieNode {
 	}
 	return trie
 }
+
+func TestInsertWordsFromReaderTokenisesWords(t *testing.T) {
+	alphabet, err := model.NewAlphabet([]string{"a", "c", "ch", "e", "l", "ll", "o"})
+	assert.NoError(t, err)
+	ch, _ := alphabet.Letter("ch")
+	ll, _ := alphabet.Letter("ll")
+
+	trie := NewTrieNode()
+	err = trie.InsertWordsFromReader(strings.NewReader("CALLE\n\nchoca\n"), alphabet)
+	assert.NoError(t, err)
+
+	calle := string([]rune{'c', 'a', ll, 'e'})
+	choca := string([]rune{ch, 'o', 'c', 'a'})
+	assert.True(t, trie.Contains(calle))
+	assert.True(t, trie.Contains(choca))
+	assert.False(t, trie.Contains("calle"))
+
+	chNode := trie.NextNodes[ch]
+	assert.Equal(t, ch, chNode.IncomingEdge())
+	assert.Equal(t, 1, chNode.Length())
+	assert.Equal(t, 4, trie.NextNodes['c'].NextNodes['a'].NextNodes[ll].NextNodes['e'].Length())
+}
+
+func TestInsertWordsFromReaderReturnsErrorForUnknownLetters(t *testing.T) {
+	alphabet, err := model.NewAlphabet([]string{"a", "b"})
+	assert.NoError(t, err)
+
+	err = NewTrieNode().InsertWordsFromReader(strings.NewReader("ab\nabc\n"), alphabet)
+	assert.EqualError(t, err, `line 2: 'c' in "abc" is not in the alphabet`)
+}
+
+func TestHooks(t *testing.T) {
+	trie := NewTrieNode()
+	for _, word := range []string{"at", "bat", "cat", "ate", "ats", "atsx", "a"} {
+		trie.Insert(word)
+	}
+
+	front, back := trie.Hooks("at")
+	assert.Equal(t, []rune("bc"), front)
+	assert.Equal(t, []rune("es"), back)
+
+	front, back = trie.Hooks("xyz")
+	assert.Empty(t, front)
+	assert.Empty(t, back)
+}
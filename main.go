@@ -1,52 +1,238 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"strings"
 
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/data"
 	"example.com/unscrabble/unscrabble/model"
-	"gopkg.in/yaml.v2"
 )
 
-func check(err error) {
-	if err != nil {
-		panic(err)
+// The exit codes of the commands
+const (
+	exitOK      = 0
+	exitFailure = 1 // the command failed, e.g. a file could not be read
+	exitUsage   = 2 // the command line is invalid
+	exitInvalid = 3 // the input is invalid, e.g. a configuration or a recorded game
+)
+
+// command is a subcommand of the CLI. run is passed the arguments after the
+// name of the command and returns the exit code.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"play", "play a game between bots", runPlay},
+		{"interactive", "play a game against a bot at the terminal", runInteractive},
+		{"analyze", "rank the moves for a rack on a board", runAnalyze},
+		{"anagram", "list the words that can be made from a rack", runAnagram},
+		{"lexicon", "compile a word list or print the statistics of a lexicon", runLexicon},
+		{"validate-config", "check a configuration and print it", runValidateConfig},
+		{"replay", "replay a GCG file and check its scores", runReplay},
+		{"serve", "serve move analysis and host games over HTTP", runServe},
+		{"strategies", "list the strategies bots can play and their parameters", runStrategies},
+		{"tournament", "play strategies against each other and compare them", runTournament},
+		{"ratings", "print the leaderboard of the strategies rated in tournaments", runRatings},
 	}
 }
 
 func main() {
-	// Load in confiugration
-	// Create a game with that confifguration
-	// Play that game
-	// return the winner
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		os.Exit(exitOK)
+	}
+	for _, command := range commands() {
+		if command.name == name {
+			os.Exit(command.run(os.Args[2:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+	usage()
+	os.Exit(exitUsage)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %v <command> [arguments]\n\nThe commands are:\n", os.Args[0])
+	for _, command := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-16v %v\n", command.name, command.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"%v <command> -h\" for the flags of a command.\n", os.Args[0])
+}
+
+// newFlagSet returns the flags of a command, with a usage message that shows
+// the command's arguments
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %v %v %v\n", os.Args[0], name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
+// parseFlags parses the arguments of a command and checks it has nArgs
+// positional arguments. It returns false if the command line is invalid.
+func parseFlags(flags *flag.FlagSet, args []string, nArgs int) bool {
+	if err := flags.Parse(args); err != nil {
+		return false
+	}
+	if flags.NArg() != nArgs {
+		flags.Usage()
+		return false
+	}
+	return true
+}
+
+// exitError is an error with the exit code of the command that failed with it
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// invalid marks err as being caused by invalid input
+func invalid(err error) error {
+	return &exitError{code: exitInvalid, err: err}
+}
+
+// exit prints err, if there is one, and returns the exit code for it
+func exit(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(os.Stderr, err)
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// ruleFlags are the flags for choosing the rules of a game
+type ruleFlags struct {
+	preset     *string
+	configPath *string
+}
+
+func addRuleFlags(flags *flag.FlagSet) ruleFlags {
+	return ruleFlags{
+		preset: flags.String(
+			"preset",
+			"scrabble",
+			"name of a bundled rule preset: "+strings.Join(data.PresetNames(), ", "),
+		),
+		configPath: flags.String("config", "", "path of a configuration file to use instead of a preset"),
+	}
+}
+
+// name returns the name of the chosen rules, which is the path of the
+// configuration file if there is one
+func (r ruleFlags) name() string {
+	if *r.configPath != "" {
+		return *r.configPath
+	}
+	return *r.preset
+}
+
+// load returns the chosen configuration, which must be valid, and its alphabet
+func (r ruleFlags) load() (model.Configuration, *model.Alphabet, error) {
+	name := r.name()
 	var config model.Configuration
-	dataPath := os.Args[1]
-	configBytes, err := ioutil.ReadFile(dataPath)
-	check(err)
+	var err error
+	if *r.configPath != "" {
+		config, err = model.LoadConfiguration(*r.configPath)
+	} else {
+		config, err = data.LoadPreset(*r.preset)
+	}
+	if err != nil {
+		return model.Configuration{}, nil, err
+	}
+	if err := validateConfiguration(name, config); err != nil {
+		return model.Configuration{}, nil, err
+	}
+	alphabet, err := config.NewAlphabet()
+	return config, alphabet, err
+}
+
+// validateConfiguration returns an error listing the problems with config
+func validateConfiguration(name string, config model.Configuration) error {
+	errs := config.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid configuration %v:", name)
+	for _, err := range errs {
+		fmt.Fprintf(&sb, "\n  %v", err)
+	}
+	return invalid(errors.New(sb.String()))
+}
+
+// addLexiconFlag adds the flag for the path of the lexicon
+func addLexiconFlag(flags *flag.FlagSet) *string {
+	return flags.String("lexicon", "", "path of a word list, with one word per line, or a compiled lexicon")
+}
 
-	err = yaml.Unmarshal(configBytes, &config)
-	check(err)
+// loadLexicon loads the lexicon at path, which is required
+func loadLexicon(path string, alphabet *model.Alphabet) (*lexicon.TrieNode, error) {
+	if path == "" {
+		return nil, &exitError{code: exitUsage, err: errors.New("a -lexicon is required")}
+	}
+	return lexicon.Load(path, alphabet)
+}
 
-	fmt.Println(config)
+// formatFlag is the flag for choosing the output format of a command
+type formatFlag struct {
+	format  *string
+	formats []string
+}
 
-	_, err = model.NewGame(
-		2,
-		config.BingoPremium,
-		config.RackSize,
-		convertStringsToRunes(config.LetterScores),
-		convertStringsToRunes(config.LetterCounts),
-		config.LetterMultipliers,
-		config.WordMultipliers,
-		nil,
-	)
+// addFormatFlag adds the flag for the output format. The first format is the
+// default.
+func addFormatFlag(flags *flag.FlagSet, formats ...string) formatFlag {
+	return formatFlag{
+		format:  flags.String("format", formats[0], "output format: "+strings.Join(formats, ", ")),
+		formats: formats,
+	}
 }
 
-func convertStringsToRunes(in map[string]int) map[rune]int {
-	out := map[rune]int{}
-	for key, value := range in {
-		out[[]rune(key)[0]] = value
+// check returns an error if the chosen format is not one of the formats
+func (f formatFlag) check() error {
+	for _, format := range f.formats {
+		if *f.format == format {
+			return nil
+		}
 	}
-	return out
+	return &exitError{
+		code: exitUsage,
+		err:  fmt.Errorf("unknown format %q, the formats are: %v", *f.format, strings.Join(f.formats, ", ")),
+	}
+}
+
+// writeJSON writes value to stdout as indented JSON
+func writeJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/gcg"
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// runPlay plays a game between bots and prints it. It returns the exit code.
+func runPlay(args []string) int {
+	flags := newFlagSet("play", "[-preset name | -config config.yaml] -lexicon words.txt [-strategies a,b] [-seed n] [-turn-time d] [-events]")
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	playerStrategies := flags.String(
+		"strategies",
+		"highscore,highscore",
+		"comma separated strategies of the players"+specsUsage,
+	)
+	seed := flags.Int64("seed", 0, "seed for drawing tiles and random strategies, 0 for a seed from the time")
+	turnTime := flags.Duration("turn-time", 0, "time each strategy has to pick its move, 0 for no limit")
+	events := flags.Bool("events", false, "log the events of the game to stderr as it is played")
+	format := addFormatFlag(flags, "text", "json", "gcg")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	return exit(play(rules, *lexiconPath, splitSpecs(*playerStrategies), *seed, *turnTime, *events, format))
+}
+
+func play(
+	rules ruleFlags,
+	lexiconPath string,
+	playerStrategies []string,
+	seed int64,
+	turnTime time.Duration,
+	events bool,
+	format formatFlag,
+) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+	moveGenerator, err := newMoveGenerator(config, alphabet, lex)
+	if err != nil {
+		return err
+	}
+	playerPickers, err := newPickers(playerStrategies, alphabet)
+	if err != nil {
+		return err
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	players := make([]*model.Player, len(playerStrategies))
+	gcgPlayers := make([]gcg.Player, len(playerStrategies))
+	for i, name := range playerStrategies {
+		playerName := fmt.Sprintf("Player %v (%v)", i+1, name)
+		players[i] = model.NewPlayer(playerName, playerPickers[i](moveGenerator, random))
+		gcgPlayers[i] = gcg.Player{Nickname: fmt.Sprintf("p%v", i+1), Name: playerName}
+	}
+
+	game, err := model.NewSeededGame(config, lex, seed, players...)
+	if err != nil {
+		return err
+	}
+	if events {
+		game.AddObserver(eventLogger(alphabet, os.Stderr))
+	}
+	winners, err := game.PlayContext(context.Background(), turnTime)
+	if err != nil {
+		return err
+	}
+
+	switch *format.format {
+	case "json":
+		fmt.Fprintf(os.Stderr, "seed %v\n", seed)
+		return writeJSON(game.JSON(model.AllPlayers))
+	case "gcg":
+		fmt.Fprintf(os.Stderr, "seed %v\n", seed)
+		return gcg.FromGame(game, alphabet, gcgPlayers...).Write(os.Stdout)
+	}
+	fmt.Printf("seed %v\n", seed)
+	printRecord(game, alphabet)
+	for _, player := range players {
+		fmt.Printf("%v %v\n", player.Name(), player.Score())
+	}
+	winnerNames := make([]string, len(winners))
+	for i, winner := range winners {
+		winnerNames[i] = winner.Name()
+	}
+	if len(winners) == 1 {
+		fmt.Printf("winner: %v\n", winnerNames[0])
+	} else {
+		fmt.Printf("draw between %v\n", strings.Join(winnerNames, " and "))
+	}
+	return nil
+}
+
+// eventLogger returns an observer that writes a line to w for every event of
+// a game
+func eventLogger(alphabet *model.Alphabet, w io.Writer) model.Observer {
+	return model.ObserverFunc(func(game *model.Game, event model.GameEvent) {
+		line := event.Type.String()
+		if event.Player >= 0 {
+			line = game.Players()[event.Player].Name() + ": " + line
+		}
+		if event.Move != nil {
+			line += " " + event.Move.Coordinates() + " " + alphabet.FormatWord(event.Move.Word)
+		}
+		if len(event.Tiles) > 0 {
+			tiles := model.NewRack(len(event.Tiles))
+			for _, letter := range event.Tiles {
+				tiles.AddLetter(letter)
+			}
+			line += " " + alphabet.FormatRack(tiles)
+		}
+		switch event.Type {
+		case model.MoveScored:
+			line += fmt.Sprintf(" +%v", event.Score)
+		case model.PlayChallenged:
+			if event.Successful {
+				line += " (withdrawn)"
+			} else {
+				line += " (valid)"
+			}
+		case model.GameEnded:
+			for _, player := range game.Players() {
+				line += fmt.Sprintf(", %v %v", player.Name(), player.Score())
+			}
+		}
+		fmt.Fprintln(w, line)
+	})
+}
+
+// newMoveGenerator returns a generator of the moves for a game, scored and
+// ranked from the highest score
+func newMoveGenerator(
+	config model.Configuration,
+	alphabet *model.Alphabet,
+	lex *lexicon.TrieNode,
+) (strategy.MoveGenerator, error) {
+	letterScores, err := alphabet.LetterMap(config.LetterScores)
+	if err != nil {
+		return nil, err
+	}
+	if len(lex.NextNodes) == 0 {
+		return nil, invalid(errors.New("the lexicon does not have any words"))
+	}
+	trieMoveGenerator := triemovegen.NewTrieMoveGenertator(lex)
+	return strategy.NewScoringMoveGenerator(
+		&trieMoveGenerator,
+		letterScores,
+		config.RackSize,
+		config.BingoPremium,
+	), nil
+}
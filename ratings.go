@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/unscrabble/unscrabble/rating"
+)
+
+// runRatings prints the leaderboard of the strategies rated in a history of
+// tournaments. It returns the exit code.
+func runRatings(args []string) int {
+	flags := newFlagSet("ratings", "[-format text|json] ratings.jsonl")
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 1) {
+		return exitUsage
+	}
+	return exit(ratings(flags.Arg(0), format))
+}
+
+func ratings(path string, format formatFlag) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	history, err := rating.LoadHistory(path)
+	if err != nil {
+		return err
+	}
+	leaderboard := history.Leaderboard()
+	if *format.format == "json" {
+		return writeJSON(leaderboard)
+	}
+	printLeaderboard(leaderboard)
+	return nil
+}
+
+// printLeaderboard prints the standings of strategies, with how much their
+// ratings changed in the last tournament they played in
+func printLeaderboard(leaderboard []rating.Standing) {
+	if len(leaderboard) == 0 {
+		fmt.Println("no ratings")
+		return
+	}
+	width := len("strategy")
+	for _, standing := range leaderboard {
+		if len(standing.Name) > width {
+			width = len(standing.Name)
+		}
+	}
+	fmt.Printf("%4v %-*v %7v %7v %5v %7v %7v %v\n",
+		"rank", width, "strategy", "glicko", "change", "rd", "elo", "change", "games")
+	for i, standing := range leaderboard {
+		fmt.Printf("%4d %-*v %7.0f %+7.1f %5.0f %7.0f %+7.1f %v\n",
+			i+1, width, standing.Name,
+			standing.Glicko.Rating, standing.GlickoChange, standing.Glicko.Deviation,
+			standing.Elo, standing.EloChange, standing.Games)
+	}
+}
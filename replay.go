@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/unscrabble/unscrabble/gcg"
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/terminal"
+)
+
+// runReplay replays a GCG file with the rules of a preset or configuration
+// file, and checks the scores recorded in it. It returns the exit code.
+func runReplay(args []string) int {
+	flags := newFlagSet("replay", "[-preset name | -config config.yaml] game.gcg")
+	rules := addRuleFlags(flags)
+	verbose := flags.Bool("v", false, "print every turn of the game")
+	if !parseFlags(flags, args, 1) {
+		return exitUsage
+	}
+	return exit(replay(rules, flags.Arg(0), *verbose))
+}
+
+func replay(rules ruleFlags, path string, verbose bool) error {
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	record, err := gcg.Parse(file)
+	if err != nil {
+		return invalid(fmt.Errorf("%v: %w", path, err))
+	}
+	game, err := gcg.Replay(record, config)
+	if err != nil {
+		return invalid(fmt.Errorf("%v: %w", path, err))
+	}
+
+	if verbose {
+		printRecord(game, alphabet)
+	}
+	fmt.Printf("verified %v events\n", len(record.Events))
+	for _, player := range game.Players() {
+		fmt.Printf("%v %v\n", player.Name(), player.Score())
+	}
+	return nil
+}
+
+// printRecord prints every turn of the game
+func printRecord(game *model.Game, alphabet *model.Alphabet) {
+	for i, turn := range game.Record() {
+		fmt.Printf(
+			"%v: %v %+d %d\n",
+			game.Players()[turn.Player].Name(),
+			terminal.DescribeTurn(game, alphabet, i),
+			turn.Score,
+			turn.Total,
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"example.com/unscrabble/unscrabble/api"
+	"example.com/unscrabble/unscrabble/gameserver"
+	"example.com/unscrabble/unscrabble/storage"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// runServe serves the analysis API and hosts games over HTTP until it is
+// interrupted. With -data the games are stored in a directory, and the games
+// stored there are hosted again when the server restarts. It returns the exit
+// code.
+func runServe(args []string) int {
+	flags := newFlagSet(
+		"serve",
+		"[-preset name | -config config.yaml] -lexicon words.txt [-addr host:port] [-data dir] [-bots spec,spec,...] [-turn-time d]",
+	)
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	dataDir := flags.String("data", "", "directory to store hosted games in, so they survive a restart")
+	bots := flags.String("bots", strings.Join(defaultBots(), ","), "comma separated strategies that bots can play"+specsUsage)
+	turnTime := flags.Duration("turn-time", 10*time.Second, "time each bot has to pick its move, 0 for no limit")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	return exit(serve(rules, *lexiconPath, *addr, *dataDir, splitSpecs(*bots), *turnTime))
+}
+
+func serve(rules ruleFlags, lexiconPath, addr, dataDir string, bots []string, turnTime time.Duration) error {
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+	analysis, err := api.NewServer(config, lex)
+	if err != nil {
+		return err
+	}
+	// the bots are made once here so that a bad spec is found at once
+	if _, err := newPickers(bots, alphabet); err != nil {
+		return err
+	}
+	games := gameserver.NewServer(strategy.DefaultRegistry)
+	defer games.Close()
+	games.SetTurnTime(turnTime)
+	if err := games.AddRules(rules.name(), config, lex); err != nil {
+		return err
+	}
+	for _, spec := range bots {
+		if err := games.AddBot(spec); err != nil {
+			return err
+		}
+	}
+	if dataDir != "" {
+		store, err := storage.NewFileStore(dataDir)
+		if err != nil {
+			return err
+		}
+		if err := games.UseStore(store); err != nil {
+			return err
+		}
+	}
+	handler := http.NewServeMux()
+	handler.Handle("/games", games)
+	handler.Handle("/games/", games)
+	handler.Handle("/strategies", games)
+	handler.Handle("/", analysis)
+
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: time.Minute,
+	}
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		<-interrupted
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "listening on %v\n", addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// runStrategies lists the strategies that bots can play and their parameters.
+// It returns the exit code.
+func runStrategies(args []string) int {
+	flags := newFlagSet("strategies", "[-format text|json]")
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	if err := format.check(); err != nil {
+		return exit(err)
+	}
+	if *format.format == "json" {
+		return exit(writeJSON(strategy.DefaultRegistry.Definitions()))
+	}
+	fmt.Print(strategy.DefaultRegistry.Usage())
+	return exitOK
+}
+
+// specsUsage is the usage of the flags that take strategy specs
+const specsUsage = `, e.g. "random" or "equity:leaves=leaves.csv" (see the strategies command)`
+
+// splitSpecs splits a comma separated list of strategy specs. As the
+// parameters of a spec are also separated by commas, a part with a parameter
+// but no strategy name belongs to the spec before it, so
+// "sim:plies=2,iters=500,random" is the specs "sim:plies=2,iters=500" and
+// "random".
+func splitSpecs(list string) []string {
+	var specs []string
+	for _, part := range strings.Split(list, ",") {
+		if len(specs) > 0 && strings.Contains(part, "=") && !strings.Contains(part, ":") {
+			specs[len(specs)-1] += "," + part
+		} else {
+			specs = append(specs, part)
+		}
+	}
+	return specs
+}
+
+// newPickers returns the makers of the pickers of strategy specs. A spec that
+// is given more than once is only made once.
+func newPickers(specs []string, alphabet *model.Alphabet) ([]strategy.NewPicker, error) {
+	newPickers := make([]strategy.NewPicker, len(specs))
+	made := make(map[string]strategy.NewPicker)
+	for i, spec := range specs {
+		if newPicker, ok := made[spec]; ok {
+			newPickers[i] = newPicker
+			continue
+		}
+		if _, _, err := strategy.DefaultRegistry.Parse(spec); err != nil {
+			return nil, &exitError{code: exitUsage, err: err}
+		}
+		newPicker, err := strategy.DefaultRegistry.New(spec, alphabet)
+		if err != nil {
+			return nil, err
+		}
+		newPickers[i], made[spec] = newPicker, newPicker
+	}
+	return newPickers, nil
+}
+
+// defaultBots returns the specs of the strategies without required
+// parameters, which are the bots a server offers by default
+func defaultBots() []string {
+	var specs []string
+definitions:
+	for _, definition := range strategy.DefaultRegistry.Definitions() {
+		for _, param := range definition.Params {
+			if param.Required {
+				continue definitions
+			}
+		}
+		specs = append(specs, definition.Name)
+	}
+	return specs
+}
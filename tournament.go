@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/unscrabble/unscrabble/rating"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/tournament"
+)
+
+// runTournament plays a round robin between strategies and prints how they
+// did. With -ratings the strategies are rated from the games, and the ratings
+// are added to the history in the file. It returns the exit code.
+func runTournament(args []string) int {
+	flags := newFlagSet(
+		"tournament",
+		"[-preset name | -config config.yaml] -lexicon words.txt [-strategies spec,spec,...] [-games n] [-seed n] [-workers n] [-turn-time d] [-ratings ratings.jsonl]",
+	)
+	rules := addRuleFlags(flags)
+	lexiconPath := addLexiconFlag(flags)
+	entrantStrategies := flags.String(
+		"strategies",
+		strings.Join(defaultBots(), ","),
+		"comma separated strategies to play against each other"+specsUsage,
+	)
+	games := flags.Int("games", 100, "number of games between each pair of strategies")
+	seed := flags.Int64("seed", 0, "seed for drawing tiles and random strategies, 0 for a seed from the time")
+	workers := flags.Int("workers", 0, "number of games to play at once, 0 for the number of CPUs")
+	turnTime := flags.Duration("turn-time", 0, "time each strategy has to pick its move, 0 for no limit")
+	ratingsPath := flags.String("ratings", "", "file of the history of ratings to rate the strategies in")
+	format := addFormatFlag(flags, "text", "csv", "json")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	return exit(playTournament(
+		rules,
+		*lexiconPath,
+		splitSpecs(*entrantStrategies),
+		*games,
+		*seed,
+		*workers,
+		*turnTime,
+		*ratingsPath,
+		format,
+	))
+}
+
+func playTournament(
+	rules ruleFlags,
+	lexiconPath string,
+	entrantStrategies []string,
+	games int,
+	seed int64,
+	workers int,
+	turnTime time.Duration,
+	ratingsPath string,
+	format formatFlag,
+) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	if len(entrantStrategies) < 2 {
+		return &exitError{code: exitUsage, err: errors.New("a tournament needs at least two strategies")}
+	}
+	if games < 1 {
+		return &exitError{code: exitUsage, err: errors.New("a tournament needs at least one game per match")}
+	}
+	var history *rating.History
+	if ratingsPath != "" {
+		var err error
+		if history, err = rating.LoadHistory(ratingsPath); err != nil {
+			return err
+		}
+	}
+
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	lex, err := loadLexicon(lexiconPath, alphabet)
+	if err != nil {
+		return err
+	}
+	// check the move generator can be made before the games make their own
+	if _, err := newMoveGenerator(config, alphabet, lex); err != nil {
+		return err
+	}
+	entrantPickers, err := newPickers(entrantStrategies, alphabet)
+	if err != nil {
+		return err
+	}
+	entrants := make([]tournament.Entrant, len(entrantStrategies))
+	counts := make(map[string]int)
+	for i, spec := range entrantStrategies {
+		// a strategy can play against itself, under another name
+		counts[spec]++
+		name := spec
+		if counts[spec] > 1 {
+			name = fmt.Sprintf("%v (%v)", spec, counts[spec])
+		}
+		entrants[i] = tournament.Entrant{Name: name, NewPicker: entrantPickers[i]}
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t := tournament.Tournament{
+		Config:  config,
+		Lexicon: lex,
+		NewMoveGenerator: func() strategy.MoveGenerator {
+			moveGenerator, _ := newMoveGenerator(config, alphabet, lex)
+			return moveGenerator
+		},
+		Entrants: entrants,
+		Games:    games,
+		Seed:     seed,
+		Workers:  workers,
+		TurnTime: turnTime,
+		Progress: func(played, total int) {
+			fmt.Fprintf(os.Stderr, "\rplayed %v/%v games", played, total)
+			if played == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		},
+	}
+	report, err := t.Run()
+	if err != nil {
+		return err
+	}
+	if history != nil {
+		if err := rating.AppendPeriod(ratingsPath, history.Rate(report, time.Now())); err != nil {
+			return err
+		}
+	}
+
+	switch *format.format {
+	case "csv":
+		fmt.Fprintf(os.Stderr, "seed %v\n", seed)
+		return tournament.WriteCSV(os.Stdout, append(report.Entrants, report.Matchups...))
+	case "json":
+		fmt.Fprintf(os.Stderr, "seed %v\n", seed)
+		return writeJSON(report)
+	}
+	fmt.Printf("seed %v\n", seed)
+	printStats(report.Entrants, false)
+	fmt.Println()
+	printStats(report.Matchups, true)
+	if history != nil {
+		fmt.Println()
+		printLeaderboard(history.Leaderboard())
+	}
+	return nil
+}
+
+// printStats prints a table of tournament stats, with the opponents if
+// matchups is true
+func printStats(stats []tournament.Stats, matchups bool) {
+	name := func(s tournament.Stats) string {
+		if matchups {
+			return s.Entrant + " v " + s.Opponent
+		}
+		return s.Entrant
+	}
+	width := len("strategy")
+	for _, s := range stats {
+		if len(name(s)) > width {
+			width = len(name(s))
+		}
+	}
+	fmt.Printf("%-*v %6v %6v %6v %-15v %8v %7v %6v %6v\n",
+		width, "strategy", "games", "wins", "draws", "win rate (95%)", "spread", "score", "bingos", "turns")
+	for _, s := range stats {
+		fmt.Printf("%-*v %6d %6d %6d %5.1f%% %-8v %+8.1f %7.1f %6.2f %6.1f\n",
+			width, name(s), s.Games, s.Wins, s.Draws,
+			100*s.WinRate, fmt.Sprintf("%.0f-%.0f%%", 100*s.WinRateLow, 100*s.WinRateHigh),
+			s.MeanSpread, s.MeanScore, s.BingoRate, s.MeanLength)
+	}
+}
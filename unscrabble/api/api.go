@@ -0,0 +1,448 @@
+// Package api serves move analysis over HTTP. Requests and responses are JSON,
+// with boards, racks and moves in the notation and encodings of the model
+// package.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// maxRequestBytes limits the size of request bodies
+const maxRequestBytes = 1 << 20
+
+// MovesRequest asks for the highest scoring moves for a rack on a board. The
+// board is either a JSON string in the text format of FormatBoard or a
+// BoardJSON object, and is the empty board of the rules if it is missing. The
+// first move rules are always those of the server's configuration.
+type MovesRequest struct {
+	Board json.RawMessage `json:"board,omitempty"`
+	Rack  string          `json:"rack"`
+	// Limit is the number of moves to return, or every move if it is 0
+	Limit int `json:"limit,omitempty"`
+}
+
+// MovesResponse lists moves from the highest scoring
+type MovesResponse struct {
+	Moves []RankedMove `json:"moves"`
+}
+
+// RankedMove is a move in standard notation and in its JSON encoding
+type RankedMove struct {
+	Notation string         `json:"notation"`
+	Move     model.MoveJSON `json:"move"`
+}
+
+// MoveRequest asks about a move in standard notation on a board, which is
+// given as in a MovesRequest. The rack is optional, and if it is given the
+// move must be played from it.
+type MoveRequest struct {
+	Board json.RawMessage `json:"board,omitempty"`
+	Rack  string          `json:"rack,omitempty"`
+	Move  string          `json:"move"`
+}
+
+// ValidateResponse tells whether a move can be played. Phonies lists the words
+// formed by the move which are not in the lexicon. The score is only given for
+// a valid move.
+type ValidateResponse struct {
+	Valid   bool     `json:"valid"`
+	Error   string   `json:"error,omitempty"`
+	Phonies []string `json:"phonies,omitempty"`
+	Score   int      `json:"score,omitempty"`
+}
+
+// ScoreResponse is the score of a move and the words it forms, whether or not
+// they are in the lexicon
+type ScoreResponse struct {
+	Score int      `json:"score"`
+	Words []string `json:"words"`
+}
+
+// WordResponse tells whether a word is in the lexicon
+type WordResponse struct {
+	Word  string `json:"word"`
+	Valid bool   `json:"valid"`
+}
+
+// AnagramsResponse lists the words that can be made from a rack, from the
+// longest
+type AnagramsResponse struct {
+	Rack  string   `json:"rack"`
+	Words []string `json:"words"`
+}
+
+// HooksResponse lists the letters which can be added to the front and back of
+// a word to make other words
+type HooksResponse struct {
+	Word  string   `json:"word"`
+	Front []string `json:"front"`
+	Back  []string `json:"back"`
+}
+
+// ErrorResponse is the body of every response with an error status
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server is an http.Handler answering analysis requests for the rules of a
+// configuration and a lexicon. The lexicon is shared by the requests and must
+// not be changed while the server is in use; everything else a request needs
+// is created for it, so requests can be served concurrently.
+//
+// The endpoints are:
+//
+//	POST /moves           MovesRequest -> MovesResponse
+//	POST /validate        MoveRequest  -> ValidateResponse
+//	POST /score           MoveRequest  -> ScoreResponse
+//	GET  /words/WORD      WordResponse
+//	GET  /anagrams?rack=  AnagramsResponse, with an optional min word length
+//	GET  /hooks/WORD      HooksResponse
+//
+// Racks, including the rack to find anagrams of, hold at most the rack size of
+// the configuration.
+type Server struct {
+	config       model.Configuration
+	alphabet     *model.Alphabet
+	lexicon      *lexicon.TrieNode
+	letterScores map[rune]int
+	letterCounts map[rune]int
+	mux          *http.ServeMux
+}
+
+// NewServer returns a server for the rules of config, which must be valid,
+// and a lexicon
+func NewServer(config model.Configuration, lex *lexicon.TrieNode) (*Server, error) {
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return nil, err
+	}
+	letterScores, err := alphabet.LetterMap(config.LetterScores)
+	if err != nil {
+		return nil, err
+	}
+	letterCounts, err := alphabet.LetterMap(config.LetterCounts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		config:       config,
+		alphabet:     alphabet,
+		lexicon:      lex,
+		letterScores: letterScores,
+		letterCounts: letterCounts,
+		mux:          http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/moves", s.post(s.moves))
+	s.mux.HandleFunc("/validate", s.post(s.validate))
+	s.mux.HandleFunc("/score", s.post(s.score))
+	s.mux.HandleFunc("/words/", s.get(s.word))
+	s.mux.HandleFunc("/anagrams", s.get(s.anagrams))
+	s.mux.HandleFunc("/hooks/", s.get(s.hooks))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("there is no endpoint %v", r.URL.Path))
+	})
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// requestError is an error caused by a bad request
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// badRequest marks err as being caused by the request
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{err: fmt.Errorf(format, args...)}
+}
+
+// handler answers a request with a value to be written as JSON
+type handler func(r *http.Request) (interface{}, error)
+
+func (s *Server) get(handle handler) http.HandlerFunc {
+	return s.method(http.MethodGet, handle)
+}
+
+func (s *Server) post(handle handler) http.HandlerFunc {
+	return s.method(http.MethodPost, handle)
+}
+
+// method returns a handler for requests with the given method, which writes
+// the response of handle
+func (s *Server) method(method string, handle handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%v must be requested with %v", r.URL.Path, method))
+			return
+		}
+		response, err := handle(r)
+		var requestErr *requestError
+		switch {
+		case errors.As(err, &requestErr):
+			writeError(w, http.StatusBadRequest, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			writeJSON(w, http.StatusOK, response)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// decodeRequest decodes the JSON body of a request into request
+func decodeRequest(r *http.Request, request interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		return badRequest("invalid request: %v", err)
+	}
+	return nil
+}
+
+func (s *Server) moves(r *http.Request) (interface{}, error) {
+	var request MovesRequest
+	if err := decodeRequest(r, &request); err != nil {
+		return nil, err
+	}
+	if request.Limit < 0 {
+		return nil, badRequest("limit must not be negative")
+	}
+	board, err := s.decodeBoard(request.Board)
+	if err != nil {
+		return nil, err
+	}
+	rack, err := s.parseRack(request.Rack)
+	if err != nil {
+		return nil, err
+	}
+
+	trieMoveGenerator := triemovegen.NewTrieMoveGenertator(s.lexicon)
+	moveGenerator := strategy.NewScoringMoveGenerator(
+		&trieMoveGenerator,
+		s.letterScores,
+		s.config.RackSize,
+		s.config.BingoPremium,
+	)
+	moves := moveGenerator.GenerateMoves(board, *rack)
+	if request.Limit > 0 && len(moves) > request.Limit {
+		moves = moves[:request.Limit]
+	}
+	response := MovesResponse{Moves: make([]RankedMove, len(moves))}
+	for i, move := range moves {
+		response.Moves[i] = RankedMove{
+			Notation: s.alphabet.FormatMove(board, move),
+			Move:     s.alphabet.EncodeMove(move),
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) validate(r *http.Request) (interface{}, error) {
+	board, rack, move, err := s.decodeMoveRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	err = board.ValidateMove(move, rack, s.lexicon)
+	var phonyErr *model.PhonyError
+	if errors.As(err, &phonyErr) {
+		return ValidateResponse{Error: err.Error(), Phonies: s.formatWords(phonyErr.Words)}, nil
+	}
+	if err != nil {
+		return ValidateResponse{Error: err.Error()}, nil
+	}
+	score, err := move.CalculateScore(board, s.letterScores, s.config.RackSize, s.config.BingoPremium)
+	if err != nil {
+		return ValidateResponse{Error: err.Error()}, nil
+	}
+	return ValidateResponse{Valid: true, Score: score}, nil
+}
+
+func (s *Server) score(r *http.Request) (interface{}, error) {
+	board, rack, move, err := s.decodeMoveRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := board.ValidateMove(move, rack, nil); err != nil {
+		return nil, badRequest("move %v: %v", s.alphabet.FormatMove(board, move), err)
+	}
+	score, err := move.CalculateScore(board, s.letterScores, s.config.RackSize, s.config.BingoPremium)
+	if err != nil {
+		return nil, badRequest("move %v: %v", s.alphabet.FormatMove(board, move), err)
+	}
+	return ScoreResponse{Score: score, Words: s.formatWords(board.WordsFormed(move))}, nil
+}
+
+// decodeMoveRequest decodes a MoveRequest. The rack is nil if the request does
+// not have one.
+func (s *Server) decodeMoveRequest(r *http.Request) (model.Board, *model.Rack, model.Move, error) {
+	var request MoveRequest
+	if err := decodeRequest(r, &request); err != nil {
+		return model.Board{}, nil, model.Move{}, err
+	}
+	board, err := s.decodeBoard(request.Board)
+	if err != nil {
+		return model.Board{}, nil, model.Move{}, err
+	}
+	var rack *model.Rack
+	if request.Rack != "" {
+		if rack, err = s.parseRack(request.Rack); err != nil {
+			return model.Board{}, nil, model.Move{}, err
+		}
+	}
+	move, err := s.alphabet.ParseMove(board, request.Move)
+	if err != nil {
+		return model.Board{}, nil, model.Move{}, badRequest("move %q: %v", request.Move, err)
+	}
+	return board, rack, move, nil
+}
+
+func (s *Server) word(r *http.Request) (interface{}, error) {
+	word, err := s.parseWord(strings.TrimPrefix(r.URL.Path, "/words/"))
+	if err != nil {
+		return nil, err
+	}
+	return WordResponse{Word: s.formatWord(word), Valid: s.lexicon.Contains(word)}, nil
+}
+
+func (s *Server) anagrams(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	// the rack is not checked against the letter distribution, as anagrams
+	// can be found of any letters, but it must fit on a rack
+	rack, err := s.alphabet.ParseRack(query.Get("rack"), s.config.RackSize, nil)
+	if err != nil {
+		return nil, badRequest("rack %q: %v", query.Get("rack"), err)
+	}
+	minLength := 2
+	if min := query.Get("min"); min != "" {
+		if minLength, err = strconv.Atoi(min); err != nil {
+			return nil, badRequest("min %q is not a number", min)
+		}
+	}
+	response := AnagramsResponse{Rack: s.alphabet.FormatRack(rack), Words: []string{}}
+	for _, word := range s.lexicon.Anagrams(*rack) {
+		if word.Length() >= minLength {
+			response.Words = append(response.Words, s.alphabet.FormatWord(word))
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) hooks(r *http.Request) (interface{}, error) {
+	word, err := s.parseWord(strings.TrimPrefix(r.URL.Path, "/hooks/"))
+	if err != nil {
+		return nil, err
+	}
+	front, back := s.lexicon.Hooks(word)
+	return HooksResponse{
+		Word:  s.formatWord(word),
+		Front: s.formatLetters(front),
+		Back:  s.formatLetters(back),
+	}, nil
+}
+
+// decodeBoard decodes the board of a request, as described by MovesRequest
+func (s *Server) decodeBoard(raw json.RawMessage) (model.Board, error) {
+	var encoded model.BoardJSON
+	var text string
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return s.config.NewBoard(s.lexicon)
+	case json.Unmarshal(raw, &text) == nil:
+		board, err := s.alphabet.ParseBoard(text, s.lexicon, s.letterScores)
+		if err != nil {
+			return model.Board{}, badRequest("board: %v", err)
+		}
+		encoded = s.alphabet.EncodeBoard(board)
+	default:
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return model.Board{}, badRequest("board: %v", err)
+		}
+	}
+
+	encoded.StartSquares = s.config.StartSquares
+	encoded.FirstMoveAnywhere = s.config.FirstMoveAnywhere
+	encoded.MinFirstWordLength = s.config.MinFirstWordLength
+	board, err := s.alphabet.DecodeBoard(encoded, s.lexicon, s.letterScores)
+	if err != nil {
+		return model.Board{}, badRequest("board: %v", err)
+	}
+	if rows, columns := s.config.Dimensions(); board.Rows() != rows || board.Columns() != columns {
+		return model.Board{}, badRequest(
+			"board is %vx%v but the rules' board is %vx%v",
+			board.Rows(), board.Columns(), rows, columns,
+		)
+	}
+	return board, nil
+}
+
+// parseRack parses a rack, which must be possible with the rules' tiles
+func (s *Server) parseRack(notation string) (*model.Rack, error) {
+	rack, err := s.alphabet.ParseRack(notation, s.config.RackSize, s.letterCounts)
+	if err != nil {
+		return nil, badRequest("rack %q: %v", notation, err)
+	}
+	return rack, nil
+}
+
+// parseWord tokenises a word in a path, ignoring case
+func (s *Server) parseWord(text string) (string, error) {
+	if text == "" {
+		return "", badRequest("a word is required")
+	}
+	word, err := s.alphabet.Tokenise(text)
+	if err != nil {
+		return "", badRequest("word %q: %v", text, err)
+	}
+	return word, nil
+}
+
+// formatWord writes the letters of a word in upper case
+func (s *Server) formatWord(letters string) string {
+	return s.alphabet.FormatWord(model.Word{Chars: letters})
+}
+
+func (s *Server) formatWords(words []string) []string {
+	formatted := make([]string, len(words))
+	for i, word := range words {
+		formatted[i] = s.formatWord(word)
+	}
+	return formatted
+}
+
+func (s *Server) formatLetters(letters []rune) []string {
+	formatted := make([]string, len(letters))
+	for i, letter := range letters {
+		formatted[i] = s.formatWord(string(letter))
+	}
+	return formatted
+}
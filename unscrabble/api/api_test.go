@@ -0,0 +1,182 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/api"
+	"example.com/unscrabble/unscrabble/data"
+	"example.com/unscrabble/unscrabble/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	config, err := data.LoadPreset("scrabble")
+	require.NoError(t, err)
+	lex := testutil.Lexicon("act", "at", "ate", "bat", "cat", "cats", "scat", "tact")
+	server, err := api.NewServer(config, lex)
+	require.NoError(t, err)
+	testServer := httptest.NewServer(server)
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+// boardWithCat is a 15x15 board without premium squares, with CAT played
+// across from 8H
+const boardWithCat = `
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . C A T . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+. . . . . . . . . . . . . . .
+`
+
+func TestMovesRanksMovesOnEmptyBoard(t *testing.T) {
+	server := newTestServer(t)
+
+	var response api.MovesResponse
+	status := testutil.Request(t, server, http.MethodPost, "/moves", api.MovesRequest{Rack: "TCA", Limit: 2}, &response)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, response.Moves, 2)
+	assert.Equal(t, "H6 ACT", response.Moves[0].Notation)
+}
+
+func TestMovesUsesTextBoard(t *testing.T) {
+	server := newTestServer(t)
+
+	board, err := json.Marshal(boardWithCat)
+	require.NoError(t, err)
+	var response api.MovesResponse
+	status := testutil.Request(t, server, http.MethodPost, "/moves", api.MovesRequest{Board: board, Rack: "S"}, &response)
+	require.Equal(t, http.StatusOK, status)
+	notations := make([]string, len(response.Moves))
+	for i, move := range response.Moves {
+		notations[i] = move.Notation
+	}
+	assert.Equal(t, []string{"8G S(CAT)", "8H (CAT)S"}, notations)
+	assert.Equal(t, 6, response.Moves[0].Move.Score)
+}
+
+func TestValidateReportsPhonies(t *testing.T) {
+	server := newTestServer(t)
+	board, err := json.Marshal(boardWithCat)
+	require.NoError(t, err)
+
+	var response api.ValidateResponse
+	status := testutil.Request(t, server, http.MethodPost, "/validate", api.MoveRequest{Board: board, Move: "8G S(CAT)", Rack: "S"}, &response)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, api.ValidateResponse{Valid: true, Score: 6}, response)
+
+	response = api.ValidateResponse{}
+	status = testutil.Request(t, server, http.MethodPost, "/validate", api.MoveRequest{Board: board, Move: "8H (CAT)E"}, &response)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, api.ValidateResponse{Error: "words not in lexicon: cate", Phonies: []string{"CATE"}}, response)
+
+	response = api.ValidateResponse{}
+	status = testutil.Request(t, server, http.MethodPost, "/validate", api.MoveRequest{Board: board, Move: "8H (CAT)S", Rack: "E"}, &response)
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, response.Valid)
+	assert.Equal(t, "rack E does not have the tiles for the move", response.Error)
+}
+
+func TestScoreListsWordsFormed(t *testing.T) {
+	server := newTestServer(t)
+	board, err := json.Marshal(boardWithCat)
+	require.NoError(t, err)
+
+	var response api.ScoreResponse
+	status := testutil.Request(t, server, http.MethodPost, "/score", api.MoveRequest{Board: board, Move: "9H AT"}, &response)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, api.ScoreResponse{Score: 8, Words: []string{"AT", "CA", "AT"}}, response)
+
+	var errResponse api.ErrorResponse
+	status = testutil.Request(t, server, http.MethodPost, "/score", api.MoveRequest{Board: board, Move: "1A AT"}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "move 1A AT: move is not connected to the tiles on the board", errResponse.Error)
+}
+
+func TestLooksUpWords(t *testing.T) {
+	server := newTestServer(t)
+
+	var word api.WordResponse
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/words/cat", nil, &word))
+	assert.Equal(t, api.WordResponse{Word: "CAT", Valid: true}, word)
+	word = api.WordResponse{}
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/words/TAC", nil, &word))
+	assert.Equal(t, api.WordResponse{Word: "TAC", Valid: false}, word)
+
+	var anagrams api.AnagramsResponse
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/anagrams?rack=tca", nil, &anagrams))
+	assert.Equal(t, api.AnagramsResponse{Rack: "ACT", Words: []string{"ACT", "CAT", "AT"}}, anagrams)
+
+	var hooks api.HooksResponse
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/hooks/at", nil, &hooks))
+	assert.Equal(t, api.HooksResponse{Word: "AT", Front: []string{"B", "C"}, Back: []string{"E"}}, hooks)
+}
+
+func TestRejectsBadRequests(t *testing.T) {
+	server := newTestServer(t)
+
+	var response api.ErrorResponse
+	assert.Equal(t, http.StatusMethodNotAllowed, testutil.Request(t, server, http.MethodGet, "/moves", nil, &response))
+	assert.Equal(t, http.StatusNotFound, testutil.Request(t, server, http.MethodGet, "/nothing", nil, &response))
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/moves", map[string]string{"rack": "QQQ"}, &response))
+	assert.Equal(t, `rack "QQQ": rack has 3 'q' tiles but there are only 1`, response.Error)
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodGet, "/anagrams?rack="+strings.Repeat("A", 300), nil, &response))
+	assert.Equal(t, `rack "`+strings.Repeat("A", 300)+`": rack has 300 tiles but can only hold 7`, response.Error)
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/moves", map[string]string{"tiles": "A"}, &response))
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/moves", api.MovesRequest{Board: json.RawMessage(`". . ."`)}, &response))
+	assert.Equal(t, "board is 1x3 but the rules' board is 15x15", response.Error)
+}
+
+func TestServesConcurrentRequests(t *testing.T) {
+	server := newTestServer(t)
+	board, err := json.Marshal(boardWithCat)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(api.MovesRequest{Board: board, Rack: "TASC?"})
+	require.NoError(t, err)
+	var expected api.MovesResponse
+	testutil.Request(t, server, http.MethodPost, "/moves", json.RawMessage(body), &expected)
+	require.NotEmpty(t, expected.Moves)
+
+	// the requests are made without require, which must not be used outside
+	// the test's goroutine
+	var wg sync.WaitGroup
+	responses := make([]api.MovesResponse, 16)
+	errs := make([]error, len(responses))
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := server.Client().Post(server.URL+"/moves", "application/json", bytes.NewReader(body))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer resp.Body.Close()
+			errs[i] = json.NewDecoder(resp.Body).Decode(&responses[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, response := range responses {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, expected, response)
+	}
+}
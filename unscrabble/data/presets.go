@@ -0,0 +1,45 @@
+// Package data contains the rule presets bundled with unscrabble. Each preset
+// is a YAML Configuration embedded from this directory.
+package data
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+//go:embed *.yaml
+var presetFiles embed.FS
+
+const presetExtension = ".yaml"
+
+// PresetNames returns the names of the bundled presets in sorted order
+func PresetNames() []string {
+	entries, err := presetFiles.ReadDir(".")
+	if err != nil {
+		panic(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), presetExtension))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPreset returns the configuration of a bundled preset, e.g. "scrabble",
+// "super_scrabble", "words_with_friends_15x15" or "wordfeud".
+func LoadPreset(name string) (model.Configuration, error) {
+	configBytes, err := presetFiles.ReadFile(name + presetExtension)
+	if err != nil {
+		return model.Configuration{}, fmt.Errorf(
+			"unknown preset %q, the available presets are: %v",
+			name,
+			strings.Join(PresetNames(), ", "),
+		)
+	}
+	return model.ParseConfiguration(configBytes)
+}
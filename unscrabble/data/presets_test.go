@@ -0,0 +1,84 @@
+package data_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetNamesIncludesGameVariants(t *testing.T) {
+	assert.Subset(
+		t,
+		data.PresetNames(),
+		[]string{"scrabble", "super_scrabble", "words_with_friends", "words_with_friends_15x15", "wordfeud"},
+	)
+}
+
+func TestEveryPresetLoadsAndValidates(t *testing.T) {
+	for _, name := range data.PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			config, err := data.LoadPreset(name)
+			require.NoError(t, err)
+			assert.Empty(t, config.Validate())
+		})
+	}
+}
+
+func TestPresetsHaveExpectedRules(t *testing.T) {
+	for name, expected := range map[string]struct {
+		boardSize, tiles, bingoPremium, maxWordMultiplier int
+	}{
+		"scrabble":                 {15, 100, 50, 3},
+		"super_scrabble":           {21, 200, 50, 4},
+		"words_with_friends":       {11, 52, 35, 3},
+		"words_with_friends_15x15": {15, 104, 35, 3},
+		"wordfeud":                 {15, 104, 40, 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config, err := data.LoadPreset(name)
+			require.NoError(t, err)
+
+			tiles := 0
+			for _, count := range config.LetterCounts {
+				tiles += count
+			}
+			maxWordMultiplier := 0
+			for _, row := range config.WordMultipliers {
+				for _, multiplier := range row {
+					if multiplier > maxWordMultiplier {
+						maxWordMultiplier = multiplier
+					}
+				}
+			}
+
+			assert.Equal(t, expected.boardSize, config.BoardSize)
+			assert.Equal(t, expected.tiles, tiles)
+			assert.Equal(t, expected.bingoPremium, config.BingoPremium)
+			assert.Equal(t, expected.maxWordMultiplier, maxWordMultiplier)
+			assert.Equal(t, 7, config.RackSize)
+		})
+	}
+}
+
+func TestLoadPresetReturnsErrorForUnknownPreset(t *testing.T) {
+	_, err := data.LoadPreset("monopoly")
+	assert.Error(t, err)
+}
+
+func TestPresetBoardsAreSymmetric(t *testing.T) {
+	for _, name := range data.PresetNames() {
+		config, err := data.LoadPreset(name)
+		require.NoError(t, err)
+		for _, grid := range [][][]int{config.WordMultipliers, config.LetterMultipliers} {
+			size := len(grid)
+			for y := range grid {
+				for x := range grid[y] {
+					assert.Equal(t, grid[y][x], grid[x][y], "%v (%v, %v)", name, y, x)
+					assert.Equal(t, grid[y][x], grid[size-1-y][size-1-x], "%v (%v, %v)", name, y, x)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gameserver
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// Bot is a strategy that the seats of games can be taken by
+type Bot struct {
+	// Spec is the spec of the strategy in the server's registry, by which the
+	// bot is chosen for a seat
+	Spec        string `json:"spec"`
+	Description string `json:"description"`
+}
+
+// AddBot lets seats be taken by a bot playing the strategy of spec. Only the
+// bots added by the server's operator can be chosen, as the parameters of a
+// strategy may name files on the server.
+func (s *Server) AddBot(spec string) error {
+	definition, _, err := s.registry.Parse(spec)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, bot := range s.bots {
+		if bot.Spec == spec {
+			return nil
+		}
+	}
+	s.bots = append(s.bots, Bot{Spec: spec, Description: definition.Description})
+	return nil
+}
+
+// listBots returns the bots that can be chosen
+func (s *Server) listBots() []Bot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Bot{}, s.bots...)
+}
+
+// newBot returns the strategy of a bot in a game with the named rules. The
+// strategies with the same spec and rules share what they load. Each bot has
+// its own source of randomness, as the games are played concurrently. The
+// server's mutex must be held.
+func (s *Server) newBot(spec, rulesName string, moveGenerator strategy.MoveGenerator) (model.MovePicker, error) {
+	offered := false
+	for _, bot := range s.bots {
+		offered = offered || bot.Spec == spec
+	}
+	if !offered {
+		return nil, fmt.Errorf("unknown strategy %q", spec)
+	}
+	key := rulesName + "\n" + spec
+	newPicker, ok := s.newPickers[key]
+	if !ok {
+		var err error
+		if newPicker, err = s.registry.New(spec, s.rules[rulesName].alphabet); err != nil {
+			return nil, err
+		}
+		s.newPickers[key] = newPicker
+	}
+	return newPicker(moveGenerator, rand.New(rand.NewSource(time.Now().UnixNano()))), nil
+}
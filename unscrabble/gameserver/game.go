@@ -0,0 +1,454 @@
+package gameserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/storage"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// errServerClosed is returned for requests to games after the server has
+// been closed
+var errServerClosed = withStatus(http.StatusServiceUnavailable, "the server is closed")
+
+// eventBuffer is the number of events that can be waiting to be sent to a
+// subscriber. A subscriber that falls further behind is disconnected, and
+// can reconnect to get the current state.
+const eventBuffer = 16
+
+// Seat is a place in a hosted game for a person or a bot. Bot is the strategy
+// of a bot's seat, and is empty for a person's seat.
+type Seat struct {
+	Name  string `json:"name,omitempty"`
+	Bot   string `json:"bot,omitempty"`
+	Taken bool   `json:"taken"`
+}
+
+// GameInfo describes a hosted game. A game starts once every seat is taken.
+type GameInfo struct {
+	ID      string `json:"id"`
+	Rules   string `json:"rules"`
+	Seats   []Seat `json:"seats"`
+	Started bool   `json:"started"`
+	Over    bool   `json:"over"`
+}
+
+// Event is sent to the subscribers of a game. A "state" event has the state of
+// the game as seen by the subscriber, and Seq counts the changes to the game,
+// so a subscriber can tell whether it has missed any. An "error" event is the
+// reason an action from the subscriber was rejected.
+type Event struct {
+	Type  string          `json:"type"`
+	Seq   int             `json:"seq"`
+	Game  *model.GameJSON `json:"game,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// storedGame is the metadata stored with the snapshots of a hosted game
+type storedGame struct {
+	Rules  string         `json:"rules"`
+	Seats  []Seat         `json:"seats"`
+	Tokens map[string]int `json:"tokens"`
+}
+
+// subscriber receives the events of a game for a seat, or for a spectator if
+// seat is model.Spectator
+type subscriber struct {
+	seat   int
+	events chan Event
+}
+
+// hostedGame is a game hosted by a Server. Its state is only used by the
+// goroutine running the game, and requests are passed to that goroutine as
+// functions.
+type hostedGame struct {
+	id            string
+	rulesName     string
+	rules         rules
+	moveGenerator strategy.MoveGenerator
+	requests      chan func()
+	done          chan struct{}
+	// ctx is cancelled when the game is stopped, so that a bot picking its
+	// move stops at once, and each bot has turnTime to pick its move, or as
+	// long as it takes if it is 0
+	ctx      context.Context
+	cancel   context.CancelFunc
+	turnTime time.Duration
+	// store is where the game is stored once it starts, or nil if it is not
+	// stored, and recorder stores its actions
+	store    storage.Store
+	recorder *storage.Recorder
+
+	seats       []Seat
+	pickers     []model.MovePicker
+	tokens      map[string]int
+	game        *model.Game
+	seq         int
+	subscribers map[*subscriber]bool
+	// botQueued is whether the turn of a bot is waiting to be taken, and
+	// botFailed is whether a bot could not take its last turn, which is then
+	// tried again after the next request rather than at once
+	botQueued bool
+	botFailed bool
+}
+
+func newHostedGame(id, rulesName string, rules rules, seats int, store storage.Store) *hostedGame {
+	trieMoveGenerator := triemovegen.NewTrieMoveGenertator(rules.lexicon)
+	letterScores, _ := rules.alphabet.LetterMap(rules.config.LetterScores)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &hostedGame{
+		id:        id,
+		rulesName: rulesName,
+		rules:     rules,
+		moveGenerator: strategy.NewScoringMoveGenerator(
+			&trieMoveGenerator,
+			letterScores,
+			rules.config.RackSize,
+			rules.config.BingoPremium,
+		),
+		requests:    make(chan func()),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+		store:       store,
+		seats:       make([]Seat, seats),
+		pickers:     make([]model.MovePicker, seats),
+		tokens:      make(map[string]int),
+		subscribers: make(map[*subscriber]bool),
+	}
+}
+
+// run handles the requests to the game until it is stopped. The bots take
+// their turns between requests, one turn at a time.
+func (g *hostedGame) run() {
+	g.queueBotTurn()
+	for {
+		select {
+		case request := <-g.requests:
+			request()
+			g.queueBotTurn()
+		case <-g.done:
+			for subscriber := range g.subscribers {
+				g.unsubscribe(subscriber)
+			}
+			return
+		}
+	}
+}
+
+// stop stops the game's goroutine. It must only be called once.
+func (g *hostedGame) stop() {
+	g.cancel()
+	close(g.done)
+}
+
+// do runs f on the game's goroutine and returns its error
+func (g *hostedGame) do(f func() error) error {
+	result := make(chan error, 1)
+	select {
+	case g.requests <- func() { result <- f() }:
+		return <-result
+	case <-g.done:
+		return errServerClosed
+	}
+}
+
+// info describes the game
+func (g *hostedGame) info() (GameInfo, error) {
+	var info GameInfo
+	err := g.do(func() error {
+		info = GameInfo{
+			ID:      g.id,
+			Rules:   g.rulesName,
+			Seats:   append([]Seat(nil), g.seats...),
+			Started: g.game != nil,
+			Over:    g.game != nil && g.game.IsOver(),
+		}
+		return nil
+	})
+	return info, err
+}
+
+// join gives a person a seat and returns its token
+func (g *hostedGame) join(seat int, name string) (JoinResponse, error) {
+	var response JoinResponse
+	err := g.do(func() error {
+		if seat < 0 || seat >= len(g.seats) {
+			return withStatus(http.StatusBadRequest, "there is no seat %v", seat)
+		}
+		if g.seats[seat].Taken {
+			return withStatus(http.StatusConflict, "seat %v is taken", seat)
+		}
+		if name == "" {
+			name = g.seats[seat].Name
+		}
+		if name == "" {
+			return withStatus(http.StatusBadRequest, "a name is required")
+		}
+		token, err := newToken(16)
+		if err != nil {
+			return err
+		}
+		g.seats[seat].Name = name
+		g.seats[seat].Taken = true
+		g.tokens[token] = seat
+		response = JoinResponse{Seat: seat, Token: token}
+		return g.startIfReady()
+	})
+	return response, err
+}
+
+// startIfReady starts the game once every seat is taken
+func (g *hostedGame) startIfReady() error {
+	for _, seat := range g.seats {
+		if !seat.Taken {
+			return nil
+		}
+	}
+	players := make([]*model.Player, len(g.seats))
+	for i, seat := range g.seats {
+		players[i] = model.NewPlayer(seat.Name, g.pickers[i])
+	}
+	game, err := model.NewGame(g.rules.config, g.rules.lexicon, players...)
+	if err != nil {
+		return err
+	}
+	g.game = game
+	g.changed()
+	return nil
+}
+
+// viewer returns the seat of token, or model.Spectator if token is empty
+func (g *hostedGame) viewer(token string) (int, error) {
+	if token == "" {
+		return model.Spectator, nil
+	}
+	seat, ok := g.tokens[token]
+	if !ok {
+		return 0, withStatus(http.StatusForbidden, "the token is not for a seat of the game")
+	}
+	return seat, nil
+}
+
+// state returns the state of the game as seen by the seat of token
+func (g *hostedGame) state(token string) (model.GameJSON, error) {
+	var state model.GameJSON
+	err := g.do(func() error {
+		viewer, err := g.viewer(token)
+		if err != nil {
+			return err
+		}
+		if g.game == nil {
+			return withStatus(http.StatusConflict, "the game has not started")
+		}
+		state = g.game.JSON(viewer)
+		return nil
+	})
+	return state, err
+}
+
+// act takes a turn for the seat of token and returns the state of the game
+// after it
+func (g *hostedGame) act(token string, action Action) (model.GameJSON, error) {
+	var state model.GameJSON
+	err := g.do(func() error {
+		seat, err := g.viewer(token)
+		if err != nil {
+			return err
+		}
+		if err := g.takeTurn(seat, action); err != nil {
+			return err
+		}
+		state = g.game.JSON(seat)
+		return nil
+	})
+	return state, err
+}
+
+// takeTurn takes a turn for a seat
+func (g *hostedGame) takeTurn(seat int, action Action) error {
+	switch {
+	case seat == model.Spectator:
+		return withStatus(http.StatusForbidden, "spectators cannot take turns")
+	case g.game == nil:
+		return withStatus(http.StatusConflict, "the game has not started")
+	case g.game.IsOver():
+		return withStatus(http.StatusConflict, "the game is over")
+	case g.game.CurrentPlayer() != seat:
+		return withStatus(http.StatusConflict, "it is not your turn")
+	}
+
+	var err error
+	switch action.Type {
+	case "play":
+		var move model.Move
+		if move, err = g.rules.alphabet.ParseMove(g.game.Board(), action.Move); err == nil {
+			err = g.game.PlayMove(move)
+		}
+	case "exchange":
+		var rack *model.Rack
+		rack, err = g.rules.alphabet.ParseRack(action.Tiles, utf8.RuneCountInString(action.Tiles), nil)
+		if err == nil {
+			err = g.game.Exchange(rack.Letters()...)
+		}
+	case "pass":
+		err = g.game.Pass()
+	case "challenge":
+		err = g.game.Challenge()
+	case "accept":
+		err = g.game.AcceptPlay()
+	default:
+		return withStatus(http.StatusBadRequest, "unknown action %q", action.Type)
+	}
+	if err != nil {
+		return withStatus(http.StatusUnprocessableEntity, "%v", err)
+	}
+	g.changed()
+	return nil
+}
+
+// botsTurn is whether it is the turn of a bot in a game that is not over
+func (g *hostedGame) botsTurn() bool {
+	return g.game != nil && !g.game.IsOver() && g.pickers[g.game.CurrentPlayer()] != nil
+}
+
+// queueBotTurn queues the turn of the current player if it is a bot, as a
+// request to the game. The other requests to the game are handled between the
+// turns of bots rather than waiting for them all.
+func (g *hostedGame) queueBotTurn() {
+	if g.botFailed {
+		g.botFailed = false
+		return
+	}
+	if g.botQueued || !g.botsTurn() {
+		return
+	}
+	g.botQueued = true
+	go func() {
+		select {
+		case g.requests <- func() {
+			g.botQueued = false
+			g.botFailed = g.botsTurn() && !g.takeBotTurn()
+		}:
+		case <-g.done:
+		}
+	}()
+}
+
+// takeBotTurn takes the turn of the current player, which is a bot, and
+// returns whether it was taken
+func (g *hostedGame) takeBotTurn() bool {
+	if err := g.playBot(); errors.Is(err, context.Canceled) {
+		// the game is being stopped, and the bot takes its turn when the
+		// game is restored
+		return false
+	} else if err != nil {
+		// the bot cannot take its turn, which should not happen as bots
+		// fall back to exchanging or passing
+		g.broadcastError(fmt.Errorf("%v could not take their turn: %w", g.seats[g.game.CurrentPlayer()].Name, err))
+		return false
+	}
+	g.changed()
+	return true
+}
+
+// playBot takes the turn of the current player, which is a bot
+func (g *hostedGame) playBot() error {
+	if g.turnTime == 0 {
+		return g.game.PlayTurnContext(g.ctx)
+	}
+	ctx, cancel := context.WithTimeout(g.ctx, g.turnTime)
+	defer cancel()
+	return g.game.PlayTurnContext(ctx)
+}
+
+// save stores the actions taken since the game was last saved. The game is
+// first stored when it starts, with the seats and their tokens.
+func (g *hostedGame) save() error {
+	if g.store == nil || g.game == nil {
+		return nil
+	}
+	if g.recorder != nil {
+		return g.recorder.Record()
+	}
+	metadata, err := json.Marshal(storedGame{Rules: g.rulesName, Seats: g.seats, Tokens: g.tokens})
+	if err != nil {
+		return err
+	}
+	g.recorder, err = storage.NewRecorder(g.store, g.id, g.game, metadata)
+	return err
+}
+
+// changed saves the game and sends its new state to its subscribers
+func (g *hostedGame) changed() {
+	if err := g.save(); err != nil {
+		g.broadcastError(fmt.Errorf("the game could not be saved: %w", err))
+	}
+	g.seq++
+	for subscriber := range g.subscribers {
+		g.send(subscriber, g.stateEvent(subscriber.seat))
+	}
+}
+
+func (g *hostedGame) stateEvent(viewer int) Event {
+	event := Event{Type: "state", Seq: g.seq}
+	if g.game != nil {
+		state := g.game.JSON(viewer)
+		event.Game = &state
+	}
+	return event
+}
+
+func (g *hostedGame) broadcastError(err error) {
+	for subscriber := range g.subscribers {
+		g.send(subscriber, Event{Type: "error", Seq: g.seq, Error: err.Error()})
+	}
+}
+
+// send sends an event to a subscriber, and disconnects the subscriber if it
+// has fallen too far behind
+func (g *hostedGame) send(subscriber *subscriber, event Event) {
+	select {
+	case subscriber.events <- event:
+	default:
+		g.unsubscribe(subscriber)
+	}
+}
+
+// subscribe returns a subscriber for the seat of token, which replaces any
+// other subscriber for the seat. The current state is the first event.
+func (g *hostedGame) subscribe(token string) (*subscriber, error) {
+	var subscribed *subscriber
+	err := g.do(func() error {
+		seat, err := g.viewer(token)
+		if err != nil {
+			return err
+		}
+		for other := range g.subscribers {
+			if seat != model.Spectator && other.seat == seat {
+				g.unsubscribe(other)
+			}
+		}
+		subscribed = &subscriber{seat: seat, events: make(chan Event, eventBuffer)}
+		g.subscribers[subscribed] = true
+		g.send(subscribed, g.stateEvent(seat))
+		return nil
+	})
+	return subscribed, err
+}
+
+// unsubscribe stops sending events to a subscriber, and closes its events
+func (g *hostedGame) unsubscribe(subscriber *subscriber) {
+	if g.subscribers[subscriber] {
+		delete(g.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
@@ -0,0 +1,472 @@
+// Package gameserver hosts games between people and bots over HTTP. Players
+// take seats in a game and submit their turns, and the state of the game is
+// pushed to them over a WebSocket after every turn. Each player only sees
+// their own rack.
+package gameserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/storage"
+	"example.com/unscrabble/unscrabble/strategy"
+	"github.com/gorilla/websocket"
+)
+
+// maxRequestBytes limits the size of request bodies
+const maxRequestBytes = 1 << 20
+
+// SeatRequest is a seat of a game to be created. A seat with a bot, which is
+// the spec of one of the server's bots, is taken by the bot, and the other
+// seats are left for people to join.
+type SeatRequest struct {
+	Bot  string `json:"bot,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// CreateRequest asks for a game to be created with the named rules, which are
+// the server's default rules if the name is empty
+type CreateRequest struct {
+	Rules string        `json:"rules,omitempty"`
+	Seats []SeatRequest `json:"seats"`
+}
+
+// JoinRequest asks for a person's seat in a game
+type JoinRequest struct {
+	Seat int    `json:"seat"`
+	Name string `json:"name"`
+}
+
+// JoinResponse is the token of a seat that has been joined. The token is
+// needed to take turns from the seat, to see its rack, and to reconnect to the
+// game.
+type JoinResponse struct {
+	Seat  int    `json:"seat"`
+	Token string `json:"token"`
+}
+
+// Action is a turn taken by a person. The token is only needed for actions
+// posted over HTTP, as a WebSocket is already connected to its seat.
+//
+// The types of action are "play", with a move in standard notation,
+// "exchange", with the tiles to exchange, "pass", "challenge" and "accept".
+type Action struct {
+	Token string `json:"token,omitempty"`
+	Type  string `json:"type"`
+	Move  string `json:"move,omitempty"`
+	Tiles string `json:"tiles,omitempty"`
+}
+
+// ErrorResponse is the body of every response with an error status
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// rules are the rules and lexicon that games can be hosted with
+type rules struct {
+	config   model.Configuration
+	alphabet *model.Alphabet
+	lexicon  *lexicon.TrieNode
+}
+
+// Server is an http.Handler hosting games. The games are kept in memory, and
+// in a store if the server has one, and each is run by its own goroutine.
+//
+// The endpoints are:
+//
+//	POST /games                  CreateRequest -> GameInfo
+//	GET  /games                  the GameInfo of every game
+//	GET  /games/ID               GameInfo
+//	POST /games/ID/join          JoinRequest -> JoinResponse
+//	POST /games/ID/actions       Action -> model.GameJSON seen by the seat
+//	GET  /games/ID/state?token=  model.GameJSON seen by the seat
+//	GET  /games/ID/ws?token=     a WebSocket of Events, which accepts Actions
+//	GET  /strategies             the Bot of each strategy seats can be taken by
+//
+// Without a token the state of a game is seen as by a spectator, who sees none
+// of the racks. Reconnecting a seat's WebSocket replaces its old connection,
+// and the current state of the game is always sent first.
+type Server struct {
+	registry     *strategy.Registry
+	turnTime     time.Duration
+	rules        map[string]rules
+	defaultRules string
+	upgrader     websocket.Upgrader
+
+	mutex      sync.Mutex
+	games      map[string]*hostedGame
+	bots       []Bot
+	newPickers map[string]strategy.NewPicker
+	store      storage.Store
+	closed     bool
+}
+
+// NewServer returns a server whose bots play the strategies of registry. Rules
+// must be added before games can be created, and bots before they can take
+// seats.
+func NewServer(registry *strategy.Registry) *Server {
+	return &Server{
+		registry:   registry,
+		rules:      make(map[string]rules),
+		games:      make(map[string]*hostedGame),
+		newPickers: make(map[string]strategy.NewPicker),
+	}
+}
+
+// SetTurnTime gives the bots of the games created or restored from now on
+// turnTime to pick each move. Bots whose strategies are not a
+// model.ContextMovePicker cannot be told the time they have, and if they take
+// longer the move they pick is not played.
+func (s *Server) SetTurnTime(turnTime time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.turnTime = turnTime
+}
+
+// AddRules lets games be created with the rules of config, which must be
+// valid, and a lexicon. The first rules added are the default rules.
+func (s *Server) AddRules(name string, config model.Configuration, lex *lexicon.TrieNode) error {
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.rules) == 0 {
+		s.defaultRules = name
+	}
+	s.rules[name] = rules{config: config, alphabet: alphabet, lexicon: lex}
+	return nil
+}
+
+// UseStore stores the games started from now on in store, and hosts the games
+// already stored in it, so that games survive a restart of the server. The
+// rules of the stored games must have been added. Games are stored once every
+// seat is taken, so games waiting for players are not restored.
+func (s *Server) UseStore(store storage.Store) error {
+	ids, err := store.IDs()
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return errServerClosed
+	}
+	s.store = store
+	for _, id := range ids {
+		if _, ok := s.games[id]; ok {
+			continue
+		}
+		game, err := s.restoreGame(id)
+		if err != nil {
+			return fmt.Errorf("game %v: %w", id, err)
+		}
+		s.games[id] = game
+		go game.run()
+	}
+	return nil
+}
+
+// restoreGame recreates a game from the store, with its seats, tokens and
+// bots. Bots whose turn it is take their turns once the game is run, so
+// that restoring games does not wait for them.
+func (s *Server) restoreGame(id string) (*hostedGame, error) {
+	snapshot, events, err := s.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	var stored storedGame
+	if err := json.Unmarshal(snapshot.Metadata, &stored); err != nil {
+		return nil, err
+	}
+	rules, ok := s.rules[stored.Rules]
+	if !ok {
+		return nil, fmt.Errorf("unknown rules %q", stored.Rules)
+	}
+	game := newHostedGame(id, stored.Rules, rules, len(stored.Seats), s.store)
+	game.turnTime = s.turnTime
+	copy(game.seats, stored.Seats)
+	game.tokens = stored.Tokens
+	players := make([]*model.Player, len(stored.Seats))
+	for i, seat := range stored.Seats {
+		if seat.Bot != "" {
+			if game.pickers[i], err = s.newBot(seat.Bot, stored.Rules, game.moveGenerator); err != nil {
+				return nil, err
+			}
+		}
+		players[i] = model.NewPlayer(seat.Name, game.pickers[i])
+	}
+	if game.recorder, err = storage.Restore(s.store, id, snapshot, events, rules.lexicon, players...); err != nil {
+		return nil, err
+	}
+	game.game = game.recorder.Game()
+	return game, game.save()
+}
+
+// Close stops every game and disconnects their players
+func (s *Server) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, game := range s.games {
+		game.stop()
+	}
+}
+
+// statusError is an error with the HTTP status of the response for it
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func withStatus(status int, format string, args ...interface{}) error {
+	return &statusError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if path == "strategies" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.listBots())
+		return
+	}
+	if parts[0] != "games" || len(parts) > 3 {
+		writeError(w, withStatus(http.StatusNotFound, "there is no endpoint %v", r.URL.Path))
+		return
+	}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.list())
+		case http.MethodPost:
+			s.create(w, r)
+		default:
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	game, err := s.game(parts[1])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	endpoint := ""
+	if len(parts) == 3 {
+		endpoint = parts[2]
+	}
+	method := map[string]string{
+		"":        http.MethodGet,
+		"join":    http.MethodPost,
+		"actions": http.MethodPost,
+		"state":   http.MethodGet,
+		"ws":      http.MethodGet,
+	}[endpoint]
+	switch {
+	case method == "":
+		writeError(w, withStatus(http.StatusNotFound, "there is no endpoint %v", r.URL.Path))
+	case r.Method != method:
+		methodNotAllowed(w, r, method)
+	case endpoint == "":
+		s.respond(w, func() (interface{}, error) { return game.info() })
+	case endpoint == "join":
+		s.respond(w, func() (interface{}, error) {
+			var request JoinRequest
+			if err := decodeRequest(r, &request); err != nil {
+				return nil, err
+			}
+			return game.join(request.Seat, request.Name)
+		})
+	case endpoint == "actions":
+		s.respond(w, func() (interface{}, error) {
+			var action Action
+			if err := decodeRequest(r, &action); err != nil {
+				return nil, err
+			}
+			return game.act(action.Token, action)
+		})
+	case endpoint == "state":
+		s.respond(w, func() (interface{}, error) { return game.state(r.URL.Query().Get("token")) })
+	case endpoint == "ws":
+		s.serveWebSocket(w, r, game)
+	}
+}
+
+// respond writes the result of handle as JSON
+func (s *Server) respond(w http.ResponseWriter, handle func() (interface{}, error)) {
+	response, err := handle()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) list() []GameInfo {
+	s.mutex.Lock()
+	games := make([]*hostedGame, 0, len(s.games))
+	for _, game := range s.games {
+		games = append(games, game)
+	}
+	s.mutex.Unlock()
+
+	infos := make([]GameInfo, 0, len(games))
+	for _, game := range games {
+		if info, err := game.info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var request CreateRequest
+	if err := decodeRequest(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	game, err := s.newGame(request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	info, err := game.info()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// newGame creates a game and starts its goroutine
+func (s *Server) newGame(request CreateRequest) (*hostedGame, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, errServerClosed
+	}
+	name := request.Rules
+	if name == "" {
+		name = s.defaultRules
+	}
+	rules, ok := s.rules[name]
+	if !ok {
+		return nil, withStatus(http.StatusBadRequest, "unknown rules %q", request.Rules)
+	}
+	if len(request.Seats) == 0 {
+		return nil, withStatus(http.StatusBadRequest, "a game needs at least one seat")
+	}
+	tiles := 0
+	for _, count := range rules.config.LetterCounts {
+		tiles += count
+	}
+	if len(request.Seats)*rules.config.RackSize > tiles {
+		return nil, withStatus(http.StatusBadRequest, "there are not enough tiles for %v seats", len(request.Seats))
+	}
+
+	id, err := newToken(8)
+	if err != nil {
+		return nil, err
+	}
+	game := newHostedGame(id, name, rules, len(request.Seats), s.store)
+	game.turnTime = s.turnTime
+	for i, seat := range request.Seats {
+		if seat.Bot == "" {
+			game.seats[i] = Seat{Name: seat.Name}
+			continue
+		}
+		picker, err := s.newBot(seat.Bot, name, game.moveGenerator)
+		if err != nil {
+			return nil, withStatus(http.StatusBadRequest, "seat %v: %v", i, err)
+		}
+		if seat.Name == "" {
+			seat.Name = fmt.Sprintf("Bot (%v)", seat.Bot)
+		}
+		game.seats[i] = Seat{Name: seat.Name, Bot: seat.Bot, Taken: true}
+		game.pickers[i] = picker
+	}
+	s.games[id] = game
+	go game.run()
+	return game, game.do(game.startIfReady)
+}
+
+func (s *Server) game(id string) (*hostedGame, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	game, ok := s.games[id]
+	if !ok {
+		return nil, withStatus(http.StatusNotFound, "there is no game %q", id)
+	}
+	return game, nil
+}
+
+// newToken returns a random hex string of n bytes
+func newToken(n int) (string, error) {
+	token := make([]byte, n)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, withStatus(
+		http.StatusMethodNotAllowed,
+		"%v must be requested with %v",
+		r.URL.Path,
+		strings.Join(methods, " or "),
+	))
+}
+
+// decodeRequest decodes the JSON body of a request into request
+func decodeRequest(r *http.Request, request interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		return withStatus(http.StatusBadRequest, "invalid request: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		status = statusErr.status
+	}
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,449 @@
+package gameserver_test
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/unscrabble/unscrabble/gameserver"
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/storage"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/testutil"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a server hosting games on a Scrabble board in which
+// every tile is an A, so the racks are known in advance
+func newTestServer(t *testing.T) *httptest.Server {
+	return newStoredServer(t, nil)
+}
+
+// newStoredServer returns a test server which stores its games in store if it
+// is not nil
+func newStoredServer(t *testing.T, store storage.Store) *httptest.Server {
+	return newServer(t, store, strategy.DefaultRegistry, func(*gameserver.Server) {})
+}
+
+// newServer returns a test server whose bots play the strategies of registry,
+// set up by setup
+func newServer(
+	t *testing.T,
+	store storage.Store,
+	registry *strategy.Registry,
+	setup func(*gameserver.Server),
+) *httptest.Server {
+	config := testutil.Configuration(t, 3, map[string]int{"a": 8})
+	lex := testutil.Lexicon("aa", "aaa")
+
+	server := gameserver.NewServer(registry)
+	require.NoError(t, server.AddRules("test", config, lex))
+	require.NoError(t, server.AddBot("highscore"))
+	setup(server)
+	if store != nil {
+		require.NoError(t, server.UseStore(store))
+	}
+	testServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		server.Close()
+		testServer.Close()
+	})
+	return testServer
+}
+
+func createGame(t *testing.T, server *httptest.Server, seats ...gameserver.SeatRequest) gameserver.GameInfo {
+	var info gameserver.GameInfo
+	status := testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{Seats: seats}, &info)
+	require.Equal(t, http.StatusCreated, status)
+	return info
+}
+
+func join(t *testing.T, server *httptest.Server, id string, seat int, name string) string {
+	var joined gameserver.JoinResponse
+	status := testutil.Request(t, server, http.MethodPost, "/games/"+id+"/join", gameserver.JoinRequest{Seat: seat, Name: name}, &joined)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, seat, joined.Seat)
+	return joined.Token
+}
+
+func dial(t *testing.T, server *httptest.Server, id, token string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/" + id + "/ws?token=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) gameserver.Event {
+	var event gameserver.Event
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&event))
+	return event
+}
+
+func TestGameStartsWhenSeatsAreTaken(t *testing.T) {
+	server := newTestServer(t)
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{Bot: "highscore"})
+	assert.Equal(t, "test", info.Rules)
+	assert.False(t, info.Started)
+	assert.Equal(t, []gameserver.Seat{{}, {Name: "Bot (highscore)", Bot: "highscore", Taken: true}}, info.Seats)
+
+	var errResponse gameserver.ErrorResponse
+	assert.Equal(t, http.StatusConflict, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID+"/state", nil, &errResponse))
+	assert.Equal(t, "the game has not started", errResponse.Error)
+
+	join(t, server, info.ID, 0, "Ann")
+	assert.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID, nil, &info))
+	assert.True(t, info.Started)
+	assert.Equal(t, "Ann", info.Seats[0].Name)
+
+	var infos []gameserver.GameInfo
+	assert.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games", nil, &infos))
+	assert.Equal(t, []gameserver.GameInfo{info}, infos)
+
+	assert.Equal(t, http.StatusConflict, testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/join", gameserver.JoinRequest{Seat: 0, Name: "Bob"}, &errResponse))
+	assert.Equal(t, "seat 0 is taken", errResponse.Error)
+}
+
+func TestPlayersOnlySeeTheirOwnRack(t *testing.T) {
+	server := newTestServer(t)
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{})
+	ann := join(t, server, info.ID, 0, "Ann")
+	join(t, server, info.ID, 1, "Bob")
+
+	var state model.GameJSON
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID+"/state?token="+ann, nil, &state))
+	assert.Equal(t, 0, state.Viewer)
+	assert.Equal(t, "AAA", state.Players[0].Rack.Tiles)
+	assert.Empty(t, state.Players[1].Rack.Tiles)
+	assert.Equal(t, 3, state.Players[1].Rack.TileCount)
+
+	state = model.GameJSON{}
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID+"/state", nil, &state))
+	assert.Equal(t, model.Spectator, state.Viewer)
+	assert.Empty(t, state.Players[0].Rack.Tiles)
+
+	var errResponse gameserver.ErrorResponse
+	assert.Equal(t, http.StatusForbidden, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID+"/state?token=nope", nil, &errResponse))
+}
+
+func TestActionsArePostedAndPushed(t *testing.T) {
+	server := newTestServer(t)
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{})
+	ann := join(t, server, info.ID, 0, "Ann")
+	bob := join(t, server, info.ID, 1, "Bob")
+	bobConn := dial(t, server, info.ID, bob)
+	event := readEvent(t, bobConn)
+	assert.Equal(t, "state", event.Type)
+	require.NotNil(t, event.Game)
+	assert.Equal(t, "AAA", event.Game.Players[1].Rack.Tiles)
+
+	var errResponse gameserver.ErrorResponse
+	status := testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: bob, Type: "pass"}, &errResponse)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "it is not your turn", errResponse.Error)
+
+	var state model.GameJSON
+	status = testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: ann, Type: "play", Move: "8H AA"}, &state)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, state.CurrentPlayer)
+	assert.Equal(t, 4, state.Players[0].Score)
+
+	pushed := readEvent(t, bobConn)
+	assert.Equal(t, "state", pushed.Type)
+	assert.Equal(t, event.Seq+1, pushed.Seq)
+	require.Len(t, pushed.Game.History, 1)
+	assert.Equal(t, "AA", pushed.Game.History[0].Move.Word)
+	assert.Empty(t, pushed.Game.History[0].Rack.Tiles)
+
+	require.NoError(t, bobConn.WriteJSON(gameserver.Action{Type: "play", Move: "1A AAA"}))
+	rejected := readEvent(t, bobConn)
+	assert.Equal(t, "error", rejected.Type)
+	assert.Equal(t, "move is not connected to the tiles on the board", rejected.Error)
+
+	require.NoError(t, bobConn.WriteJSON(gameserver.Action{Type: "pass"}))
+	passed := readEvent(t, bobConn)
+	assert.Equal(t, pushed.Seq+1, passed.Seq)
+	assert.Equal(t, 0, passed.Game.CurrentPlayer)
+}
+
+func TestBotsReplyToPeople(t *testing.T) {
+	server := newTestServer(t)
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{Bot: "highscore"})
+	ann := join(t, server, info.ID, 0, "Ann")
+	conn := dial(t, server, info.ID, ann)
+	readEvent(t, conn)
+
+	require.NoError(t, conn.WriteJSON(gameserver.Action{Type: "play", Move: "8H AA"}))
+	assert.Len(t, readEvent(t, conn).Game.History, 1)
+	// the bot goes out with its reply
+	reply := readEvent(t, conn)
+	require.Len(t, reply.Game.History, 3)
+	assert.Equal(t, model.TurnJSON{
+		Player: 1,
+		Type:   model.PlayTurn,
+		Rack:   model.RackJSON{TileCount: 3, Capacity: 3},
+		Move:   &model.MoveJSON{Row: 6, Column: 6, Horizontal: true, Word: "AAA", Score: 10},
+		Score:  10,
+		Total:  10,
+	}, reply.Game.History[1])
+	assert.True(t, reply.Game.Over)
+}
+
+func TestOnlyOfferedBotsTakeSeats(t *testing.T) {
+	server := newTestServer(t)
+	var bots []gameserver.Bot
+	assert.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/strategies", nil, &bots))
+	assert.Equal(t, []gameserver.Bot{{Spec: "highscore", Description: "plays the highest scoring move"}}, bots)
+
+	// random is registered but the server does not offer it
+	var errResponse gameserver.ErrorResponse
+	status := testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{Seats: []gameserver.SeatRequest{{Bot: "random"}}}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `seat 0: unknown strategy "random"`, errResponse.Error)
+	assert.Equal(t, http.StatusMethodNotAllowed, testutil.Request(t, server, http.MethodPost, "/strategies", nil, &errResponse))
+}
+
+// patientStrategy plays the highest scoring move once its deadline has passed
+type patientStrategy struct {
+	*strategy.HighScoreStrategy
+}
+
+func (s patientStrategy) PickMoveContext(ctx context.Context, board model.Board, rack model.Rack) *model.Move {
+	<-ctx.Done()
+	return s.PickMove(board, rack)
+}
+
+func TestBotsAreGivenTheTurnTime(t *testing.T) {
+	registry := strategy.NewRegistry()
+	require.NoError(t, registry.Register(strategy.Definition{
+		Name:        "highscore",
+		Description: "takes all the time it is given",
+		New: func(strategy.Params, *model.Alphabet) (strategy.NewPicker, error) {
+			return func(moveGenerator strategy.MoveGenerator, _ *rand.Rand) model.MovePicker {
+				return patientStrategy{strategy.NewHighScoreStrategy(moveGenerator)}
+			}, nil
+		},
+	}))
+	server := newServer(t, nil, registry, func(server *gameserver.Server) {
+		server.SetTurnTime(20 * time.Millisecond)
+	})
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{Bot: "highscore"})
+	ann := join(t, server, info.ID, 0, "Ann")
+	conn := dial(t, server, info.ID, ann)
+	readEvent(t, conn)
+
+	require.NoError(t, conn.WriteJSON(gameserver.Action{Type: "play", Move: "8H AA"}))
+	assert.Len(t, readEvent(t, conn).Game.History, 1)
+	reply := readEvent(t, conn)
+	require.Len(t, reply.Game.History, 3)
+	assert.Equal(t, 10, reply.Game.History[1].Score)
+}
+
+// passesAtDeadline passes once its deadline has passed
+type passesAtDeadline struct{}
+
+func (passesAtDeadline) PickMove(board model.Board, rack model.Rack) *model.Move {
+	return nil
+}
+
+func (passesAtDeadline) PickMoveContext(ctx context.Context, board model.Board, rack model.Rack) *model.Move {
+	<-ctx.Done()
+	return nil
+}
+
+func TestRequestsAreAnsweredBetweenTurnsOfBots(t *testing.T) {
+	registry := strategy.NewRegistry()
+	require.NoError(t, registry.Register(strategy.Definition{
+		Name:        "highscore",
+		Description: "passes when its time is up",
+		New: func(strategy.Params, *model.Alphabet) (strategy.NewPicker, error) {
+			return func(strategy.MoveGenerator, *rand.Rand) model.MovePicker {
+				return passesAtDeadline{}
+			}, nil
+		},
+	}))
+	server := newServer(t, nil, registry, func(server *gameserver.Server) {
+		server.SetTurnTime(50 * time.Millisecond)
+	})
+	info := createGame(t, server, gameserver.SeatRequest{Bot: "highscore"}, gameserver.SeatRequest{Bot: "highscore"})
+	assert.True(t, info.Started)
+
+	// the bots pass until the game is over, but each request only waits for
+	// the turn being taken
+	var games []gameserver.GameInfo
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games", nil, &games))
+	require.Len(t, games, 1)
+	assert.False(t, games[0].Over)
+	require.Equal(t, http.StatusOK, testutil.Request(t, server, http.MethodGet, "/games/"+info.ID, nil, &info))
+	assert.False(t, info.Over)
+}
+
+// blocksUntilReleased does not know about deadlines, and does not pick a move
+// until it is released
+type blocksUntilReleased struct {
+	released chan struct{}
+}
+
+func (s blocksUntilReleased) PickMove(board model.Board, rack model.Rack) *model.Move {
+	<-s.released
+	return nil
+}
+
+func TestBotsIgnoringTheTurnTimeDoNotHoldUpGames(t *testing.T) {
+	released := make(chan struct{})
+	t.Cleanup(func() { close(released) })
+	registry := strategy.NewRegistry()
+	require.NoError(t, registry.Register(strategy.Definition{
+		Name:        "highscore",
+		Description: "never picks a move in time",
+		New: func(strategy.Params, *model.Alphabet) (strategy.NewPicker, error) {
+			return func(strategy.MoveGenerator, *rand.Rand) model.MovePicker {
+				return blocksUntilReleased{released}
+			}, nil
+		},
+	}))
+	server := newServer(t, nil, registry, func(server *gameserver.Server) {
+		server.SetTurnTime(20 * time.Millisecond)
+	})
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{Bot: "highscore"})
+	ann := join(t, server, info.ID, 0, "Ann")
+	conn := dial(t, server, info.ID, ann)
+	readEvent(t, conn)
+
+	require.NoError(t, conn.WriteJSON(gameserver.Action{Type: "play", Move: "8H AA"}))
+	assert.Len(t, readEvent(t, conn).Game.History, 1)
+	reply := readEvent(t, conn)
+	require.Len(t, reply.Game.History, 2)
+	assert.Equal(t, model.PassTurn, reply.Game.History[1].Type)
+	assert.Equal(t, 0, reply.Game.CurrentPlayer)
+}
+
+func TestReconnectReplacesConnection(t *testing.T) {
+	server := newTestServer(t)
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{})
+	ann := join(t, server, info.ID, 0, "Ann")
+	join(t, server, info.ID, 1, "Bob")
+
+	first := dial(t, server, info.ID, ann)
+	event := readEvent(t, first)
+	second := dial(t, server, info.ID, ann)
+	assert.Equal(t, event, readEvent(t, second))
+
+	require.NoError(t, first.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := first.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "%v", err)
+
+	require.NoError(t, second.WriteJSON(gameserver.Action{Type: "pass"}))
+	assert.Equal(t, event.Seq+1, readEvent(t, second).Seq)
+}
+
+func TestStoredGamesSurviveRestart(t *testing.T) {
+	store := storage.NewMemoryStore()
+	server := newStoredServer(t, store)
+	waiting := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{})
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{})
+	ann := join(t, server, info.ID, 0, "Ann")
+	bob := join(t, server, info.ID, 1, "Bob")
+	var state model.GameJSON
+	status := testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: ann, Type: "play", Move: "8H AA"}, &state)
+	require.Equal(t, http.StatusOK, status)
+
+	restarted := newStoredServer(t, store)
+	var infos []gameserver.GameInfo
+	require.Equal(t, http.StatusOK, testutil.Request(t, restarted, http.MethodGet, "/games", nil, &infos))
+	require.Len(t, infos, 1)
+	assert.Equal(t, info.ID, infos[0].ID)
+	assert.True(t, infos[0].Started)
+	var errResponse gameserver.ErrorResponse
+	assert.Equal(t, http.StatusNotFound, testutil.Request(t, restarted, http.MethodGet, "/games/"+waiting.ID, nil, &errResponse))
+
+	var restored model.GameJSON
+	require.Equal(t, http.StatusOK, testutil.Request(t, restarted, http.MethodGet, "/games/"+info.ID+"/state?token="+ann, nil, &restored))
+	assert.Equal(t, state, restored)
+	status = testutil.Request(t, restarted, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: bob, Type: "pass"}, &state)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 0, state.CurrentPlayer)
+
+	_, events, err := store.Load(info.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
+
+func TestRestoredBotsTakeTheirTurnsOnceRestored(t *testing.T) {
+	registry := strategy.NewRegistry()
+	require.NoError(t, registry.Register(strategy.Definition{
+		Name:        "highscore",
+		Description: "passes when its time is up",
+		New: func(strategy.Params, *model.Alphabet) (strategy.NewPicker, error) {
+			return func(strategy.MoveGenerator, *rand.Rand) model.MovePicker {
+				return passesAtDeadline{}
+			}, nil
+		},
+	}))
+	store := storage.NewMemoryStore()
+	server := newServer(t, store, registry, func(server *gameserver.Server) {
+		server.SetTurnTime(time.Hour)
+	})
+	info := createGame(t, server, gameserver.SeatRequest{}, gameserver.SeatRequest{Bot: "highscore"})
+	ann := join(t, server, info.ID, 0, "Ann")
+	var state model.GameJSON
+	status := testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: ann, Type: "play", Move: "8H AA"}, &state)
+	require.Equal(t, http.StatusOK, status)
+
+	// the bot is still thinking when the server is restarted, and the
+	// restarted server takes its turn after it has restored the game
+	start := time.Now()
+	newServer(t, store, registry, func(server *gameserver.Server) {
+		server.SetTurnTime(10 * time.Second)
+	})
+	assert.True(t, time.Since(start) < 5*time.Second, "restoring took %v", time.Since(start))
+	restarted := newStoredServer(t, store)
+	conn := dial(t, restarted, info.ID, ann)
+	event := readEvent(t, conn)
+	if len(event.Game.History) < 2 {
+		event = readEvent(t, conn)
+	}
+	require.GreaterOrEqual(t, len(event.Game.History), 2)
+	assert.Equal(t, 1, event.Game.History[1].Player)
+	assert.Equal(t, 10, event.Game.History[1].Score)
+}
+
+func TestRejectsBadRequests(t *testing.T) {
+	server := newTestServer(t)
+	var errResponse gameserver.ErrorResponse
+
+	assert.Equal(t, http.StatusNotFound, testutil.Request(t, server, http.MethodGet, "/games/nope", nil, &errResponse))
+	assert.Equal(t, http.StatusNotFound, testutil.Request(t, server, http.MethodGet, "/other", nil, &errResponse))
+	assert.Equal(t, http.StatusMethodNotAllowed, testutil.Request(t, server, http.MethodDelete, "/games", nil, &errResponse))
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{}, &errResponse))
+	assert.Equal(t, "a game needs at least one seat", errResponse.Error)
+	status := testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{Seats: []gameserver.SeatRequest{{Bot: "clever"}}}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `seat 0: unknown strategy "clever"`, errResponse.Error)
+	status = testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{Rules: "chess", Seats: []gameserver.SeatRequest{{}}}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status = testutil.Request(t, server, http.MethodPost, "/games", gameserver.CreateRequest{Seats: make([]gameserver.SeatRequest, 3)}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "there are not enough tiles for 3 seats", errResponse.Error)
+
+	info := createGame(t, server, gameserver.SeatRequest{})
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/join", gameserver.JoinRequest{Seat: 1, Name: "Ann"}, &errResponse))
+	assert.Equal(t, http.StatusBadRequest, testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/join", gameserver.JoinRequest{Seat: 0}, &errResponse))
+	assert.Equal(t, "a name is required", errResponse.Error)
+	ann := join(t, server, info.ID, 0, "Ann")
+	status = testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Type: "pass"}, &errResponse)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "spectators cannot take turns", errResponse.Error)
+	status = testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: ann, Type: "resign"}, &errResponse)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status = testutil.Request(t, server, http.MethodPost, "/games/"+info.ID+"/actions", gameserver.Action{Token: ann, Type: "play", Move: "3A AA"}, &errResponse)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, "first move must cover a start square", errResponse.Error)
+}
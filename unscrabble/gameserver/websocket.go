@@ -0,0 +1,98 @@
+package gameserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a message to a WebSocket
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed for the client to answer a ping
+	pongWait = time.Minute
+	// pingPeriod is how often the client is pinged, which must be less than
+	// pongWait
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageBytes limits the size of the actions sent by the client
+	maxMessageBytes = 4096
+)
+
+// serveWebSocket connects a WebSocket to a game. The events of the game are
+// written to it, and the actions read from it are taken for its seat.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request, game *hostedGame) {
+	token := r.URL.Query().Get("token")
+	subscriber, err := game.subscribe(token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already responded
+		game.do(func() error {
+			game.unsubscribe(subscriber)
+			return nil
+		})
+		return
+	}
+	go writeEvents(conn, subscriber.events)
+	readActions(conn, game, token, subscriber)
+}
+
+// writeEvents writes events to conn until they are closed, and then closes
+// conn. The client is pinged so that a lost connection is noticed.
+func writeEvents(conn *websocket.Conn, events <-chan Event) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+	for {
+		select {
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readActions takes the actions read from conn until it is closed. An action
+// that is rejected is answered with an error event.
+func readActions(conn *websocket.Conn, game *hostedGame, token string, subscriber *subscriber) {
+	defer game.do(func() error {
+		game.unsubscribe(subscriber)
+		return nil
+	})
+	conn.SetReadLimit(maxMessageBytes)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		var action Action
+		if err := conn.ReadJSON(&action); err != nil {
+			return
+		}
+		if _, err := game.act(token, action); err != nil {
+			game.do(func() error {
+				if game.subscribers[subscriber] {
+					game.send(subscriber, Event{Type: "error", Seq: game.seq, Error: err.Error()})
+				}
+				return nil
+			})
+		}
+	}
+}
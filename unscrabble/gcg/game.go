@@ -0,0 +1,270 @@
+package gcg
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// Replay plays the record as a game with the rules of config, checking the
+// score and cumulative score of every event. Words are not checked against a
+// lexicon, and challenges are resolved as recorded.
+func Replay(record *Record, config model.Configuration) (*model.Game, error) {
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return nil, err
+	}
+	config.ChallengeRule = challengeRule(record, config.ChallengeRule)
+
+	nicknames := make(map[string]int)
+	var players []*model.Player
+	for i, player := range record.Players {
+		nicknames[player.Nickname] = i
+		players = append(players, model.NewPlayer(player.Nickname, nil))
+	}
+	game, err := model.NewGame(config, nil, players...)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[int]bool)
+	for i, event := range record.Events {
+		player, ok := nicknames[event.Nickname]
+		if !ok {
+			return nil, fmt.Errorf("line %v: unknown player %q", event.Line, event.Nickname)
+		}
+		if err := replayEvent(game, alphabet, record.Events[i+1:], player, event); err != nil {
+			return nil, fmt.Errorf("line %v: %w", event.Line, err)
+		}
+		if err := checkEvent(game.Record(), matched, player, event); err != nil {
+			return nil, fmt.Errorf("line %v: %w", event.Line, err)
+		}
+	}
+	return game, nil
+}
+
+// challengeRule returns a challenge rule that allows the challenges in the
+// record to be replayed
+func challengeRule(record *Record, rule model.ChallengeRule) model.ChallengeRule {
+	for _, event := range record.Events {
+		switch {
+		case event.Type == model.ChallengeBonusTurn && event.Score == 5:
+			return model.FivePointChallenge
+		case event.Type == model.ChallengeBonusTurn && event.Score == 10:
+			return model.TenPointChallenge
+		case event.Type == model.PhonyWithdrawnTurn && (rule == "" || rule == model.VoidChallenge):
+			rule = model.DoubleChallenge
+		}
+	}
+	return rule
+}
+
+func replayEvent(game *model.Game, alphabet *model.Alphabet, remaining []Event, player int, event Event) error {
+	switch event.Type {
+	case model.PhonyWithdrawnTurn:
+		return game.ResolveChallenge(true)
+	case model.ChallengeBonusTurn:
+		return game.ResolveChallenge(false)
+	case model.EndRackBonusTurn, model.EndRackPenaltyTurn:
+		if !game.IsOver() {
+			return game.AcceptPlay()
+		}
+		return nil
+	}
+
+	if game.CurrentPlayer() != player {
+		return fmt.Errorf("it is not %v's turn", event.Nickname)
+	}
+	if err := setRack(game, alphabet, player, event.Rack); err != nil {
+		return err
+	}
+	if err := setFinalRacks(game, alphabet, remaining, player); err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case model.PlayTurn:
+		move, err := alphabet.ParseMove(game.Board(), event.Position+" "+event.Play)
+		if err != nil {
+			return err
+		}
+		return game.PlayMove(move)
+	case model.ExchangeTurn:
+		rack, err := alphabet.ParseRack(event.Exchanged, utf8.RuneCountInString(event.Exchanged), nil)
+		if err != nil {
+			return err
+		}
+		exchanged := rack.Letters()
+		if drawn, ok := finalDraw(game, alphabet, remaining, player, event.Nickname, exchanged); ok {
+			return game.ExchangeDrawing(exchanged, drawn)
+		}
+		return game.Exchange(exchanged...)
+	}
+	return game.Pass()
+}
+
+func setRack(game *model.Game, alphabet *model.Alphabet, player int, notation string) error {
+	rack, err := alphabet.ParseRack(notation, utf8.RuneCountInString(notation), nil)
+	if err != nil {
+		return err
+	}
+	return game.SetRack(player, rack.Letters()...)
+}
+
+// setFinalRacks sets the racks settled at the end of the game if the turn
+// being replayed is the last turn, so that the adjustments can be checked
+func setFinalRacks(game *model.Game, alphabet *model.Alphabet, remaining []Event, player int) error {
+	for _, event := range remaining {
+		if event.Type != model.EndRackBonusTurn && event.Type != model.EndRackPenaltyTurn {
+			return nil
+		}
+	}
+	for _, event := range remaining {
+		settled := -1
+		for i, other := range game.Players() {
+			if event.Type == model.EndRackPenaltyTurn && other.Name() == event.Nickname {
+				settled = i
+			}
+			// the opponent's rack can only be known in a two player game
+			if event.Type == model.EndRackBonusTurn && len(game.Players()) == 2 && other.Name() != event.Nickname {
+				settled = i
+			}
+		}
+		if settled == -1 || settled == player {
+			continue
+		}
+		if err := setRack(game, alphabet, settled, event.Rack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalDraw returns the tiles drawn in an exchange that ends the game, which
+// are the tiles of the player's rack settled at the end of the game that were
+// not kept from the exchange
+func finalDraw(
+	game *model.Game,
+	alphabet *model.Alphabet,
+	remaining []Event,
+	player int,
+	nickname string,
+	exchanged []rune,
+) ([]rune, bool) {
+	var finalRack string
+	for _, event := range remaining {
+		switch {
+		case event.Type != model.EndRackBonusTurn && event.Type != model.EndRackPenaltyTurn:
+			return nil, false
+		case event.Type == model.EndRackPenaltyTurn && event.Nickname == nickname:
+			finalRack = event.Rack
+		}
+	}
+	final, err := alphabet.ParseRack(finalRack, utf8.RuneCountInString(finalRack), nil)
+	if finalRack == "" || err != nil {
+		return nil, false
+	}
+
+	counts := final.LetterCounts()
+	kept := game.Players()[player].Rack()
+	for _, letter := range exchanged {
+		if !kept.HasTile(letter) {
+			return nil, false
+		}
+		kept.RemoveLetter(letter)
+	}
+	for letter, count := range kept.LetterCounts() {
+		counts[letter] -= count
+	}
+	var drawn []rune
+	for letter, count := range counts {
+		if count < 0 {
+			return nil, false
+		}
+		for i := 0; i < count; i++ {
+			drawn = append(drawn, letter)
+		}
+	}
+	return drawn, true
+}
+
+// checkEvent checks the event against the first turn of the game record with
+// the same player and type that has not already been checked
+func checkEvent(turns []model.Turn, matched map[int]bool, player int, event Event) error {
+	for i, turn := range turns {
+		if matched[i] || turn.Player != player || turn.Type != event.Type {
+			continue
+		}
+		matched[i] = true
+		if turn.Score != event.Score {
+			return fmt.Errorf("score is %+d but the game scored %+d", event.Score, turn.Score)
+		}
+		if turn.Total != event.Total {
+			return fmt.Errorf("cumulative score is %v but the game has %v", event.Total, turn.Total)
+		}
+		return nil
+	}
+	return fmt.Errorf("the game has no %v for %v", event.Type, event.Nickname)
+}
+
+// FromGame returns the record of a game. The players of the record default to
+// the names of the game's players.
+func FromGame(game *model.Game, alphabet *model.Alphabet, players ...Player) *Record {
+	record := &Record{Players: players}
+	for i := len(players); i < len(game.Players()); i++ {
+		name := game.Players()[i].Name()
+		record.Players = append(record.Players, Player{
+			Nickname: strings.ReplaceAll(name, " ", "_"),
+			Name:     name,
+		})
+	}
+
+	for i, turn := range game.Record() {
+		event := Event{
+			Nickname: record.Players[turn.Player].Nickname,
+			Type:     turn.Type,
+			Rack:     alphabet.FormatRack(&turn.Rack),
+			Score:    turn.Score,
+			Total:    turn.Total,
+		}
+		switch turn.Type {
+		case model.PlayTurn:
+			event.Position = turn.Move.Coordinates()
+			event.Play = formatPlay(game.BoardBefore(i), alphabet, *turn.Move)
+		case model.ExchangeTurn:
+			exchanged := model.NewRack(len(turn.Exchanged))
+			for _, letter := range turn.Exchanged {
+				exchanged.AddLetter(letter)
+			}
+			event.Exchanged = alphabet.FormatRack(exchanged)
+		}
+		record.Events = append(record.Events, event)
+	}
+	return record
+}
+
+// formatPlay returns the word of a move with '.' for the tiles it plays
+// through on the board
+func formatPlay(board model.Board, alphabet *model.Alphabet, move model.Move) string {
+	rowStep, columnStep := 0, 1
+	if !move.Horizontal {
+		rowStep, columnStep = 1, 0
+	}
+	var sb strings.Builder
+	i := 0
+	for _, letter := range move.Word.Chars {
+		tile := board.Tiles[move.StartPosition.Row+i*rowStep][move.StartPosition.Column+i*columnStep]
+		if tile.Empty() {
+			sb.WriteString(alphabet.FormatWord(model.Word{
+				Chars:      string(letter),
+				BlankTiles: move.Word.BlankTiles[i : i+1],
+			}))
+		} else {
+			sb.WriteRune('.')
+		}
+		i++
+	}
+	return sb.String()
+}
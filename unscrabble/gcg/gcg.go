@@ -0,0 +1,200 @@
+// Package gcg reads and writes game records in the GCG format used by
+// crossword game tools. A record has a line for each turn, e.g.
+//
+//	#player1 alice Alice
+//	#player2 bob Bob
+//	>alice: AEINRST 8D RETAINS +66 66
+//	>bob: DEIOOUY -OOU +0 0
+//	>alice: EFGHLOR D8 .EFLOG +24 90
+//
+// Positions are a row number followed by a column letter for horizontal plays
+// and the reverse for vertical plays. Tiles that are played through are
+// written as '.', and blanks are written in lower case.
+package gcg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// Player is a player in a record
+type Player struct {
+	Nickname string // Nickname identifies the player in events, and can not contain spaces
+	Name     string
+}
+
+// Event is a line of a record describing a turn or an end of game adjustment
+type Event struct {
+	Nickname  string
+	Type      model.TurnType
+	Rack      string // Rack is the player's rack, or the rack being settled for end rack adjustments
+	Position  string // Position is the coordinates of a play, e.g. "8D" or "D8"
+	Play      string // Play is the word played, with '.' for tiles played through
+	Exchanged string // Exchanged is the tiles exchanged
+	Score     int
+	Total     int
+	Line      int // Line is the line number of the event in the parsed record
+}
+
+// Record is a game in GCG format
+type Record struct {
+	Players []Player
+	Lexicon string
+	Events  []Event
+}
+
+// Parse reads a record. Pragmas other than the players, lexicon and character
+// encoding, such as notes, are ignored.
+func Parse(r io.Reader) (*Record, error) {
+	record := &Record{}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		var err error
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			err = record.parsePragma(line)
+		case strings.HasPrefix(line, ">"):
+			var event Event
+			event, err = parseEvent(line)
+			event.Line = lineNumber
+			record.Events = append(record.Events, event)
+		default:
+			err = errors.New("line is not a pragma or an event")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (record *Record) parsePragma(line string) error {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "#player1", "#player2":
+		if len(fields) < 2 {
+			return fmt.Errorf("%v has no nickname", fields[0])
+		}
+		player := Player{Nickname: fields[1], Name: strings.Join(fields[2:], " ")}
+		index := int(fields[0][len(fields[0])-1] - '1')
+		for len(record.Players) <= index {
+			record.Players = append(record.Players, Player{})
+		}
+		record.Players[index] = player
+	case "#lexicon":
+		record.Lexicon = strings.Join(fields[1:], " ")
+	case "#character-encoding":
+		if len(fields) != 2 || !strings.EqualFold(fields[1], "UTF-8") {
+			return fmt.Errorf("unsupported character encoding %q", strings.Join(fields[1:], " "))
+		}
+	}
+	return nil
+}
+
+func parseEvent(line string) (Event, error) {
+	colon := strings.Index(line, ":")
+	if colon == -1 {
+		return Event{}, errors.New("event has no nickname")
+	}
+	event := Event{Nickname: line[1:colon]}
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) < 3 {
+		return Event{}, errors.New("event has too few fields")
+	}
+
+	var err error
+	if event.Total, err = strconv.Atoi(fields[len(fields)-1]); err != nil {
+		return Event{}, fmt.Errorf("invalid cumulative score %q", fields[len(fields)-1])
+	}
+	if event.Score, err = strconv.Atoi(fields[len(fields)-2]); err != nil {
+		return Event{}, fmt.Errorf("invalid score %q", fields[len(fields)-2])
+	}
+	fields = fields[:len(fields)-2]
+
+	if len(fields) == 1 && isParenthesised(fields[0]) {
+		if fields[0] == "(challenge)" {
+			event.Type = model.ChallengeBonusTurn
+			return event, nil
+		}
+		event.Type = model.EndRackBonusTurn
+		event.Rack = strings.Trim(fields[0], "()")
+		return event, nil
+	}
+
+	event.Rack = fields[0]
+	switch {
+	case len(fields) == 2 && fields[1] == "-":
+		event.Type = model.PassTurn
+	case len(fields) == 2 && fields[1] == "--":
+		event.Type = model.PhonyWithdrawnTurn
+	case len(fields) == 2 && fields[1] == "(challenge)":
+		event.Type = model.ChallengeBonusTurn
+	case len(fields) == 2 && fields[1] == "(time)":
+		return Event{}, errors.New("time penalties are not supported")
+	case len(fields) == 2 && isParenthesised(fields[1]):
+		event.Type = model.EndRackPenaltyTurn
+		event.Rack = strings.Trim(fields[1], "()")
+	case len(fields) == 2 && strings.HasPrefix(fields[1], "-"):
+		event.Type = model.ExchangeTurn
+		event.Exchanged = fields[1][1:]
+	case len(fields) == 3:
+		event.Type = model.PlayTurn
+		event.Position = fields[1]
+		event.Play = fields[2]
+	default:
+		return Event{}, errors.New("event is not a play, exchange, pass, challenge or adjustment")
+	}
+	return event, nil
+}
+
+func isParenthesised(field string) bool {
+	return strings.HasPrefix(field, "(") && strings.HasSuffix(field, ")")
+}
+
+// Write writes the record in GCG format
+func (record *Record) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#character-encoding UTF-8")
+	for i, player := range record.Players {
+		fmt.Fprintf(bw, "#player%v %v %v\n", i+1, player.Nickname, player.Name)
+	}
+	if record.Lexicon != "" {
+		fmt.Fprintf(bw, "#lexicon %v\n", record.Lexicon)
+	}
+	for _, event := range record.Events {
+		fmt.Fprintf(bw, ">%v: %v\n", event.Nickname, event.format())
+	}
+	return bw.Flush()
+}
+
+func (event Event) format() string {
+	var move string
+	switch event.Type {
+	case model.PlayTurn:
+		move = event.Rack + " " + event.Position + " " + event.Play
+	case model.ExchangeTurn:
+		move = event.Rack + " -" + event.Exchanged
+	case model.PassTurn, model.FailedChallengeTurn:
+		move = event.Rack + " -"
+	case model.PhonyWithdrawnTurn:
+		move = event.Rack + " --"
+	case model.ChallengeBonusTurn:
+		move = event.Rack + " (challenge)"
+	case model.EndRackBonusTurn:
+		move = "(" + event.Rack + ")"
+	case model.EndRackPenaltyTurn:
+		move = event.Rack + " (" + event.Rack + ")"
+	}
+	return fmt.Sprintf("%v %+d %d", move, event.Score, event.Total)
+}
@@ -0,0 +1,136 @@
+package gcg_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/data"
+	"example.com/unscrabble/unscrabble/gcg"
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readRecord(t *testing.T, name string) (string, *gcg.Record) {
+	text, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	record, err := gcg.Parse(bytes.NewReader(text))
+	require.NoError(t, err)
+	return string(text), record
+}
+
+func TestParseAndWriteRoundTripEveryEventType(t *testing.T) {
+	text := `#character-encoding UTF-8
+#player1 alice Alice
+#player2 bob Bob
+>alice: AEINRST 8D RETAINS +66 66
+>bob: DEIOOUY -OOU +0 0
+>alice: EFGHLOR D8 .EFLOg +22 88
+>alice: EFGHLOR -- -22 66
+>bob: ADEINOU - +0 0
+>alice: EFGHLOR (challenge) +5 71
+>bob: ADEINOU (ADEINOU) -7 -7
+>alice: (ADEINOU) +14 85
+`
+	record, err := gcg.Parse(strings.NewReader(text))
+	require.NoError(t, err)
+	require.Len(t, record.Events, 8)
+	assert.Equal(t, gcg.Event{
+		Nickname: "alice",
+		Type:     model.PlayTurn,
+		Rack:     "EFGHLOR",
+		Position: "D8",
+		Play:     ".EFLOg",
+		Score:    22,
+		Total:    88,
+		Line:     6,
+	}, record.Events[2])
+	assert.Equal(t, "OOU", record.Events[1].Exchanged)
+	assert.Equal(t, model.EndRackBonusTurn, record.Events[7].Type)
+	assert.Equal(t, "ADEINOU", record.Events[7].Rack)
+
+	var sb strings.Builder
+	require.NoError(t, record.Write(&sb))
+	assert.Equal(t, text, sb.String())
+}
+
+func TestParseReportsLineOfError(t *testing.T) {
+	_, err := gcg.Parse(strings.NewReader("#player1 alice Alice\n>alice: AEINRST 8D RETAINS +sixty 66\n"))
+	assert.EqualError(t, err, `line 2: invalid score "+sixty"`)
+}
+
+func TestReplayVerifiesScoresAndRoundTrips(t *testing.T) {
+	text, record := readRecord(t, "scoreless.gcg")
+	config, err := data.LoadPreset("scrabble")
+	require.NoError(t, err)
+
+	game, err := gcg.Replay(record, config)
+	require.NoError(t, err)
+	assert.True(t, game.IsOver())
+	assert.Equal(t, -4, game.Players()[1].Score())
+	assert.Equal(t, 'k', game.Board().Tiles[5][8].Letter)
+
+	alphabet, err := config.NewAlphabet()
+	require.NoError(t, err)
+	exported := gcg.FromGame(game, alphabet, record.Players...)
+	exported.Lexicon = record.Lexicon
+
+	var sb strings.Builder
+	require.NoError(t, exported.Write(&sb))
+	assert.Equal(t, text, sb.String())
+}
+
+func TestReplayReportsIncorrectScores(t *testing.T) {
+	_, record := readRecord(t, "scoreless.gcg")
+	record.Events[3].Score = 12
+	config, err := data.LoadPreset("scrabble")
+	require.NoError(t, err)
+
+	_, err = gcg.Replay(record, config)
+	assert.EqualError(t, err, "line 8: score is +12 but the game scored +10")
+}
+
+func TestReplaySettlesRacksWhenPlayerGoesOut(t *testing.T) {
+	config, err := model.ParseConfiguration([]byte(`
+rack_size: 2
+board_size: 3
+letter_scores: {a: 1, b: 3, "?": 0}
+letter_counts: {a: 2, b: 2, "?": 0}
+letter_multipliers: [[1, 1, 1], [1, 1, 1], [1, 1, 1]]
+word_multipliers: [[1, 1, 1], [1, 1, 1], [1, 1, 1]]
+`))
+	require.NoError(t, err)
+	record, err := gcg.Parse(strings.NewReader(`#player1 alice Alice
+#player2 bob Bob
+>alice: AB 2A AB +4 4
+>alice: (AB) +8 12
+`))
+	require.NoError(t, err)
+
+	game, err := gcg.Replay(record, config)
+	require.NoError(t, err)
+	assert.True(t, game.IsOver())
+	assert.Equal(t, 12, game.Players()[0].Score())
+}
+
+func TestReplayDrawsFinalRackWhenGameEndsWithExchange(t *testing.T) {
+	config, err := data.LoadPreset("scrabble")
+	require.NoError(t, err)
+	config.EndGame.MaxScorelessTurns = 1
+	record, err := gcg.Parse(strings.NewReader(`#player1 alice Alice
+#player2 bob Bob
+>alice: AEINRST -AEI +0 0
+>alice: EFGNRST (EFGNRST) -11 -11
+>bob: BCDLMOP (BCDLMOP) -16 -16
+`))
+	require.NoError(t, err)
+
+	game, err := gcg.Replay(record, config)
+	require.NoError(t, err)
+	assert.True(t, game.IsOver())
+	rack := game.Players()[0].Rack()
+	assert.Equal(t, "EFGNRST", rack.String())
+}
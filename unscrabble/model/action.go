@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ActionType is the kind of an Action
+type ActionType string
+
+const (
+	// PlayAction is a call to PlayMove
+	PlayAction ActionType = "play"
+	// ExchangeAction is a call to Exchange
+	ExchangeAction ActionType = "exchange"
+	// PassAction is a call to Pass
+	PassAction ActionType = "pass"
+	// ChallengeAction is a call to Challenge or ResolveChallenge
+	ChallengeAction ActionType = "challenge"
+	// AcceptAction is a call to AcceptPlay
+	AcceptAction ActionType = "accept"
+	// SetRackAction is a call to SetRack
+	SetRackAction ActionType = "set_rack"
+)
+
+// Action is a change made to a game by one of its players. Every successful
+// call that takes a turn, including the turns taken by PlayTurn, is recorded
+// as an action along with the tiles that were drawn from the bag, so that the
+// game can be replayed exactly by applying its actions to a copy of the game
+// as it was before them. Changes of a player's rack by SetRack are recorded
+// too.
+type Action struct {
+	Type       ActionType
+	Player     int    // Player is the index of the player who took the action, or whose rack was set
+	Move       *Move  // Move is the move played by a PlayAction
+	Tiles      []rune // Tiles is the tiles returned to the bag by an ExchangeAction, or put on the rack by a SetRackAction
+	Drawn      []rune // Drawn is the tiles drawn from the bag by a PlayAction, ExchangeAction or SetRackAction
+	Successful bool   // Successful is whether a ChallengeAction withdrew the play
+}
+
+// Actions returns the actions taken in the game since it was created or
+// restored
+func (g *Game) Actions() []Action {
+	return g.actions
+}
+
+// Apply takes an action recorded in another game, drawing the same tiles from
+// the bag. The action must be for the current player, unless it sets a rack.
+func (g *Game) Apply(action Action) error {
+	if action.Type == SetRackAction {
+		return g.SetRackDrawing(action.Player, action.Tiles, action.Drawn)
+	}
+	if action.Player != g.currentPlayer {
+		return fmt.Errorf("the action is for player %v but it is player %v's turn", action.Player, g.currentPlayer)
+	}
+	switch action.Type {
+	case PlayAction:
+		if action.Move == nil {
+			return errors.New("play does not have a move")
+		}
+		return g.PlayMoveDrawing(*action.Move, action.Drawn)
+	case ExchangeAction:
+		return g.ExchangeDrawing(action.Tiles, action.Drawn)
+	case PassAction:
+		return g.Pass()
+	case ChallengeAction:
+		return g.ResolveChallenge(action.Successful)
+	case AcceptAction:
+		return g.AcceptPlay()
+	}
+	return fmt.Errorf("unknown action %q", action.Type)
+}
+
+// recordAction adds an action taken by the current player to the actions of
+// the game
+func (g *Game) recordAction(action Action) {
+	action.Player = g.currentPlayer
+	g.actions = append(g.actions, action)
+}
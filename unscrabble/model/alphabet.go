@@ -0,0 +1,329 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxAlphabetSize is the number of distinct letters, including the blank,
+	// that a Rack or LetterSet can hold. It is one less than the bits of a
+	// LetterSet so that no set of letters is the UnconstrainedLetterSet.
+	maxAlphabetSize = 63
+	// extendedLetterBase is the first of the runes (from the Unicode private use
+	// area) that an Alphabet assigns to tiles which are not a letter from 'a' to
+	// 'z', e.g. the Spanish "ch" and "ñ" tiles.
+	extendedLetterBase = '\uE000'
+	// firstExtendedIndex is the index of extendedLetterBase
+	firstExtendedIndex = 27
+	// maxExtendedLetters is the number of tiles in an alphabet that can be
+	// assigned an extended letter.
+	maxExtendedLetters = maxAlphabetSize - firstExtendedIndex
+)
+
+// letterIndex maps a letter to a compact index which is used by racks and
+// letter sets. The blank has index 0, the letters 'a' to 'z' have indices 1 to
+// 26, and extended letters follow them.
+func letterIndex(letter rune) (int, bool) {
+	switch {
+	case letter == BlankTile:
+		return 0, true
+	case letter >= 'a' && letter <= 'z':
+		return int(letter-'a') + 1, true
+	case letter >= extendedLetterBase && letter < extendedLetterBase+maxExtendedLetters:
+		return int(letter-extendedLetterBase) + firstExtendedIndex, true
+	}
+	return 0, false
+}
+
+// indexLetter is the inverse of letterIndex
+func indexLetter(index int) rune {
+	switch {
+	case index == 0:
+		return BlankTile
+	case index < firstExtendedIndex:
+		return 'a' + rune(index-1)
+	}
+	return extendedLetterBase + rune(index-firstExtendedIndex)
+}
+
+// EnglishAlphabet is the alphabet of the letters 'a' to 'z'
+var EnglishAlphabet = mustNewAlphabet(strings.Split("abcdefghijklmnopqrstuvwxyz", ""))
+
+// Alphabet maps the symbols written on a language's tiles (e.g. "a", "ñ" or
+// "ch") to the letters used to represent the tiles in racks, on the board and
+// in the lexicon. Each tile is represented by a single rune, so a word made of
+// multi-character tiles is still one rune per tile. Symbols which are a single
+// letter from 'a' to 'z' are their own letter, all other symbols are assigned
+// an extended letter. This keeps the mapping from letters to the compact
+// indices used by racks and letter sets independent of the alphabet.
+type Alphabet struct {
+	letters         []rune
+	symbols         map[rune]string
+	symbolLetters   map[string]rune
+	maxSymbolLength int
+}
+
+// NewAlphabet creates an Alphabet from the symbols of its tiles, excluding the
+// blank. Symbols are case-insensitive.
+func NewAlphabet(symbols []string) (*Alphabet, error) {
+	alphabet := &Alphabet{
+		symbols:       make(map[rune]string, len(symbols)),
+		symbolLetters: make(map[string]rune, len(symbols)),
+	}
+	nextExtendedLetter := rune(extendedLetterBase)
+
+	for _, symbol := range symbols {
+		symbol = strings.ToLower(symbol)
+		if symbol == "" {
+			return nil, errors.New("alphabet contains an empty symbol")
+		}
+		if strings.ContainsAny(symbol, "?*[]") {
+			return nil, fmt.Errorf("alphabet symbol %q contains a reserved character", symbol)
+		}
+		if _, ok := alphabet.symbolLetters[symbol]; ok {
+			return nil, fmt.Errorf("alphabet contains %q more than once", symbol)
+		}
+
+		letter, size := utf8.DecodeRuneInString(symbol)
+		if size != len(symbol) || letter < 'a' || letter > 'z' {
+			if nextExtendedLetter == extendedLetterBase+maxExtendedLetters {
+				return nil, fmt.Errorf(
+					"alphabet has more than %v symbols outside of 'a' to 'z'", maxExtendedLetters,
+				)
+			}
+			letter = nextExtendedLetter
+			nextExtendedLetter++
+		}
+
+		alphabet.letters = append(alphabet.letters, letter)
+		alphabet.symbols[letter] = symbol
+		alphabet.symbolLetters[symbol] = letter
+		if length := utf8.RuneCountInString(symbol); length > alphabet.maxSymbolLength {
+			alphabet.maxSymbolLength = length
+		}
+	}
+	return alphabet, nil
+}
+
+func mustNewAlphabet(symbols []string) *Alphabet {
+	alphabet, err := NewAlphabet(symbols)
+	if err != nil {
+		panic(err)
+	}
+	return alphabet
+}
+
+// Letters returns the letters of the alphabet in the order of the symbols
+// given to NewAlphabet. The blank is not included.
+func (alphabet *Alphabet) Letters() []rune {
+	return append([]rune(nil), alphabet.letters...)
+}
+
+// Size returns the number of letters in the alphabet, excluding the blank
+func (alphabet *Alphabet) Size() int {
+	return len(alphabet.letters)
+}
+
+// Letter returns the letter for a symbol. "?" and "*" are the blank.
+func (alphabet *Alphabet) Letter(symbol string) (rune, bool) {
+	if symbol == "?" || symbol == string(BlankTile) {
+		return BlankTile, true
+	}
+	letter, ok := alphabet.symbolLetters[strings.ToLower(symbol)]
+	return letter, ok
+}
+
+// Symbol returns the lower case symbol for a letter. The blank is "?".
+func (alphabet *Alphabet) Symbol(letter rune) string {
+	if letter == BlankTile {
+		return "?"
+	}
+	if symbol, ok := alphabet.symbols[letter]; ok {
+		return symbol
+	}
+	return string(letter)
+}
+
+// Contains tells us whether letter is a letter of the alphabet
+func (alphabet *Alphabet) Contains(letter rune) bool {
+	_, ok := alphabet.symbols[letter]
+	return ok
+}
+
+// Tokenise splits text into tiles and returns it as a string of letters, one
+// rune per tile. Text is case-insensitive and is split greedily, so the
+// longest symbol at each position is used (e.g. Spanish "chico" starts with
+// the "ch" tile). A symbol can be written in square brackets, e.g. "[c]hico",
+// to override the greedy split. An error is returned if the text cannot be
+// split into symbols of the alphabet.
+func (alphabet *Alphabet) Tokenise(text string) (string, error) {
+	letters, _, err := alphabet.tokenise(text, false)
+	return string(letters), err
+}
+
+// ParseWord tokenises a word played on the board like Tokenise, where lower
+// case symbols are blanks, e.g. "QuIT" has a blank as the 'u'.
+func (alphabet *Alphabet) ParseWord(text string) (Word, error) {
+	letters, upper, err := alphabet.tokenise(text, false)
+	if err != nil {
+		return Word{}, err
+	}
+	blanks := make([]bool, len(letters))
+	for i := range blanks {
+		blanks[i] = !upper[i]
+	}
+	return Word{Chars: string(letters), BlankTiles: blanks}, nil
+}
+
+// FormatWord returns the symbols of a word in upper case with blanks in lower
+// case. Multi-character symbols are written in square brackets so that the
+// result can be parsed by ParseWord.
+func (alphabet *Alphabet) FormatWord(word Word) string {
+	var sb strings.Builder
+	i := 0
+	for _, letter := range word.Chars {
+		symbol := alphabet.Symbol(letter)
+		if i >= len(word.BlankTiles) || !word.BlankTiles[i] {
+			symbol = strings.ToUpper(symbol)
+		}
+		if utf8.RuneCountInString(symbol) > 1 {
+			symbol = "[" + symbol + "]"
+		}
+		sb.WriteString(symbol)
+		i++
+	}
+	return sb.String()
+}
+
+// tokenise splits text into letters, and tells us whether each letter was
+// written in upper case
+func (alphabet *Alphabet) tokenise(text string, allowBlanks bool) ([]rune, []bool, error) {
+	original := []rune(text)
+	chars := make([]rune, len(original))
+	for i, char := range original {
+		chars[i] = unicode.ToLower(char)
+	}
+	letters := make([]rune, 0, len(chars))
+	upper := make([]bool, 0, len(chars))
+
+	for i := 0; i < len(chars); {
+		if allowBlanks && (chars[i] == '?' || chars[i] == BlankTile) {
+			letters = append(letters, BlankTile)
+			upper = append(upper, false)
+			i++
+			continue
+		}
+
+		if chars[i] == '[' {
+			end := i + 1
+			for end < len(chars) && chars[end] != ']' {
+				end++
+			}
+			if end == len(chars) {
+				return nil, nil, fmt.Errorf("%q has an unclosed '['", text)
+			}
+			letter, ok := alphabet.symbolLetters[string(chars[i+1:end])]
+			if !ok {
+				return nil, nil, fmt.Errorf("%q is not in the alphabet", string(chars[i+1:end]))
+			}
+			letters = append(letters, letter)
+			upper = append(upper, end > i+1 && unicode.IsUpper(original[i+1]))
+			i = end + 1
+			continue
+		}
+
+		matched := false
+		for length := alphabet.maxSymbolLength; length > 0; length-- {
+			if i+length > len(chars) {
+				continue
+			}
+			if letter, ok := alphabet.symbolLetters[string(chars[i:i+length])]; ok {
+				letters = append(letters, letter)
+				upper = append(upper, unicode.IsUpper(original[i]))
+				i += length
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, nil, fmt.Errorf("%q in %q is not in the alphabet", chars[i], text)
+		}
+	}
+	return letters, upper, nil
+}
+
+// Render returns the symbols of a string of letters in lower case, e.g. for
+// printing a word found in the lexicon.
+func (alphabet *Alphabet) Render(letters string) string {
+	var sb strings.Builder
+	for _, letter := range letters {
+		sb.WriteString(alphabet.Symbol(letter))
+	}
+	return sb.String()
+}
+
+// LetterMap converts a map keyed by symbols (such as the letter scores and
+// counts of a Configuration) to a map keyed by letters. "?" and "*" are the
+// blank.
+func (alphabet *Alphabet) LetterMap(symbolMap map[string]int) (map[rune]int, error) {
+	letterMap := make(map[rune]int, len(symbolMap))
+	for symbol, value := range symbolMap {
+		letter, ok := alphabet.Letter(symbol)
+		if !ok {
+			return nil, fmt.Errorf("%q is not in the alphabet", symbol)
+		}
+		letterMap[letter] = value
+	}
+	return letterMap, nil
+}
+
+// ParseRack creates a rack from notation such as "AEINRS?", where '?' or '*'
+// denotes a blank. The notation is tokenised like Tokenise, so "[CH]" can be
+// used for a multi-character tile. If letterCounts is not nil the rack is
+// validated against it, so every letter must be part of the letter
+// distribution and no letter may appear more often than it does in the
+// distribution.
+func (alphabet *Alphabet) ParseRack(notation string, rackSize int, letterCounts map[rune]int) (*Rack, error) {
+	letters, _, err := alphabet.tokenise(notation, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFits(rackSize, len(letters)); err != nil {
+		return nil, err
+	}
+	rack := NewRack(rackSize)
+	for _, letter := range letters {
+		rack.AddLetter(letter)
+	}
+	if err := rack.Validate(letterCounts); err != nil {
+		return nil, err
+	}
+	return rack, nil
+}
+
+// FormatRack returns the rack's tiles as upper case symbols in the order of
+// the alphabet's letters followed by any blanks as '?'. Multi-character symbols are written in
+// square brackets so that the result can be parsed by ParseRack.
+func (alphabet *Alphabet) FormatRack(rack *Rack) string {
+	return alphabet.formatTiles(rack.LetterCounts())
+}
+
+// formatTiles writes counts of tiles in the notation of FormatRack
+func (alphabet *Alphabet) formatTiles(letterCounts map[rune]int) string {
+	var sb strings.Builder
+	for _, letter := range alphabet.letters {
+		symbol := strings.ToUpper(alphabet.symbols[letter])
+		if utf8.RuneCountInString(symbol) > 1 {
+			symbol = "[" + symbol + "]"
+		}
+		for i := 0; i < letterCounts[letter]; i++ {
+			sb.WriteString(symbol)
+		}
+	}
+	sb.WriteString(strings.Repeat("?", letterCounts[BlankTile]))
+	return sb.String()
+}
@@ -6,13 +6,13 @@ import (
 )
 
 type CrossCheckSetGenerator interface {
-	ValidLettersBetweenPrefixAndSuffix(prefix, suffix string) map[rune]bool
+	ValidLettersBetweenPrefixAndSuffix(prefix, suffix string) LetterSet
 }
 
 // Position contains the coordinates of a board Tile
 type Position struct {
-	Row    int // Row is the zero-indexed row number (top row is 0)
-	Column int // Column is the zero-indexed column number (leftmost row is 0)
+	Row    int `yaml:"row" json:"row"`       // Row is the zero-indexed row number (top row is 0)
+	Column int `yaml:"column" json:"column"` // Column is the zero-indexed column number (leftmost row is 0)
 }
 
 func (position *Position) transpose() {
@@ -23,10 +23,10 @@ func (position *Position) transpose() {
 type Tile struct {
 	Letter                 rune // If Letter is 0 the tile is empty
 	WordMultiplier         int
-	LetterMultiplier       int           // If LetterMultiplier is 0 the tile was a blank RackTile
-	CrossCheckSet          map[rune]bool // If CrossCheckSet is nil, any tile can be placed
+	LetterMultiplier       int       // If LetterMultiplier is 0 the tile was a blank RackTile
+	CrossCheckSet          LetterSet // If CrossCheckSet is unconstrained, any tile can be placed
 	CrossScore             int
-	transposeCrossCheckSet map[rune]bool
+	transposeCrossCheckSet LetterSet
 	transposeCrossScore    int
 	IsAnchor               bool
 	BoardPosition          *Position
@@ -41,7 +41,8 @@ func NewTile(y, x, wordMultiplier, letterMultiplier int) *Tile {
 			Row:    y,
 			Column: x,
 		},
-		CrossCheckSet: nil,
+		CrossCheckSet:          UnconstrainedLetterSet,
+		transposeCrossCheckSet: UnconstrainedLetterSet,
 	}
 }
 
@@ -51,15 +52,12 @@ func (tile *Tile) transpose() {
 	tile.CrossScore, tile.transposeCrossScore = tile.transposeCrossScore, tile.CrossScore
 }
 
-// TODO: Review should vertical even be a parameter here?
-// If it should, shouldn't we be bounds checking?
-// If not document why not.
-
-// GetAdjacentTile gets the tile adjacent to tile in board.
+// GetAdjacentTile gets the tile adjacent to tile in board. It returns nil if the adjacent
+// position is not on the board.
 func (tile *Tile) GetAdjacentTile(board Board, vertical, horizontal int) *Tile {
 	currRow := tile.BoardPosition.Row
 	currColumn := tile.BoardPosition.Column
-	if currColumn+horizontal < 0 || currColumn+horizontal >= len(board.Tiles) {
+	if !board.Contains(Position{Row: currRow + vertical, Column: currColumn + horizontal}) {
 		return nil
 	}
 	return board.Tiles[currRow+vertical][currColumn+horizontal]
@@ -70,9 +68,9 @@ func (tile *Tile) GetAdjacentTile(board Board, vertical, horizontal int) *Tile {
 func (tile *Tile) GetAdjacentTileOrSentinel(board Board, vertical, horizontal int) *Tile {
 	currRow := tile.BoardPosition.Row
 	currColumn := tile.BoardPosition.Column
-	if currColumn+horizontal < 0 || currColumn+horizontal >= len(board.Tiles) {
+	if !board.Contains(Position{Row: currRow + vertical, Column: currColumn + horizontal}) {
 		sentinel := &Tile{
-			CrossCheckSet: make(map[rune]bool),
+			CrossCheckSet: EmptyLetterSet,
 			BoardPosition: &Position{
 				Column: currColumn + horizontal,
 				Row:    currRow + vertical,
@@ -101,19 +99,25 @@ func (tile *Tile) SetIsAnchor(isAnchor bool, board Board) error {
 	// the crossCheckSet is set to the placed character to ensure
 	// Lexicon traversals are constrained to the placed character
 	// when considering new moves that pass through this board position.
-	tile.CrossCheckSet = map[rune]bool{tile.Letter: true}
+	tile.CrossCheckSet = NewLetterSet(tile.Letter)
 	tile.transposeCrossCheckSet = tile.CrossCheckSet
 	tile.CrossScore = 0
 	tile.transposeCrossScore = tile.CrossScore
 	return nil
 }
 
-// SetIsAnchor is used for setting the isAnchor propert of a Tile safely
-func (tile *Tile) crossCheck(board Board, letterScores map[rune]int) (map[rune]bool, int) {
+// crossCheck returns the letters that can be placed on the tile given the
+// tiles above and below it, and the score of those tiles
+func (tile *Tile) crossCheck(board Board, letterScores map[rune]int) (LetterSet, int) {
 	suffix, suffixScore := tile.getSuffixBelow(board, letterScores)
 	prefix, prefixScore := tile.getPrefixAbove(board, letterScores)
 	if prefix == "" && suffix == "" {
-		return nil, 0
+		return UnconstrainedLetterSet, 0
+	}
+	if board.crossCheckSetGenerator == nil {
+		// without a lexicon any letter can be placed, but the set must not be
+		// unconstrained as a cross word is still formed and scored
+		return allLetters, prefixScore + suffixScore
 	}
 	crossCheckSet := board.crossCheckSetGenerator.ValidLettersBetweenPrefixAndSuffix(prefix, suffix)
 	return crossCheckSet, prefixScore + suffixScore
@@ -126,10 +130,10 @@ func (tile *Tile) getSuffixBelow(board Board, letterScores map[rune]int) (string
 	y := tile.BoardPosition.Row + 1
 	score := 0
 
-	for ; (y < len(board.Tiles)) && board.Tiles[y][x].Letter != 0; y++ {
+	for ; (y < board.Rows()) && board.Tiles[y][x].Letter != 0; y++ {
 		placedTile := board.Tiles[y][x]
 		sb.WriteRune(placedTile.Letter)
-		score += letterScores[placedTile.Letter]
+		score += letterScores[placedTile.Letter] * placedTile.LetterMultiplier
 	}
 
 	return sb.String(), score
@@ -145,7 +149,7 @@ func (tile *Tile) getPrefixAbove(board Board, letterScores map[rune]int) (string
 	for ; (y >= 0) && board.Tiles[y][x].Letter != 0; y-- {
 		placedTile := board.Tiles[y][x]
 		sb.WriteRune(placedTile.Letter)
-		score += letterScores[placedTile.Letter]
+		score += letterScores[placedTile.Letter] * placedTile.LetterMultiplier
 	}
 
 	return reverse(sb.String()), score
@@ -168,19 +172,74 @@ func (t *Tile) Empty() bool {
 	return t.Letter == 0
 }
 
-// Board is a collection of Tiles
+// IsBlank tells us whether a blank tile has been placed on the tile
+func (t *Tile) IsBlank() bool {
+	return !t.Empty() && t.LetterMultiplier == 0
+}
+
+// place puts letter on the tile. The premiums of the tile are used up, and the
+// tile only allows moves through it that use the placed letter.
+func (t *Tile) place(letter rune, blank bool) {
+	t.Letter = letter
+	t.WordMultiplier = 1
+	t.LetterMultiplier = 1
+	if blank {
+		t.LetterMultiplier = 0
+	}
+	t.IsAnchor = false
+	t.CrossCheckSet = NewLetterSet(letter)
+	t.transposeCrossCheckSet = t.CrossCheckSet
+	t.CrossScore = 0
+	t.transposeCrossScore = 0
+}
+
+// FirstMoveRules describes where the first move of a game can be played
+type FirstMoveRules struct {
+	StartSquares  []Position // StartSquares defaults to the centre of the board
+	Anywhere      bool       // If Anywhere is true the first move need not cover a start square
+	MinWordLength int        // MinWordLength is the minimum length of the first word
+}
+
+// Board is a collection of Tiles arranged in rows and columns. Boards do not
+// need to be square.
 type Board struct {
 	Tiles                  [][]*Tile
 	crossCheckSetGenerator CrossCheckSetGenerator
+	firstMoveRules         FirstMoveRules
 }
 
 // NewBoard returns a new empty board (a 2D slice of Tiles) from 2D slices
-// of word multipliers and letter multipliers.
-func NewBoard(crossCheckSetGenerator CrossCheckSetGenerator, wordMultipliers, letterMultipliers [][]int) Board {
-	boardSize := len(wordMultipliers)
-	tiles := make([][]*Tile, boardSize)
+// of word multipliers and letter multipliers, which must have the same shape.
+// The start squares are the anchors for the first move. If no start squares
+// are provided the tile at the centre of the board is used.
+func NewBoard(
+	crossCheckSetGenerator CrossCheckSetGenerator,
+	wordMultipliers, letterMultipliers [][]int,
+	startSquares ...Position,
+) Board {
+	return NewBoardWithRules(
+		crossCheckSetGenerator,
+		wordMultipliers,
+		letterMultipliers,
+		FirstMoveRules{StartSquares: startSquares},
+	)
+}
+
+// NewBoardWithRules returns a new empty board, like NewBoard, on which the
+// first move must follow firstMoveRules.
+func NewBoardWithRules(
+	crossCheckSetGenerator CrossCheckSetGenerator,
+	wordMultipliers, letterMultipliers [][]int,
+	firstMoveRules FirstMoveRules,
+) Board {
+	rows := len(wordMultipliers)
+	columns := 0
+	if rows > 0 {
+		columns = len(wordMultipliers[0])
+	}
+	tiles := make([][]*Tile, rows)
 	for y := range tiles {
-		tiles[y] = make([]*Tile, boardSize)
+		tiles[y] = make([]*Tile, columns)
 		for x := range tiles[y] {
 			tiles[y][x] = NewTile(
 				y,
@@ -193,33 +252,170 @@ func NewBoard(crossCheckSetGenerator CrossCheckSetGenerator, wordMultipliers, le
 	board := Board{
 		Tiles:                  tiles,
 		crossCheckSetGenerator: crossCheckSetGenerator,
+		firstMoveRules:         firstMoveRules,
+	}
+	if len(board.firstMoveRules.StartSquares) == 0 {
+		board.firstMoveRules.StartSquares = []Position{board.Centre()}
+	}
+	board.updateAnchors()
+	return board
+}
+
+// Copy returns a deep copy of the board, which can be changed without changing
+// the original
+func (board Board) Copy() Board {
+	tiles := make([][]*Tile, len(board.Tiles))
+	for y, row := range board.Tiles {
+		tiles[y] = make([]*Tile, len(row))
+		for x, tile := range row {
+			tileCopy := *tile
+			position := *tile.BoardPosition
+			tileCopy.BoardPosition = &position
+			tiles[y][x] = &tileCopy
+		}
 	}
-	board.Tiles[boardSize/2][boardSize/2].IsAnchor = true
+	board.Tiles = tiles
 	return board
 }
 
-// Transpose transposes the tiles of the board.
-// This is achieved using an in-place transformation.
-// This works on the assumption that the board is square.
-func Transpose(board Board) {
-	for y := range board.Tiles {
-		for x := y + 1; x < len(board.Tiles); x++ {
-			board.Tiles[y][x].transpose()
-			board.Tiles[x][y].transpose()
-			board.Tiles[y][x], board.Tiles[x][y] = board.Tiles[x][y], board.Tiles[y][x]
+// FirstMoveRules returns the rules for the first move on the board
+func (board Board) FirstMoveRules() FirstMoveRules {
+	return board.firstMoveRules
+}
+
+// IsEmpty tells us whether no tiles have been placed on the board
+func (board Board) IsEmpty() bool {
+	for _, row := range board.Tiles {
+		for _, tile := range row {
+			if !tile.Empty() {
+				return false
+			}
 		}
 	}
-	// Transpose also flips the cross sets so we need to do the diagonal too
-	for y := range board.Tiles {
-		board.Tiles[y][y].transpose()
+	return true
+}
+
+// MinWordLength returns the minimum length of the word of the next move
+func (board Board) MinWordLength() int {
+	if board.IsEmpty() {
+		return board.firstMoveRules.MinWordLength
+	}
+	return 0
+}
+
+// PlaceMove puts the tiles of move on the board, and updates the anchors and
+// cross-checks of the empty tiles. The move is not validated beyond checking
+// that it fits on the board and agrees with the tiles already placed, see
+// ValidateMove.
+func (board *Board) PlaceMove(move Move, letterScores map[rune]int) error {
+	if err := board.checkFits(move); err != nil {
+		return err
+	}
+	rowStep, columnStep := move.steps()
+	i := 0
+	for _, letter := range move.Word.Chars {
+		tile := board.Tiles[move.StartPosition.Row+i*rowStep][move.StartPosition.Column+i*columnStep]
+		if tile.Empty() {
+			tile.place(letter, move.Word.BlankTiles[i])
+		}
+		i++
+	}
+	board.updateAnchors()
+	board.updateCrossChecks(letterScores)
+	return nil
+}
+
+// updateAnchors marks the tiles from which moves can be generated. On an empty
+// board these are the start squares, or every tile if the first move can be
+// played anywhere.
+func (board Board) updateAnchors() {
+	for _, row := range board.Tiles {
+		for _, tile := range row {
+			tile.IsAnchor = false
+		}
+	}
+	if !board.IsEmpty() {
+		for _, tile := range GetAnchors(board) {
+			tile.IsAnchor = true
+		}
+		return
+	}
+	if board.firstMoveRules.Anywhere {
+		for _, row := range board.Tiles {
+			for _, tile := range row {
+				tile.IsAnchor = true
+			}
+		}
+		return
+	}
+	for _, position := range board.firstMoveRules.StartSquares {
+		board.Tiles[position.Row][position.Column].IsAnchor = true
+	}
+}
+
+// updateCrossChecks recalculates the cross-check sets and cross scores of the
+// empty tiles for both horizontal and vertical moves
+func (board *Board) updateCrossChecks(letterScores map[rune]int) {
+	// the cross-checks for vertical moves are the cross-checks for horizontal
+	// moves on the transposed board, transposing twice restores the board
+	for i := 0; i < 2; i++ {
+		for _, row := range board.Tiles {
+			for _, tile := range row {
+				if tile.Empty() {
+					tile.CrossCheckSet, tile.CrossScore = tile.crossCheck(*board, letterScores)
+				}
+			}
+		}
+		board.Transpose()
+	}
+}
+
+// Rows returns the number of rows on the board
+func (board Board) Rows() int {
+	return len(board.Tiles)
+}
+
+// Columns returns the number of columns on the board
+func (board Board) Columns() int {
+	if len(board.Tiles) == 0 {
+		return 0
+	}
+	return len(board.Tiles[0])
+}
+
+// Centre returns the position of the centre tile. If a dimension has an even
+// length the tile below or to the right of the centre is used.
+func (board Board) Centre() Position {
+	return Position{Row: board.Rows() / 2, Column: board.Columns() / 2}
+}
+
+// Contains tells us whether position is on the board
+func (board Board) Contains(position Position) bool {
+	return position.Row >= 0 && position.Row < board.Rows() &&
+		position.Column >= 0 && position.Column < board.Columns()
+}
+
+// Transpose transposes the tiles of the board, so that rows become columns and
+// columns become rows. The tiles themselves are updated to reflect their new
+// positions, so any other copies of the board are invalid until the board is
+// transposed back.
+func (board *Board) Transpose() {
+	transposed := make([][]*Tile, board.Columns())
+	for x := range transposed {
+		transposed[x] = make([]*Tile, board.Rows())
+		for y := range transposed[x] {
+			transposed[x][y] = board.Tiles[y][x]
+			transposed[x][y].transpose()
+		}
 	}
+	board.Tiles = transposed
 }
 
 // GetAnchors is for finding the anchors of the rows. Anchors are the empty
 // Tiles which are adjacent (horizontally or vertically) to a non-empty
 // Tile.
 func GetAnchors(board Board) []*Tile {
-	anchors := make([]*Tile, 0, len(board.Tiles)*len(board.Tiles))
+	anchors := make([]*Tile, 0, board.Rows()*board.Columns())
 	for y, row := range board.Tiles {
 		for x, tile := range row {
 			if !(tile.Letter == 0) {
@@ -231,7 +427,7 @@ func GetAnchors(board Board) []*Tile {
 				continue
 			}
 
-			if y < (len(board.Tiles)-1) && board.Tiles[y+1][x].Letter != 0 {
+			if y < (board.Rows()-1) && board.Tiles[y+1][x].Letter != 0 {
 				anchors = append(anchors, tile)
 				continue
 			}
@@ -241,7 +437,7 @@ func GetAnchors(board Board) []*Tile {
 				continue
 			}
 
-			if x < (len(board.Tiles)-1) && board.Tiles[y][x+1].Letter != 0 {
+			if x < (board.Columns()-1) && board.Tiles[y][x+1].Letter != 0 {
 				anchors = append(anchors, tile)
 				continue
 			}
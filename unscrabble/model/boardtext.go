@@ -0,0 +1,143 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// premium is the word and letter multipliers of an empty square
+type premium struct {
+	word, letter int
+}
+
+// premiumSymbols are the symbols used for empty squares in the text format of
+// a board. A square can not have both a word and a letter premium.
+var premiumSymbols = map[premium]string{
+	{1, 1}: ".",
+	{1, 2}: "'",
+	{1, 3}: "\"",
+	{1, 4}: "^",
+	{2, 1}: "-",
+	{3, 1}: "=",
+	{4, 1}: "~",
+}
+
+// FormatBoard renders the board as a grid of text, with a header of column
+// letters and the row numbers down the left, e.g.
+//
+//	   A B C D E
+//	1  = . ' . =
+//	2  . - C A T
+//	3  ' . . a .
+//
+// Letters are in upper case, blanks in lower case, and empty squares show
+// their premium: ' and " are double and triple letter, - and = are double and
+// triple word, and ^ and ~ are quadruple letter and word.
+func (alphabet *Alphabet) FormatBoard(board Board) string {
+	labelWidth := len(strconv.Itoa(board.Rows()))
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", labelWidth+1))
+	for x := 0; x < board.Columns(); x++ {
+		sb.WriteString(" " + string(rune('A'+x)))
+	}
+	sb.WriteRune('\n')
+
+	for y, row := range board.Tiles {
+		fmt.Fprintf(&sb, "%*d ", labelWidth, y+1)
+		for _, tile := range row {
+			sb.WriteString(" " + alphabet.formatSquare(tile))
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func (alphabet *Alphabet) formatSquare(tile *Tile) string {
+	if !tile.Empty() {
+		return alphabet.FormatWord(Word{Chars: string(tile.Letter), BlankTiles: []bool{tile.IsBlank()}})
+	}
+	if symbol, ok := premiumSymbols[premium{tile.WordMultiplier, tile.LetterMultiplier}]; ok {
+		return symbol
+	}
+	if tile.WordMultiplier > 1 {
+		return premiumSymbols[premium{tile.WordMultiplier, 1}]
+	}
+	return premiumSymbols[premium{1, tile.LetterMultiplier}]
+}
+
+// ParseBoard parses a board in the format of FormatBoard. The header and row
+// numbers are optional. The anchors and cross-checks of the board are
+// calculated from the tiles on it, and if it is empty the first move must
+// cover the centre square.
+func (alphabet *Alphabet) ParseBoard(
+	text string,
+	crossCheckSetGenerator CrossCheckSetGenerator,
+	letterScores map[rune]int,
+) (Board, error) {
+	symbolPremiums := make(map[string]premium, len(premiumSymbols))
+	for premium, symbol := range premiumSymbols {
+		symbolPremiums[symbol] = premium
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || (len(rows) == 0 && isColumnHeader(fields)) {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[0]); err == nil {
+			fields = fields[1:]
+		}
+		rows = append(rows, fields)
+	}
+	if len(rows) == 0 {
+		return Board{}, fmt.Errorf("board has no rows")
+	}
+
+	wordMultipliers := make([][]int, len(rows))
+	letterMultipliers := make([][]int, len(rows))
+	letters := make([][]Word, len(rows))
+	for y, row := range rows {
+		if len(row) != len(rows[0]) {
+			return Board{}, fmt.Errorf("row %v has %v squares but row 1 has %v", y+1, len(row), len(rows[0]))
+		}
+		wordMultipliers[y] = make([]int, len(row))
+		letterMultipliers[y] = make([]int, len(row))
+		letters[y] = make([]Word, len(row))
+		for x, square := range row {
+			wordMultipliers[y][x], letterMultipliers[y][x] = 1, 1
+			if premium, ok := symbolPremiums[square]; ok {
+				wordMultipliers[y][x], letterMultipliers[y][x] = premium.word, premium.letter
+				continue
+			}
+			word, err := alphabet.ParseWord(square)
+			if err != nil || word.Length() != 1 {
+				return Board{}, fmt.Errorf("row %v column %v: %q is not a tile or premium", y+1, x+1, square)
+			}
+			letters[y][x] = word
+		}
+	}
+
+	board := NewBoard(crossCheckSetGenerator, wordMultipliers, letterMultipliers)
+	for y, row := range letters {
+		for x, word := range row {
+			if word.Length() == 1 {
+				board.Tiles[y][x].place([]rune(word.Chars)[0], word.BlankTiles[0])
+			}
+		}
+	}
+	board.updateAnchors()
+	board.updateCrossChecks(letterScores)
+	return board, nil
+}
+
+// isColumnHeader tells us whether fields are the column letters of a board
+func isColumnHeader(fields []string) bool {
+	for x, field := range fields {
+		if field != string(rune('A'+x)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChallengeRule is how plays that form words which are not in the lexicon
+// (phonies) are handled
+type ChallengeRule string
+
+const (
+	// VoidChallenge rejects phonies when they are played
+	VoidChallenge ChallengeRule = "void"
+	// DoubleChallenge lets phonies be played and challenged by the next
+	// player. A successful challenge withdraws the play, and the challenger
+	// loses their turn if the challenge fails.
+	DoubleChallenge ChallengeRule = "double"
+	// SingleChallenge is like DoubleChallenge, but the challenger is not
+	// penalised if the challenge fails
+	SingleChallenge ChallengeRule = "single"
+	// FivePointChallenge is like SingleChallenge, but the player whose play
+	// was challenged gets five points if the challenge fails
+	FivePointChallenge ChallengeRule = "five_point"
+	// TenPointChallenge is like FivePointChallenge with a ten point bonus
+	TenPointChallenge ChallengeRule = "ten_point"
+)
+
+// ErrPlayAwaitingChallenge is returned when a player takes their turn before
+// deciding whether to challenge a play that went out
+var ErrPlayAwaitingChallenge = errors.New("the last play went out and must be challenged or accepted")
+
+// Challenger is implemented by strategies which can decide whether to
+// challenge their opponent's play
+type Challenger interface {
+	ShouldChallenge(board Board, move Move) bool
+}
+
+// validateChallengeRule returns an error if rule is not a known challenge rule
+func validateChallengeRule(rule ChallengeRule) error {
+	switch rule {
+	case "", VoidChallenge, DoubleChallenge, SingleChallenge, FivePointChallenge, TenPointChallenge:
+		return nil
+	}
+	return fmt.Errorf(
+		"challenge_rule must be one of %q, %q, %q, %q or %q but is %q",
+		VoidChallenge,
+		DoubleChallenge,
+		SingleChallenge,
+		FivePointChallenge,
+		TenPointChallenge,
+		rule,
+	)
+}
+
+// allowsPhonies tells us whether phonies can be played under the rule
+func (rule ChallengeRule) allowsPhonies() bool {
+	return rule != "" && rule != VoidChallenge
+}
+
+// challengeBonus returns the points for a play that is challenged unsuccessfully
+func (rule ChallengeRule) challengeBonus() int {
+	switch rule {
+	case FivePointChallenge:
+		return 5
+	case TenPointChallenge:
+		return 10
+	}
+	return 0
+}
+
+// pendingPlay is the last play of a game whose rules allow phonies, kept
+// until the next player has decided whether to challenge it
+type pendingPlay struct {
+	player         int
+	move           Move
+	phony          bool
+	wentOut        bool
+	boardBefore    Board
+	rackBefore     Rack
+	drawn          []rune
+	scorelessTurns int
+}
+
+// ChallengeablePlay returns the last play if it can be challenged by the
+// current player
+func (g *Game) ChallengeablePlay() (Move, bool) {
+	if g.pending == nil {
+		return Move{}, false
+	}
+	return g.pending.move, true
+}
+
+// Challenge challenges the last play on behalf of the current player. If the
+// play formed a phony it is withdrawn, and the current player takes their
+// turn. Otherwise the challenge rule decides the penalty for the challenger.
+func (g *Game) Challenge() error {
+	if g.over {
+		return ErrGameOver
+	}
+	if g.pending == nil {
+		return errors.New("there is no play to challenge")
+	}
+	if g.lexicon == nil {
+		return errors.New("plays cannot be challenged without a lexicon")
+	}
+	return g.ResolveChallenge(g.pending.phony)
+}
+
+// ResolveChallenge challenges the last play like Challenge, with the outcome
+// of the challenge given rather than decided by the lexicon. This is for
+// replaying the record of a game in which the challenge was adjudicated.
+func (g *Game) ResolveChallenge(successful bool) error {
+	if g.over {
+		return ErrGameOver
+	}
+	if g.pending == nil {
+		return errors.New("there is no play to challenge")
+	}
+	g.recordAction(Action{Type: ChallengeAction, Successful: successful})
+	pending := g.pending
+	g.pending = nil
+	g.notify(GameEvent{Type: PlayChallenged, Player: g.currentPlayer, Move: &pending.move, Successful: successful})
+
+	if successful {
+		g.withdraw(pending)
+		return nil
+	}
+
+	if bonus := g.challengeRule.challengeBonus(); bonus > 0 {
+		g.adjust(Turn{
+			Player: pending.player,
+			Type:   ChallengeBonusTurn,
+			Rack:   g.players[pending.player].rack.Copy(),
+			Score:  bonus,
+		})
+	}
+	if pending.wentOut {
+		g.finish(pending.player)
+		return nil
+	}
+	if g.challengeRule == DoubleChallenge {
+		g.addTurn(Turn{Type: FailedChallengeTurn, Rack: g.players[g.currentPlayer].rack.Copy()})
+		g.scorelessTurn()
+	}
+	return nil
+}
+
+// AcceptPlay accepts the last play without challenging it. Plays are accepted
+// when the next player takes their turn, so this only needs to be called for
+// a play that went out, to end the game.
+func (g *Game) AcceptPlay() error {
+	if g.over {
+		return ErrGameOver
+	}
+	if g.pending == nil {
+		return errors.New("there is no play to accept")
+	}
+	g.recordAction(Action{Type: AcceptAction})
+	pending := g.pending
+	g.pending = nil
+	if pending.wentOut {
+		g.finish(pending.player)
+	}
+	return nil
+}
+
+// checkNotAwaitingChallenge returns ErrPlayAwaitingChallenge if the last play
+// went out and the current player has not decided whether to challenge it.
+// Other plays are accepted when the current player takes their turn.
+func (g *Game) checkNotAwaitingChallenge() error {
+	if g.pending != nil && g.pending.wentOut {
+		return ErrPlayAwaitingChallenge
+	}
+	return nil
+}
+
+// withdraw takes a phony play back off the board, returning the tiles drawn
+// after it to the bag. The withdrawn play counts as a scoreless turn.
+func (g *Game) withdraw(pending *pendingPlay) {
+	player := g.players[pending.player]
+	g.board = pending.boardBefore
+	*player.rack = pending.rackBefore
+	g.letterBag.ReturnLetters(g.random, pending.drawn...)
+
+	score := pending.move.Score
+	g.adjust(Turn{
+		Player: pending.player,
+		Type:   PhonyWithdrawnTurn,
+		Rack:   pending.rackBefore,
+		Move:   &pending.move,
+		Score:  -score,
+	})
+	g.scorelessTurns = pending.scorelessTurns
+	g.scorelessTurns++
+	if g.scorelessTurns >= g.endGameRules.withDefaults().MaxScorelessTurns {
+		g.finish(-1)
+	}
+}
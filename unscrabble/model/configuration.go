@@ -0,0 +1,296 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Configuration contains the rules of a game variant, as loaded from a YAML
+// file such as data/words_with_friends.yaml. Its JSON encoding uses the same
+// field names as the YAML.
+type Configuration struct {
+	Alphabet           []string       `yaml:"alphabet" json:"alphabet,omitempty"`
+	BingoPremium       int            `yaml:"bingo_premium" json:"bingo_premium,omitempty"`
+	RackSize           int            `yaml:"rack_size" json:"rack_size"`
+	BoardSize          int            `yaml:"board_size" json:"board_size,omitempty"`                       // BoardSize is used for square boards
+	BoardRows          int            `yaml:"board_rows" json:"board_rows,omitempty"`                       // BoardRows and BoardColumns are used
+	BoardColumns       int            `yaml:"board_columns" json:"board_columns,omitempty"`                 // for rectangular boards
+	StartSquares       []Position     `yaml:"start_squares" json:"start_squares,omitempty"`                 // StartSquares defaults to the centre
+	FirstMoveAnywhere  bool           `yaml:"first_move_anywhere" json:"first_move_anywhere,omitempty"`     // The first move need not cover a start square
+	MinFirstWordLength int            `yaml:"min_first_word_length" json:"min_first_word_length,omitempty"` // The minimum length of the first word
+	InitialWords       []InitialWord  `yaml:"initial_words" json:"initial_words,omitempty"`                 // Words on the board before the first move
+	EndGame            EndGameRules   `yaml:"end_game" json:"end_game"`
+	ChallengeRule      ChallengeRule  `yaml:"challenge_rule" json:"challenge_rule,omitempty"` // ChallengeRule defaults to VoidChallenge
+	LetterScores       map[string]int `yaml:"letter_scores" json:"letter_scores"`
+	LetterCounts       map[string]int `yaml:"letter_counts" json:"letter_counts"`
+	LetterMultipliers  [][]int        `yaml:"letter_multipliers" json:"letter_multipliers"`
+	WordMultipliers    [][]int        `yaml:"word_multipliers" json:"word_multipliers"`
+}
+
+// InitialWord is a word that is placed on the board before the first move.
+// The word is written using the symbols of the alphabet.
+type InitialWord struct {
+	Position   `yaml:",inline"`
+	Horizontal bool   `yaml:"horizontal" json:"horizontal"`
+	Word       string `yaml:"word" json:"word"`
+}
+
+// ParseConfiguration parses a configuration from YAML
+func ParseConfiguration(configBytes []byte) (Configuration, error) {
+	var config Configuration
+	err := yaml.UnmarshalStrict(configBytes, &config)
+	return config, err
+}
+
+// LoadConfiguration loads a configuration from a YAML file
+func LoadConfiguration(filePath string) (Configuration, error) {
+	configBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return Configuration{}, err
+	}
+	config, err := ParseConfiguration(configBytes)
+	if err != nil {
+		return Configuration{}, fmt.Errorf("%v: %w", filePath, err)
+	}
+	return config, nil
+}
+
+// NewAlphabet returns the Alphabet of the configuration's tiles. If the
+// configuration does not list the symbols of its alphabet, the letters with a
+// score are used in sorted order.
+func (config Configuration) NewAlphabet() (*Alphabet, error) {
+	symbols := config.Alphabet
+	if len(symbols) == 0 {
+		for symbol := range config.LetterScores {
+			if !isBlankSymbol(symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+		sort.Strings(symbols)
+	}
+	return NewAlphabet(symbols)
+}
+
+// Dimensions returns the number of rows and columns of the board
+func (config Configuration) Dimensions() (rows, columns int) {
+	if config.BoardSize != 0 {
+		return config.BoardSize, config.BoardSize
+	}
+	return config.BoardRows, config.BoardColumns
+}
+
+// NewBoard returns a new board for the configuration, with the initial words
+// of the configuration placed on it
+func (config Configuration) NewBoard(crossCheckSetGenerator CrossCheckSetGenerator) (Board, error) {
+	board := NewBoardWithRules(
+		crossCheckSetGenerator,
+		config.WordMultipliers,
+		config.LetterMultipliers,
+		FirstMoveRules{
+			StartSquares:  config.StartSquares,
+			Anywhere:      config.FirstMoveAnywhere,
+			MinWordLength: config.MinFirstWordLength,
+		},
+	)
+	if len(config.InitialWords) == 0 {
+		return board, nil
+	}
+
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return Board{}, err
+	}
+	letterScores, err := alphabet.LetterMap(config.LetterScores)
+	if err != nil {
+		return Board{}, err
+	}
+	for i, initialWord := range config.InitialWords {
+		letters, err := alphabet.Tokenise(initialWord.Word)
+		if err != nil {
+			return Board{}, fmt.Errorf("initial_words %v: %w", i+1, err)
+		}
+		position := initialWord.Position
+		move := Move{
+			StartPosition: &position,
+			Horizontal:    initialWord.Horizontal,
+			Word: Word{
+				Chars:      letters,
+				BlankTiles: make([]bool, utf8.RuneCountInString(letters)),
+			},
+		}
+		if err := board.PlaceMove(move, letterScores); err != nil {
+			return Board{}, fmt.Errorf("initial_words %v: %w", i+1, err)
+		}
+	}
+	return board, nil
+}
+
+func isBlankSymbol(symbol string) bool {
+	return symbol == "?" || symbol == string(BlankTile)
+}
+
+// Validate checks that the configuration describes a playable game and returns
+// an error for each problem found. NewBoard and NewGame assume that the
+// configuration they are given is valid.
+func (config Configuration) Validate() []error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if config.RackSize <= 0 {
+		addError("rack_size must be positive but is %v", config.RackSize)
+	} else if config.RackSize > MaxRackSize {
+		addError("rack_size must be at most %v but is %v", MaxRackSize, config.RackSize)
+	}
+	if config.BingoPremium < 0 {
+		addError("bingo_premium must not be negative but is %v", config.BingoPremium)
+	}
+
+	rows, columns := config.Dimensions()
+	switch {
+	case config.BoardSize != 0 && (config.BoardRows != 0 || config.BoardColumns != 0):
+		addError("board_size must not be used with board_rows and board_columns")
+	case rows <= 0 || columns <= 0:
+		addError(
+			"board_size, or board_rows and board_columns, must be positive but the board is %vx%v",
+			rows,
+			columns,
+		)
+	default:
+		errs = append(errs, validateGridSize("letter_multipliers", config.LetterMultipliers, rows, columns)...)
+		errs = append(errs, validateGridSize("word_multipliers", config.WordMultipliers, rows, columns)...)
+		for _, start := range config.StartSquares {
+			if start.Row < 0 || start.Row >= rows || start.Column < 0 || start.Column >= columns {
+				addError(
+					"start_squares (row %v, column %v) is not on the %vx%v board",
+					start.Row,
+					start.Column,
+					rows,
+					columns,
+				)
+			}
+		}
+	}
+	if config.FirstMoveAnywhere && len(config.StartSquares) > 0 {
+		addError("start_squares must not be used with first_move_anywhere")
+	}
+	if config.MinFirstWordLength < 0 {
+		addError("min_first_word_length must not be negative but is %v", config.MinFirstWordLength)
+	}
+	errs = append(errs, config.EndGame.validate()...)
+	if err := validateChallengeRule(config.ChallengeRule); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateGridsAgree(config.LetterMultipliers, config.WordMultipliers)...)
+
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		addError("alphabet is invalid: %v", err)
+	}
+
+	blankDeclared := false
+	for _, symbol := range sortedSymbols(config.LetterCounts) {
+		count := config.LetterCounts[symbol]
+		if count < 0 {
+			addError("letter_counts has a negative count for %q", symbol)
+		}
+		if isBlankSymbol(symbol) {
+			blankDeclared = true
+			continue
+		}
+		if _, ok := config.LetterScores[symbol]; !ok {
+			addError("letter_counts has %q but letter_scores does not have a score for it", symbol)
+		}
+		if alphabet != nil {
+			if _, ok := alphabet.Letter(symbol); !ok {
+				addError("letter_counts has %q but it is not in the alphabet", symbol)
+			}
+		}
+	}
+	if !blankDeclared {
+		addError("letter_counts does not declare the blank tile (%q)", BlankTile)
+	}
+
+	for _, symbol := range sortedSymbols(config.LetterScores) {
+		if config.LetterScores[symbol] < 0 {
+			addError("letter_scores has a negative score for %q", symbol)
+		}
+		if alphabet != nil && !isBlankSymbol(symbol) {
+			if _, ok := alphabet.Letter(symbol); !ok {
+				addError("letter_scores has %q but it is not in the alphabet", symbol)
+			}
+		}
+	}
+
+	// the initial words can only be placed once the rest of the configuration
+	// is known to be valid
+	if len(errs) == 0 {
+		if _, err := config.NewBoard(nil); err != nil {
+			addError("%v", err)
+		}
+	}
+
+	return errs
+}
+
+func validateGridSize(name string, grid [][]int, rows, columns int) []error {
+	var errs []error
+	if len(grid) != rows {
+		errs = append(errs, fmt.Errorf(
+			"%v has %v rows but the board has %v rows", name, len(grid), rows,
+		))
+	}
+	for y, row := range grid {
+		if len(row) != columns {
+			errs = append(errs, fmt.Errorf(
+				"%v row %v has %v columns but the board has %v columns", name, y+1, len(row), columns,
+			))
+		}
+		for x, multiplier := range row {
+			if multiplier <= 0 {
+				errs = append(errs, fmt.Errorf(
+					"%v row %v column %v must be positive but is %v", name, y+1, x+1, multiplier,
+				))
+			}
+		}
+	}
+	return errs
+}
+
+func validateGridsAgree(letterMultipliers, wordMultipliers [][]int) []error {
+	if len(letterMultipliers) != len(wordMultipliers) {
+		return []error{fmt.Errorf(
+			"letter_multipliers has %v rows but word_multipliers has %v rows",
+			len(letterMultipliers),
+			len(wordMultipliers),
+		)}
+	}
+	var errs []error
+	for y := range letterMultipliers {
+		if len(letterMultipliers[y]) != len(wordMultipliers[y]) {
+			errs = append(errs, fmt.Errorf(
+				"row %v has %v columns in letter_multipliers but %v columns in word_multipliers",
+				y+1,
+				len(letterMultipliers[y]),
+				len(wordMultipliers[y]),
+			))
+		}
+	}
+	return errs
+}
+
+// sortedSymbols returns the keys of a symbol map in sorted order so that
+// errors are reported in a stable order.
+func sortedSymbols(symbolMap map[string]int) []string {
+	symbols := make([]string, 0, len(symbolMap))
+	for symbol := range symbolMap {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,154 @@
+package model
+
+import "fmt"
+
+// DefaultMaxScorelessTurns is the number of consecutive scoreless turns after
+// which a game ends if the rules do not say otherwise
+const DefaultMaxScorelessTurns = 6
+
+// GoingOutBonus is how a player is rewarded for playing all of their tiles
+// once the bag is empty
+type GoingOutBonus string
+
+const (
+	// DoubleRackBonus gives the player twice the value of their opponents'
+	// racks, and their opponents keep their scores. This is the tournament rule.
+	DoubleRackBonus GoingOutBonus = "double"
+	// SingleRackBonus gives the player the value of their opponents' racks,
+	// which is deducted from their opponents' scores. This is the rule printed
+	// in the box.
+	SingleRackBonus GoingOutBonus = "single"
+)
+
+// Tiebreak is how the winner is chosen when the final scores are tied
+type Tiebreak string
+
+const (
+	// TiesAreDraws makes every player with the highest score a winner
+	TiesAreDraws Tiebreak = "draw"
+	// TiesBrokenByScoreBeforeAdjustments chooses the tied player with the
+	// highest score before the end of game rack adjustments
+	TiesBrokenByScoreBeforeAdjustments Tiebreak = "score_before_adjustments"
+)
+
+// EndGameRules describes how a game ends and how the racks left at the end are
+// settled. The zero value uses the tournament rules.
+type EndGameRules struct {
+	MaxScorelessTurns int           `yaml:"max_scoreless_turns" json:"max_scoreless_turns,omitempty"` // Defaults to DefaultMaxScorelessTurns
+	GoingOutBonus     GoingOutBonus `yaml:"going_out_bonus" json:"going_out_bonus,omitempty"`         // Defaults to DoubleRackBonus
+	Tiebreak          Tiebreak      `yaml:"tiebreak" json:"tiebreak,omitempty"`                       // Defaults to TiesAreDraws
+}
+
+// withDefaults returns the rules with any unset rules replaced by their defaults
+func (rules EndGameRules) withDefaults() EndGameRules {
+	if rules.MaxScorelessTurns == 0 {
+		rules.MaxScorelessTurns = DefaultMaxScorelessTurns
+	}
+	if rules.GoingOutBonus == "" {
+		rules.GoingOutBonus = DoubleRackBonus
+	}
+	if rules.Tiebreak == "" {
+		rules.Tiebreak = TiesAreDraws
+	}
+	return rules
+}
+
+// validate returns an error for each invalid rule
+func (rules EndGameRules) validate() []error {
+	var errs []error
+	if rules.MaxScorelessTurns < 0 {
+		errs = append(errs, fmt.Errorf(
+			"end_game max_scoreless_turns must not be negative but is %v",
+			rules.MaxScorelessTurns,
+		))
+	}
+	switch rules.GoingOutBonus {
+	case "", DoubleRackBonus, SingleRackBonus:
+	default:
+		errs = append(errs, fmt.Errorf(
+			"end_game going_out_bonus must be %q or %q but is %q",
+			DoubleRackBonus,
+			SingleRackBonus,
+			rules.GoingOutBonus,
+		))
+	}
+	switch rules.Tiebreak {
+	case "", TiesAreDraws, TiesBrokenByScoreBeforeAdjustments:
+	default:
+		errs = append(errs, fmt.Errorf(
+			"end_game tiebreak must be %q or %q but is %q",
+			TiesAreDraws,
+			TiesBrokenByScoreBeforeAdjustments,
+			rules.Tiebreak,
+		))
+	}
+	return errs
+}
+
+// Adjustments returns the end of game adjustments for the racks left at the
+// end of a game. goneOut is the index of the player who played all of their
+// tiles, or -1 if the game ended after too many scoreless turns, in which
+// case every player loses the value of their own rack.
+func (rules EndGameRules) Adjustments(racks []Rack, goneOut int, letterScores map[rune]int) []Turn {
+	rules = rules.withDefaults()
+	var adjustments []Turn
+	for player, rack := range racks {
+		if player == goneOut {
+			continue
+		}
+		value := rack.Score(letterScores)
+		if goneOut == -1 || rules.GoingOutBonus == SingleRackBonus {
+			adjustments = append(adjustments, Turn{
+				Player: player,
+				Type:   EndRackPenaltyTurn,
+				Rack:   rack,
+				Score:  -value,
+			})
+		}
+		if goneOut == -1 {
+			continue
+		}
+		bonus := value
+		if rules.GoingOutBonus == DoubleRackBonus {
+			bonus *= 2
+		}
+		adjustments = append(adjustments, Turn{
+			Player: goneOut,
+			Type:   EndRackBonusTurn,
+			Rack:   rack,
+			Score:  bonus,
+		})
+	}
+	return adjustments
+}
+
+// Winners returns the indices of the winning players from their final scores,
+// and their scores before the end of game adjustments
+func (rules EndGameRules) Winners(finalScores, scoresBeforeAdjustments []int) []int {
+	rules = rules.withDefaults()
+	winners := highestScoring(finalScores, nil)
+	if len(winners) > 1 && rules.Tiebreak == TiesBrokenByScoreBeforeAdjustments {
+		winners = highestScoring(scoresBeforeAdjustments, winners)
+	}
+	return winners
+}
+
+// highestScoring returns the indices of the highest scores, only considering
+// the indices of candidates if it is not nil
+func highestScoring(scores []int, candidates []int) []int {
+	if candidates == nil {
+		for i := range scores {
+			candidates = append(candidates, i)
+		}
+	}
+	var highest []int
+	for _, i := range candidates {
+		switch {
+		case len(highest) == 0 || scores[i] > scores[highest[0]]:
+			highest = []int{i}
+		case scores[i] == scores[highest[0]]:
+			highest = append(highest, i)
+		}
+	}
+	return highest
+}
@@ -1,160 +1,771 @@
 package model
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"time"
 )
 
+// ErrGameOver is returned when a turn is taken in a game that has ended
+var ErrGameOver = errors.New("game is over")
+
+// MovePicker is the strategy of a player, which picks their moves. A strategy
+// that can be given a deadline is also a ContextMovePicker.
 type MovePicker interface {
 	PickMove(Board, Rack) *Move
 }
 
-type Lexicon interface{}
+// Lexicon is the list of words that can be played. It also generates the
+// cross-check sets of the board.
+type Lexicon interface {
+	CrossCheckSetGenerator
+	Contains(word string) bool
+}
 
 // Player represents a single player in a Game
 type Player struct {
+	name     string
 	rack     *Rack
-	turns    []*Move
 	score    int
 	strategy MovePicker
 }
 
-type Configuration struct {
-	BingoPremium      int            `yaml:"bingo_premium"`
-	RackSize          int            `yaml:"rack_size"`
-	LetterScores      map[string]int `yaml:"letter_scores"`
-	LetterCounts      map[string]int `yaml:"letter_counts"`
-	LetterMultipliers [][]int        `yaml:"letter_multipliers"`
-	WordMultipliers   [][]int        `yaml:"word_multipliers"`
+// NewPlayer returns a new player. The strategy is used to pick the player's
+// moves when the game is played automatically, and can be nil for players
+// whose turns are taken by calling PlayMove, Exchange and Pass.
+func NewPlayer(name string, strategy MovePicker) *Player {
+	return &Player{name: name, strategy: strategy}
+}
+
+// Name returns the name of the player
+func (p *Player) Name() string {
+	return p.name
+}
+
+// Score returns the current score of the player
+func (p *Player) Score() int {
+	return p.score
+}
+
+// Rack returns a copy of the player's rack, which is empty until the player
+// joins a game
+func (p *Player) Rack() Rack {
+	if p.rack == nil {
+		return Rack{}
+	}
+	return p.rack.Copy()
+}
+
+// TurnType is the kind of a Turn in the record of a game
+type TurnType int
+
+const (
+	// PlayTurn is a move which places tiles on the board
+	PlayTurn TurnType = iota
+	// ExchangeTurn swaps tiles on the rack for tiles from the bag
+	ExchangeTurn
+	// PassTurn is a turn in which the player does nothing
+	PassTurn
+	// EndRackBonusTurn is the points a player gains at the end of the game for
+	// the tiles left on an opponent's rack
+	EndRackBonusTurn
+	// EndRackPenaltyTurn is the points a player loses at the end of the game
+	// for the tiles left on their own rack
+	EndRackPenaltyTurn
+	// PhonyWithdrawnTurn takes back a play that was successfully challenged
+	PhonyWithdrawnTurn
+	// ChallengeBonusTurn is the points a player gains when their play is
+	// challenged unsuccessfully
+	ChallengeBonusTurn
+	// FailedChallengeTurn is the turn a player loses by challenging a valid
+	// play under the double challenge rule
+	FailedChallengeTurn
+)
+
+func (turnType TurnType) String() string {
+	switch turnType {
+	case PlayTurn:
+		return "play"
+	case ExchangeTurn:
+		return "exchange"
+	case PassTurn:
+		return "pass"
+	case EndRackBonusTurn:
+		return "end rack bonus"
+	case EndRackPenaltyTurn:
+		return "end rack penalty"
+	case PhonyWithdrawnTurn:
+		return "phony withdrawn"
+	case ChallengeBonusTurn:
+		return "challenge bonus"
+	case FailedChallengeTurn:
+		return "failed challenge"
+	}
+	return fmt.Sprintf("TurnType(%d)", int(turnType))
+}
+
+// Turn is an entry in the record of a game. The end of game adjustments are
+// recorded as turns after the final move.
+type Turn struct {
+	Player    int // Player is the index of the player in the game
+	Type      TurnType
+	Rack      Rack   // Rack is the player's rack before the turn, or the rack being settled for adjustments
+	Move      *Move  // Move is the move played for a PlayTurn
+	Exchanged []rune // Exchanged is the tiles returned to the bag for an ExchangeTurn
+	Score     int    // Score is the points gained in the turn, or lost if negative
+	Total     int    // Total is the player's score after the turn
 }
 
 // Game represents a single game
 type Game struct {
-	letterBag    RandomLetterBag
-	players      []Player
-	board        Board
-	lexicon      Lexicon
-	letterScores map[rune]int
-	bingoPremium int
-}
-
-// NewGame returns a new game using the provided configuration
-func NewGame(
-	numPlayers, bingoPremium, rackSize int,
-	letterScores, letterCounts map[rune]int,
-	letterMultipliers, wordMultipliers [][]int,
-	lexicon Lexicon,
-) (*Game, error) {
-
-	// TODO: make a crosscheckset generator
-	board := NewBoard(nil, wordMultipliers, letterMultipliers)
-	letterBag := NewRandomLetterBag(letterCounts)
-	if (numPlayers * rackSize) < len(letterBag) {
+	config         Configuration
+	alphabet       *Alphabet
+	letterBag      RandomLetterBag
+	random         *rand.Rand
+	players        []*Player
+	board          Board
+	initialBoard   Board
+	lexicon        Lexicon
+	letterScores   map[rune]int
+	bingoPremium   int
+	rackSize       int
+	endGameRules   EndGameRules
+	challengeRule  ChallengeRule
+	pending        *pendingPlay
+	currentPlayer  int
+	scorelessTurns int
+	record         []Turn
+	actions        []Action
+	observers      []Observer
+	over           bool
+	// picking is closed once a strategy that was left picking its move after
+	// the deadline of its turn returns, and is nil if there is none
+	picking chan struct{}
+}
+
+// NewGame returns a new game between players using the rules of config, which
+// must be valid. The lexicon is used to check the words played and can be nil
+// if any word is allowed.
+func NewGame(config Configuration, lexicon Lexicon, players ...*Player) (*Game, error) {
+	return newGame(config, lexicon, rand.New(rand.NewSource(time.Now().UnixNano())), players)
+}
+
+// NewSeededGame returns a new game like NewGame, whose tiles are drawn from
+// the bag in an order decided by seed rather than by the time. Games with the
+// same seed, players and moves draw the same tiles, even when other games are
+// being played at the same time.
+func NewSeededGame(config Configuration, lexicon Lexicon, seed int64, players ...*Player) (*Game, error) {
+	return newGame(config, lexicon, rand.New(rand.NewSource(seed)), players)
+}
+
+// newGame returns a new game whose bag is shuffled by random, which the game
+// keeps for every later shuffle of the bag
+func newGame(config Configuration, lexicon Lexicon, random *rand.Rand, players []*Player) (*Game, error) {
+	if len(players) == 0 {
+		return nil, errors.New("a game needs at least one player")
+	}
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return nil, err
+	}
+	letterScores, err := alphabet.LetterMap(config.LetterScores)
+	if err != nil {
+		return nil, err
+	}
+	letterCounts, err := alphabet.LetterMap(config.LetterCounts)
+	if err != nil {
+		return nil, err
+	}
+
+	var crossCheckSetGenerator CrossCheckSetGenerator
+	if lexicon != nil {
+		crossCheckSetGenerator = lexicon
+	}
+	board, err := config.NewBoard(crossCheckSetGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	letterBag := NewRandomLetterBag(letterCounts, random)
+	if len(players)*config.RackSize > len(letterBag) {
 		return nil, fmt.Errorf(
 			"too many players (%v) for the rackSize (%v) and number of "+
 				"letters in letter bag (%v)",
-			numPlayers,
-			rackSize,
+			len(players),
+			config.RackSize,
 			len(letterBag),
 		)
 	}
 
-	var players []Player
-	for i := 0; i < numPlayers; i++ {
-		players = append(players, Player{rack: NewRack(rackSize)})
-		players[i].rack.Fill(&letterBag)
+	for _, player := range players {
+		player.rack = NewRack(config.RackSize)
+		player.rack.Fill(&letterBag)
+		player.score = 0
 	}
 
 	game := Game{
-		letterBag:    letterBag,
-		players:      players,
-		board:        board,
-		lexicon:      lexicon,
-		letterScores: letterScores,
-		bingoPremium: bingoPremium,
+		config:        config,
+		alphabet:      alphabet,
+		letterBag:     letterBag,
+		random:        random,
+		players:       players,
+		board:         board,
+		initialBoard:  board.Copy(),
+		lexicon:       lexicon,
+		letterScores:  letterScores,
+		bingoPremium:  config.BingoPremium,
+		rackSize:      config.RackSize,
+		endGameRules:  config.EndGame,
+		challengeRule: config.ChallengeRule,
 	}
 	return &game, nil
 }
 
-// Play a game to completition and return the winners
-func (g *Game) Play() (winners []Player) {
-	g.playAllTurns()
-	return g.selectWinners()
+// Board returns the board of the game
+func (g *Game) Board() Board {
+	return g.board
+}
+
+// BoardBefore returns a copy of the board as it was before the turn with the
+// given index in the record
+func (g *Game) BoardBefore(turn int) Board {
+	// the moves were placed when they were played, so they still fit
+	board, _ := g.replayBoard(g.record[:turn])
+	return board
+}
+
+// replayBoard places the plays of turns on a copy of the initial board, and
+// takes back the plays which were withdrawn
+func (g *Game) replayBoard(turns []Turn) (Board, error) {
+	board := g.initialBoard.Copy()
+	var boardBeforeLastPlay Board
+	for i, turn := range turns {
+		switch turn.Type {
+		case PlayTurn:
+			boardBeforeLastPlay = board.Copy()
+			if err := board.PlaceMove(*turn.Move, g.letterScores); err != nil {
+				return board, fmt.Errorf("turn %v: %w", i+1, err)
+			}
+		case PhonyWithdrawnTurn:
+			if boardBeforeLastPlay.Tiles == nil {
+				return board, fmt.Errorf("turn %v: there is no play to withdraw", i+1)
+			}
+			board = boardBeforeLastPlay
+		}
+	}
+	return board, nil
+}
+
+// Players returns the players of the game in turn order
+func (g *Game) Players() []*Player {
+	return g.players
+}
+
+// CurrentPlayer returns the index of the player whose turn it is
+func (g *Game) CurrentPlayer() int {
+	return g.currentPlayer
 }
 
-func (g *Game) selectWinners() []Player {
+// TilesInBag returns the number of tiles left in the bag
+func (g *Game) TilesInBag() int {
+	return len(g.letterBag)
+}
+
+// Record returns the turns taken so far, including the end of game
+// adjustments once the game is over
+func (g *Game) Record() []Turn {
+	return g.record
+}
+
+// IsOver tells us whether the game has ended
+func (g *Game) IsOver() bool {
+	return g.over
+}
 
-	tiebreakWinnerScore := 0
-	for _, player := range g.players {
-		if player.score > tiebreakWinnerScore {
-			tiebreakWinnerScore = player.score
+// Play plays the game to completion using the strategies of the players and
+// returns the winners
+func (g *Game) Play() ([]*Player, error) {
+	return g.PlayContext(context.Background(), 0)
+}
+
+// PlayContext plays the game like Play, giving each strategy turnTime to pick
+// its move, or as long as it takes if turnTime is 0. ctx's error is returned
+// if it is done before the game is over.
+func (g *Game) PlayContext(ctx context.Context, turnTime time.Duration) ([]*Player, error) {
+	for !g.over {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		if turnTime > 0 {
+			turnCtx, cancel := context.WithTimeout(ctx, turnTime)
+			err = g.PlayTurnContext(turnCtx)
+			cancel()
+		} else {
+			err = g.PlayTurnContext(ctx)
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
+	return g.Winners(), nil
+}
 
-	var tiebreakWinners []Player
-	for _, player := range g.players {
-		if player.score == tiebreakWinnerScore {
-			tiebreakWinners = append(tiebreakWinners, player)
+// PlayTurn takes the current player's turn using their strategy. If the
+// strategy does not find a move the player exchanges their whole rack, or
+// passes if there are too few tiles in the bag to exchange.
+func (g *Game) PlayTurn() error {
+	return g.PlayTurnContext(context.Background())
+}
+
+// PlayTurnContext takes the current player's turn like PlayTurn, with ctx
+// passed to a strategy which is a ContextMovePicker. A move picked after the
+// deadline of ctx, and its DeadlineGrace, is not played. If ctx is cancelled
+// the turn is not taken and ctx's error is returned.
+func (g *Game) PlayTurnContext(ctx context.Context) error {
+	if err := ctx.Err(); err == context.Canceled {
+		return err
+	}
+	if g.over {
+		return ErrGameOver
+	}
+	player := g.players[g.currentPlayer]
+	if player.strategy == nil {
+		return fmt.Errorf("player %v does not have a strategy", player.name)
+	}
+	if g.pending != nil {
+		challenger, ok := player.strategy.(Challenger)
+		if ok && g.lexicon != nil && challenger.ShouldChallenge(g.board, g.pending.move) {
+			current := g.currentPlayer
+			if err := g.Challenge(); err != nil {
+				return err
+			}
+			if g.over || g.currentPlayer != current {
+				return nil
+			}
 		}
+		if g.pending != nil && g.pending.wentOut {
+			return g.AcceptPlay()
+		}
+	}
+	move, err := g.pickMove(ctx, player)
+	if err != nil {
+		return err
 	}
+	if move != nil {
+		g.notify(GameEvent{Type: MoveChosen, Player: g.currentPlayer, Move: move})
+		return g.PlayMove(*move)
+	}
+	if g.CanExchange() && player.rack.TileCount() > 0 {
+		return g.Exchange(player.rack.Letters()...)
+	}
+	return g.Pass()
+}
 
-	winnerScore := 0
-	for _, player := range g.players {
+// PlayMove plays move for the current player. The move is scored by the game,
+// and an error is returned if the move is not valid. If the challenge rule
+// allows phonies, moves forming words that are not in the lexicon can be
+// played and are then open to challenge by the next player.
+func (g *Game) PlayMove(move Move) error {
+	return g.playMove(move, func(rack *Rack) []rune {
+		return rack.Fill(&g.letterBag)
+	})
+}
 
-		for letter, count := range player.rack.letterCounts {
-			player.score -= g.letterScores[letter] * count
+// PlayMoveDrawing plays move like PlayMove, with the player drawing the drawn
+// tiles from the bag rather than random tiles, e.g. for replaying a recorded
+// game in which the tiles drawn are known. The drawn tiles must fill the rack,
+// or empty the bag if it has too few tiles to fill the rack.
+func (g *Game) PlayMoveDrawing(move Move, drawn []rune) error {
+	if g.over {
+		return ErrGameOver
+	}
+	if err := g.board.checkFits(move); err != nil {
+		return err
+	}
+	rack := g.players[g.currentPlayer].rack
+	space := rack.Capacity() - rack.TileCount() + len(g.board.TilesPlaced(move))
+	if space > len(g.letterBag) {
+		space = len(g.letterBag)
+	}
+	if len(drawn) != space {
+		return fmt.Errorf("the play draws %v tiles but %v were given", space, len(drawn))
+	}
+	remaining := g.letterBag.LetterCounts()
+	for _, letter := range drawn {
+		if remaining[letter] == 0 {
+			return fmt.Errorf("bag does not contain enough %q tiles", letter)
+		}
+		remaining[letter]--
+	}
+	return g.playMove(move, func(rack *Rack) []rune {
+		g.letterBag.DrawLetters(g.random, drawn...)
+		for _, letter := range drawn {
+			rack.AddLetter(letter)
 		}
+		return drawn
+	})
+}
 
-		if player.rack.tileCount == 0 {
-			for _, otherPlayer := range g.players {
-				for letter, count := range otherPlayer.rack.letterCounts {
-					player.score += g.letterScores[letter] * count
-				}
-			}
+// playMove plays move for the current player, who then draws tiles onto their
+// rack with draw
+func (g *Game) playMove(move Move, draw func(rack *Rack) []rune) error {
+	if g.over {
+		return ErrGameOver
+	}
+	if err := g.checkNotAwaitingChallenge(); err != nil {
+		return err
+	}
+	player := g.players[g.currentPlayer]
+	err := g.board.ValidateMove(move, player.rack, g.lexicon)
+	var phonyErr *PhonyError
+	phony := errors.As(err, &phonyErr)
+	if err != nil && !(phony && g.challengeRule.allowsPhonies()) {
+		return err
+	}
+	score, err := move.CalculateScore(g.board, g.letterScores, g.rackSize, g.bingoPremium)
+	if err != nil {
+		return err
+	}
+	move.Score = score
+
+	rack := player.rack.Copy()
+	var boardBefore Board
+	if g.challengeRule.allowsPhonies() {
+		boardBefore = g.board.Copy()
+	}
+	placed := g.board.TilesPlaced(move)
+	if err := g.board.PlaceMove(move, g.letterScores); err != nil {
+		return err
+	}
+	for _, letter := range placed {
+		player.rack.RemoveLetter(letter)
+	}
+	drawn := draw(player.rack)
+	g.recordAction(Action{Type: PlayAction, Move: &move, Drawn: drawn})
+
+	scorelessTurns := g.scorelessTurns
+	g.scorelessTurns = 0
+	g.addTurn(Turn{Type: PlayTurn, Rack: rack, Move: &move, Score: score})
+	g.notify(GameEvent{Type: MoveScored, Player: g.currentPlayer, Move: &move, Score: score})
+	if len(drawn) > 0 {
+		g.notify(GameEvent{Type: RackDrawn, Player: g.currentPlayer, Tiles: drawn})
+	}
+	wentOut := player.rack.TileCount() == 0
+	if g.challengeRule.allowsPhonies() {
+		g.pending = &pendingPlay{
+			player:         g.currentPlayer,
+			move:           move,
+			phony:          phony,
+			wentOut:        wentOut,
+			boardBefore:    boardBefore,
+			rackBefore:     rack,
+			drawn:          drawn,
+			scorelessTurns: scorelessTurns,
+		}
+	} else if wentOut {
+		g.finish(g.currentPlayer)
+		return nil
+	}
+	g.nextPlayer()
+	return nil
+}
+
+// CanExchange tells us whether there are enough tiles in the bag for the
+// current player to exchange tiles. Exchanges need at least a full rack of
+// tiles in the bag.
+func (g *Game) CanExchange() bool {
+	return len(g.letterBag) >= g.rackSize
+}
+
+// Exchange swaps letters on the current player's rack for tiles from the bag
+func (g *Game) Exchange(letters ...rune) error {
+	return g.exchange(letters, func() ([]rune, error) {
+		return g.letterBag.Exchange(g.random, letters...)
+	})
+}
+
+// ExchangeDrawing swaps letters on the current player's rack for the drawn
+// tiles from the bag rather than random tiles, e.g. for replaying a recorded
+// game in which the tiles drawn are known
+func (g *Game) ExchangeDrawing(letters, drawn []rune) error {
+	if len(drawn) != len(letters) {
+		return fmt.Errorf("cannot draw %v tiles in exchange for %v tiles", len(drawn), len(letters))
+	}
+	return g.exchange(letters, func() ([]rune, error) {
+		if err := g.letterBag.DrawLetters(g.random, drawn...); err != nil {
+			return nil, err
 		}
+		g.letterBag.ReturnLetters(g.random, letters...)
+		return drawn, nil
+	})
+}
 
-		if player.score > winnerScore {
-			winnerScore = player.score
+// exchange swaps letters on the current player's rack for the tiles returned
+// by draw, which takes the tiles from the bag and returns letters to it
+func (g *Game) exchange(letters []rune, draw func() ([]rune, error)) error {
+	if g.over {
+		return ErrGameOver
+	}
+	if err := g.checkNotAwaitingChallenge(); err != nil {
+		return err
+	}
+	if len(letters) == 0 {
+		return errors.New("no tiles to exchange")
+	}
+	if !g.CanExchange() {
+		return fmt.Errorf("cannot exchange with fewer than %v tiles in the bag", g.rackSize)
+	}
+	player := g.players[g.currentPlayer]
+	remaining := player.rack.Copy()
+	for _, letter := range letters {
+		if !remaining.HasTile(letter) {
+			return fmt.Errorf("rack %v does not have the tiles to exchange", player.rack)
 		}
+		remaining.RemoveLetter(letter)
 	}
 
-	var winners []Player
-	for _, player := range g.players {
-		if player.score == winnerScore {
-			winners = append(winners, player)
+	rack := player.rack.Copy()
+	drawn, err := draw()
+	if err != nil {
+		return err
+	}
+	*player.rack = remaining
+	for _, letter := range drawn {
+		player.rack.AddLetter(letter)
+	}
+	g.recordAction(Action{Type: ExchangeAction, Tiles: letters, Drawn: drawn})
+	g.addTurn(Turn{Type: ExchangeTurn, Rack: rack, Exchanged: letters})
+	g.notify(GameEvent{Type: TilesExchanged, Player: g.currentPlayer, Tiles: letters})
+	g.notify(GameEvent{Type: RackDrawn, Player: g.currentPlayer, Tiles: drawn})
+	g.scorelessTurn()
+	return nil
+}
+
+// Pass passes the current player's turn
+func (g *Game) Pass() error {
+	if g.over {
+		return ErrGameOver
+	}
+	if err := g.checkNotAwaitingChallenge(); err != nil {
+		return err
+	}
+	g.recordAction(Action{Type: PassAction})
+	g.addTurn(Turn{Type: PassTurn, Rack: g.players[g.currentPlayer].rack.Copy()})
+	g.notify(GameEvent{Type: TurnPassed, Player: g.currentPlayer})
+	g.scorelessTurn()
+	return nil
+}
+
+// SetRack replaces the tiles on a player's rack with letters, e.g. for
+// replaying a recorded game or setting up a position for analysis. The
+// player's tiles are returned to the bag and the letters are drawn from it.
+// Letters which are not in the bag are taken from the other players' racks,
+// which are each given a tile from the bag in exchange while it has any. The
+// change is recorded as an action, so that it is replayed exactly.
+func (g *Game) SetRack(player int, letters ...rune) error {
+	change, err := g.newRackChange(player, letters)
+	if err != nil {
+		return err
+	}
+	g.setRack(change, func(count int) []rune {
+		var drawn []rune
+		for i := 0; i < count; i++ {
+			letter, _ := g.letterBag.GetLetter()
+			drawn = append(drawn, letter)
+		}
+		return drawn
+	})
+	return nil
+}
+
+// SetRackDrawing is like SetRack, but the other players are given the tiles
+// drawn in exchange for the tiles taken from their racks rather than random
+// tiles from the bag
+func (g *Game) SetRackDrawing(player int, letters, drawn []rune) error {
+	change, err := g.newRackChange(player, letters)
+	if err != nil {
+		return err
+	}
+	if len(drawn) != change.exchanged {
+		return fmt.Errorf("the rack exchanges %v tiles but %v were given", change.exchanged, len(drawn))
+	}
+	for _, letter := range drawn {
+		if change.bagCounts[letter] == 0 {
+			return fmt.Errorf("bag does not contain enough %q tiles", letter)
 		}
+		change.bagCounts[letter]--
 	}
+	g.setRack(change, func(int) []rune {
+		g.letterBag.DrawLetters(g.random, drawn...)
+		return drawn
+	})
+	return nil
+}
 
-	if len(winners) == 1 {
-		return winners
+// rackChange is a change of a player's rack by SetRack
+type rackChange struct {
+	player  int
+	letters []rune
+	// fromBag are the letters that are taken from the bag, once the player's
+	// tiles are returned to it, and fromPlayers the letters that are taken
+	// from the other players' racks
+	fromBag, fromPlayers []rune
+	// bagCounts are the tiles left in the bag once fromBag are taken from it,
+	// of which the other players are given exchanged tiles
+	bagCounts map[rune]int
+	exchanged int
+}
+
+// newRackChange checks that a player's rack can be set to letters and works
+// out where the letters are taken from
+func (g *Game) newRackChange(player int, letters []rune) (rackChange, error) {
+	if player < 0 || player >= len(g.players) {
+		return rackChange{}, fmt.Errorf("there is no player %v", player)
+	}
+	rack := g.players[player].rack
+	if len(letters) > rack.Capacity() {
+		return rackChange{}, fmt.Errorf("cannot put %v tiles on a rack of %v tiles", len(letters), rack.Capacity())
+	}
+	unseen := g.letterBag.LetterCounts()
+	for _, p := range g.players {
+		for letter, count := range p.rack.LetterCounts() {
+			unseen[letter] += count
+		}
+	}
+	for _, letter := range letters {
+		if unseen[letter] == 0 {
+			return rackChange{}, fmt.Errorf("there are not enough %q tiles for the rack", letter)
+		}
+		unseen[letter]--
 	}
 
-	return tiebreakWinners
+	// take the letters that are in the bag first, so that there are tiles
+	// left in the bag to give to the other players
+	change := rackChange{player: player, letters: letters, bagCounts: g.letterBag.LetterCounts()}
+	for letter, count := range rack.LetterCounts() {
+		change.bagCounts[letter] += count
+	}
+	for _, letter := range letters {
+		if change.bagCounts[letter] > 0 {
+			change.bagCounts[letter]--
+			change.fromBag = append(change.fromBag, letter)
+		} else {
+			change.fromPlayers = append(change.fromPlayers, letter)
+		}
+	}
+	change.exchanged = len(change.fromPlayers)
+	if left := len(g.letterBag) + rack.TileCount() - len(change.fromBag); change.exchanged > left {
+		change.exchanged = left
+	}
+	return change, nil
 }
 
-func (g *Game) playAllTurns() {
-	for anyPlayerHasMove := true; anyPlayerHasMove; anyPlayerHasMove = false {
-		for _, player := range g.players {
-			move := player.SelectMove(g)
-			g.PerformMove(&player, move)
-			if move == nil {
-				player.ReplaceRack(g.letterBag)
-				continue
-			}
-			anyPlayerHasMove = true
+// setRack makes a change of a player's rack and records it. draw takes the
+// tiles the other players are given in exchange from the bag.
+func (g *Game) setRack(change rackChange, draw func(count int) []rune) {
+	rack := g.players[change.player].rack
+	g.letterBag.ReturnLetters(g.random, rack.Letters()...)
+	*rack = *NewRack(rack.Capacity())
+	g.letterBag.DrawLetters(g.random, change.fromBag...)
+	for _, letter := range change.fromBag {
+		rack.AddLetter(letter)
+	}
 
-			player.ReplaceRack(g.letterBag)
-			if player.rack.tileCount == 0 {
-				return
+	exchanged := draw(change.exchanged)
+	for i, letter := range change.fromPlayers {
+		for j, other := range g.players {
+			if j != change.player && other.rack.HasTile(letter) {
+				other.rack.RemoveLetter(letter)
+				if i < len(exchanged) {
+					other.rack.AddLetter(exchanged[i])
+				}
+				break
 			}
 		}
+		rack.AddLetter(letter)
 	}
-	return
+
+	// the action is for the player whose rack is set, who need not be the
+	// current player
+	g.actions = append(g.actions, Action{
+		Type:   SetRackAction,
+		Player: change.player,
+		Tiles:  append([]rune(nil), change.letters...),
+		Drawn:  exchanged,
+	})
 }
 
-func (g *Game) PerformMove(player *Player, move *Move) {}
+// Winners returns the players with the highest scores once the game is over,
+// with ties settled by the end game rules
+func (g *Game) Winners() []*Player {
+	if !g.over {
+		return nil
+	}
+	finalScores := make([]int, len(g.players))
+	scoresBeforeAdjustments := make([]int, len(g.players))
+	for i, player := range g.players {
+		finalScores[i] = player.score
+		scoresBeforeAdjustments[i] = player.score
+	}
+	for _, turn := range g.record {
+		if turn.Type == EndRackBonusTurn || turn.Type == EndRackPenaltyTurn {
+			scoresBeforeAdjustments[turn.Player] -= turn.Score
+		}
+	}
+
+	var winners []*Player
+	for _, i := range g.endGameRules.Winners(finalScores, scoresBeforeAdjustments) {
+		winners = append(winners, g.players[i])
+	}
+	return winners
+}
 
-func (p *Player) SelectMove(game *Game) *Move {
-	return nil
+// addTurn adds the score of turn to the current player's score, and adds the
+// turn to the record. Taking a turn accepts the last play, so it can no longer
+// be challenged.
+func (g *Game) addTurn(turn Turn) {
+	g.pending = nil
+	turn.Player = g.currentPlayer
+	g.adjust(turn)
 }
 
-func (p *Player) ReplaceRack(letterbag RandomLetterBag) {}
+// adjust adds the score of turn to the score of the player it is for, and adds
+// the turn to the record
+func (g *Game) adjust(turn Turn) {
+	player := g.players[turn.Player]
+	player.score += turn.Score
+	turn.Total = player.score
+	g.record = append(g.record, turn)
+}
+
+// scorelessTurn ends the game if there have been too many consecutive
+// scoreless turns, and otherwise moves on to the next player
+func (g *Game) scorelessTurn() {
+	g.scorelessTurns++
+	if g.scorelessTurns >= g.endGameRules.withDefaults().MaxScorelessTurns {
+		g.finish(-1)
+		return
+	}
+	g.nextPlayer()
+}
+
+func (g *Game) nextPlayer() {
+	g.currentPlayer = (g.currentPlayer + 1) % len(g.players)
+}
+
+// finish ends the game and settles the racks left on the board. goneOut is
+// the index of the player who played all of their tiles, or -1 if nobody did.
+func (g *Game) finish(goneOut int) {
+	racks := make([]Rack, len(g.players))
+	for i, player := range g.players {
+		racks[i] = player.rack.Copy()
+	}
+	for _, adjustment := range g.endGameRules.Adjustments(racks, goneOut, g.letterScores) {
+		g.adjust(adjustment)
+	}
+	g.over = true
+	g.notify(GameEvent{Type: GameEnded, Player: -1})
+}
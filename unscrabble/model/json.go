@@ -0,0 +1,598 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// JSONVersion is the version of the JSON encoding of games, boards, racks and
+// moves. It is written to every GameJSON, and is incremented whenever the
+// encoding changes in a way that existing readers would misunderstand.
+//
+// Tiles are written using the symbols of the game's alphabet, so a Spanish
+// "ch" tile is "ch" rather than the letter used to represent it. Words and
+// racks use the notation of FormatWord and FormatRack: symbols are in upper
+// case, blanks on the board are in lower case, blanks on a rack are '?', and
+// multi-character symbols are in square brackets. Rows and columns are
+// zero-indexed. The testdata of the model tests has an example of each
+// encoding.
+const JSONVersion = 1
+
+// AllPlayers is the viewer of a GameJSON that can see every rack and the tiles
+// in the bag
+const AllPlayers = -1
+
+// Spectator is the viewer of a GameJSON that can see the racks of none of the
+// players
+const Spectator = -2
+
+// PlacedTileJSON is the JSON encoding of a tile that has been placed on the
+// board
+type PlacedTileJSON struct {
+	Row    int    `json:"row"`
+	Column int    `json:"column"`
+	Letter string `json:"letter"` // Letter is the lower case symbol of the tile
+	Blank  bool   `json:"blank,omitempty"`
+}
+
+// BoardJSON is the JSON encoding of a Board. The multipliers of squares with a
+// tile on them are 1, as their premiums have been used. The anchors and
+// cross-check sets are not encoded, as they are calculated from the tiles.
+type BoardJSON struct {
+	WordMultipliers    [][]int          `json:"word_multipliers"`
+	LetterMultipliers  [][]int          `json:"letter_multipliers"`
+	Tiles              []PlacedTileJSON `json:"tiles"`
+	StartSquares       []Position       `json:"start_squares"`
+	FirstMoveAnywhere  bool             `json:"first_move_anywhere,omitempty"`
+	MinFirstWordLength int              `json:"min_first_word_length,omitempty"`
+}
+
+// RackJSON is the JSON encoding of a Rack. Tiles is empty if the rack is
+// hidden from the viewer of a game.
+type RackJSON struct {
+	Tiles     string `json:"tiles,omitempty"`
+	TileCount int    `json:"tile_count"`
+	Capacity  int    `json:"capacity"`
+}
+
+// MoveJSON is the JSON encoding of a Move. Word includes the tiles on the
+// board that the move plays through.
+type MoveJSON struct {
+	Row        int    `json:"row"`
+	Column     int    `json:"column"`
+	Horizontal bool   `json:"horizontal"`
+	Word       string `json:"word"`
+	Score      int    `json:"score"`
+}
+
+// TurnJSON is the JSON encoding of a Turn. The rack and exchanged tiles of a
+// turn are hidden like the rack of the player who took it, except for the end
+// of game adjustments, which reveal the racks anyway.
+type TurnJSON struct {
+	Player         int       `json:"player"`
+	Type           TurnType  `json:"type"`
+	Rack           RackJSON  `json:"rack"`
+	Move           *MoveJSON `json:"move,omitempty"`
+	Exchanged      string    `json:"exchanged,omitempty"`
+	TilesExchanged int       `json:"tiles_exchanged,omitempty"`
+	Score          int       `json:"score"`
+	Total          int       `json:"total"`
+}
+
+// ActionJSON is the JSON encoding of an Action. The tiles exchanged and drawn
+// are never hidden, so the actions of a game should only be shared with the
+// players once it is over.
+type ActionJSON struct {
+	Type       ActionType `json:"type"`
+	Player     int        `json:"player"`
+	Move       *MoveJSON  `json:"move,omitempty"`
+	Tiles      string     `json:"tiles,omitempty"`
+	Drawn      string     `json:"drawn,omitempty"`
+	Successful bool       `json:"successful,omitempty"`
+}
+
+// PlayerJSON is the JSON encoding of a Player
+type PlayerJSON struct {
+	Name  string   `json:"name"`
+	Score int      `json:"score"`
+	Rack  RackJSON `json:"rack"`
+}
+
+// ChallengeJSON is the JSON encoding of the last play of a game while it can
+// still be challenged. ScorelessTurns is the number of consecutive scoreless
+// turns before the play, which is restored if the play is withdrawn.
+type ChallengeJSON struct {
+	Player         int      `json:"player"`
+	Move           MoveJSON `json:"move"`
+	ScorelessTurns int      `json:"scoreless_turns"`
+}
+
+// GameJSON is the JSON encoding of the state of a Game as seen by one of its
+// players, by AllPlayers or by a Spectator. The racks of the other players and
+// the tiles in the bag are hidden from a player, and only a GameJSON seen by
+// AllPlayers can be restored.
+type GameJSON struct {
+	Version        int            `json:"version"`
+	Viewer         int            `json:"viewer"`
+	Configuration  Configuration  `json:"configuration"`
+	Players        []PlayerJSON   `json:"players"`
+	Board          BoardJSON      `json:"board"`
+	Bag            string         `json:"bag,omitempty"`
+	TilesInBag     int            `json:"tiles_in_bag"`
+	CurrentPlayer  int            `json:"current_player"`
+	ScorelessTurns int            `json:"scoreless_turns"`
+	Challenge      *ChallengeJSON `json:"challenge,omitempty"`
+	Over           bool           `json:"over"`
+	History        []TurnJSON     `json:"history"`
+}
+
+// MarshalText encodes a TurnType as its name with underscores between words,
+// e.g. "end_rack_bonus"
+func (turnType TurnType) MarshalText() ([]byte, error) {
+	if turnType < PlayTurn || turnType > FailedChallengeTurn {
+		return nil, fmt.Errorf("unknown turn type %d", int(turnType))
+	}
+	return []byte(strings.ReplaceAll(turnType.String(), " ", "_")), nil
+}
+
+// UnmarshalText decodes a TurnType encoded by MarshalText
+func (turnType *TurnType) UnmarshalText(text []byte) error {
+	for candidate := PlayTurn; candidate <= FailedChallengeTurn; candidate++ {
+		if name, _ := candidate.MarshalText(); string(name) == string(text) {
+			*turnType = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown turn type %q", text)
+}
+
+// EncodeBoard returns the JSON encoding of board
+func (alphabet *Alphabet) EncodeBoard(board Board) BoardJSON {
+	encoded := BoardJSON{
+		WordMultipliers:    make([][]int, board.Rows()),
+		LetterMultipliers:  make([][]int, board.Rows()),
+		Tiles:              []PlacedTileJSON{},
+		StartSquares:       board.firstMoveRules.StartSquares,
+		FirstMoveAnywhere:  board.firstMoveRules.Anywhere,
+		MinFirstWordLength: board.firstMoveRules.MinWordLength,
+	}
+	for y, row := range board.Tiles {
+		encoded.WordMultipliers[y] = make([]int, len(row))
+		encoded.LetterMultipliers[y] = make([]int, len(row))
+		for x, tile := range row {
+			if tile.Empty() {
+				encoded.WordMultipliers[y][x] = tile.WordMultiplier
+				encoded.LetterMultipliers[y][x] = tile.LetterMultiplier
+				continue
+			}
+			encoded.WordMultipliers[y][x] = 1
+			encoded.LetterMultipliers[y][x] = 1
+			encoded.Tiles = append(encoded.Tiles, PlacedTileJSON{
+				Row:    y,
+				Column: x,
+				Letter: alphabet.Symbol(tile.Letter),
+				Blank:  tile.IsBlank(),
+			})
+		}
+	}
+	return encoded
+}
+
+// DecodeBoard returns the board encoded by EncodeBoard. The anchors and
+// cross-checks of the board are calculated from its tiles.
+func (alphabet *Alphabet) DecodeBoard(
+	encoded BoardJSON,
+	crossCheckSetGenerator CrossCheckSetGenerator,
+	letterScores map[rune]int,
+) (Board, error) {
+	if len(encoded.WordMultipliers) == 0 || len(encoded.WordMultipliers[0]) == 0 {
+		return Board{}, errors.New("board has no squares")
+	}
+	if len(encoded.LetterMultipliers) != len(encoded.WordMultipliers) {
+		return Board{}, errors.New("board has different numbers of rows of word and letter multipliers")
+	}
+	columns := len(encoded.WordMultipliers[0])
+	for y := range encoded.WordMultipliers {
+		if len(encoded.WordMultipliers[y]) != columns || len(encoded.LetterMultipliers[y]) != columns {
+			return Board{}, fmt.Errorf("row %v of the board does not have %v columns", y, columns)
+		}
+	}
+
+	board := NewBoardWithRules(
+		crossCheckSetGenerator,
+		encoded.WordMultipliers,
+		encoded.LetterMultipliers,
+		FirstMoveRules{
+			StartSquares:  encoded.StartSquares,
+			Anywhere:      encoded.FirstMoveAnywhere,
+			MinWordLength: encoded.MinFirstWordLength,
+		},
+	)
+	for i, placed := range encoded.Tiles {
+		position := Position{Row: placed.Row, Column: placed.Column}
+		if !board.Contains(position) {
+			return Board{}, fmt.Errorf("tile %v is not on the board", i+1)
+		}
+		letter, ok := alphabet.Letter(placed.Letter)
+		if !ok || letter == BlankTile {
+			return Board{}, fmt.Errorf("tile %v: %q is not in the alphabet", i+1, placed.Letter)
+		}
+		tile := board.Tiles[position.Row][position.Column]
+		if !tile.Empty() {
+			return Board{}, fmt.Errorf("tile %v is on a square that already has a tile", i+1)
+		}
+		tile.place(letter, placed.Blank)
+	}
+	board.updateAnchors()
+	board.updateCrossChecks(letterScores)
+	return board, nil
+}
+
+// EncodeRack returns the JSON encoding of rack
+func (alphabet *Alphabet) EncodeRack(rack *Rack) RackJSON {
+	return alphabet.encodeRack(rack, false)
+}
+
+func (alphabet *Alphabet) encodeRack(rack *Rack, hidden bool) RackJSON {
+	encoded := RackJSON{TileCount: rack.TileCount(), Capacity: rack.Capacity()}
+	if !hidden {
+		encoded.Tiles = alphabet.FormatRack(rack)
+	}
+	return encoded
+}
+
+// DecodeRack returns the rack encoded by EncodeRack. An error is returned if
+// the tiles of the rack are hidden.
+func (alphabet *Alphabet) DecodeRack(encoded RackJSON) (*Rack, error) {
+	rack, err := alphabet.ParseRack(encoded.Tiles, encoded.Capacity, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rack.TileCount() != encoded.TileCount {
+		return nil, fmt.Errorf(
+			"rack %q has %v tiles but its tile_count is %v", encoded.Tiles, rack.TileCount(), encoded.TileCount,
+		)
+	}
+	return rack, nil
+}
+
+// EncodeMove returns the JSON encoding of move
+func (alphabet *Alphabet) EncodeMove(move Move) MoveJSON {
+	return MoveJSON{
+		Row:        move.StartPosition.Row,
+		Column:     move.StartPosition.Column,
+		Horizontal: move.Horizontal,
+		Word:       alphabet.FormatWord(move.Word),
+		Score:      move.Score,
+	}
+}
+
+// DecodeMove returns the move encoded by EncodeMove
+func (alphabet *Alphabet) DecodeMove(encoded MoveJSON) (Move, error) {
+	word, err := alphabet.ParseWord(encoded.Word)
+	if err != nil {
+		return Move{}, err
+	}
+	return Move{
+		StartPosition: &Position{Row: encoded.Row, Column: encoded.Column},
+		Horizontal:    encoded.Horizontal,
+		Word:          word,
+		Score:         encoded.Score,
+	}, nil
+}
+
+// EncodeAction returns the JSON encoding of action
+func (alphabet *Alphabet) EncodeAction(action Action) ActionJSON {
+	encoded := ActionJSON{
+		Type:       action.Type,
+		Player:     action.Player,
+		Tiles:      alphabet.formatTiles(countLetters(action.Tiles)),
+		Drawn:      alphabet.formatTiles(countLetters(action.Drawn)),
+		Successful: action.Successful,
+	}
+	if action.Move != nil {
+		move := alphabet.EncodeMove(*action.Move)
+		encoded.Move = &move
+	}
+	return encoded
+}
+
+// DecodeAction returns the action encoded by EncodeAction
+func (alphabet *Alphabet) DecodeAction(encoded ActionJSON) (Action, error) {
+	action := Action{
+		Type:       encoded.Type,
+		Player:     encoded.Player,
+		Successful: encoded.Successful,
+	}
+	if encoded.Move != nil {
+		move, err := alphabet.DecodeMove(*encoded.Move)
+		if err != nil {
+			return Action{}, err
+		}
+		action.Move = &move
+	}
+	var err error
+	if action.Tiles, _, err = alphabet.tokenise(encoded.Tiles, true); err != nil {
+		return Action{}, fmt.Errorf("tiles: %w", err)
+	}
+	if action.Drawn, _, err = alphabet.tokenise(encoded.Drawn, true); err != nil {
+		return Action{}, fmt.Errorf("drawn: %w", err)
+	}
+	if len(action.Tiles) == 0 {
+		action.Tiles = nil
+	}
+	if len(action.Drawn) == 0 {
+		action.Drawn = nil
+	}
+	return action, nil
+}
+
+// countLetters returns the number of each letter in letters
+func countLetters(letters []rune) map[rune]int {
+	counts := make(map[rune]int, len(letters))
+	for _, letter := range letters {
+		counts[letter]++
+	}
+	return counts
+}
+
+func (alphabet *Alphabet) encodeTurn(turn Turn, hidden bool) TurnJSON {
+	encoded := TurnJSON{
+		Player:         turn.Player,
+		Type:           turn.Type,
+		Rack:           alphabet.encodeRack(&turn.Rack, hidden),
+		TilesExchanged: len(turn.Exchanged),
+		Score:          turn.Score,
+		Total:          turn.Total,
+	}
+	if turn.Move != nil {
+		move := alphabet.EncodeMove(*turn.Move)
+		encoded.Move = &move
+	}
+	if !hidden && len(turn.Exchanged) > 0 {
+		encoded.Exchanged = alphabet.formatTiles(countLetters(turn.Exchanged))
+	}
+	return encoded
+}
+
+func (alphabet *Alphabet) decodeTurn(encoded TurnJSON) (Turn, error) {
+	rack, err := alphabet.DecodeRack(encoded.Rack)
+	if err != nil {
+		return Turn{}, err
+	}
+	turn := Turn{
+		Player: encoded.Player,
+		Type:   encoded.Type,
+		Rack:   *rack,
+		Score:  encoded.Score,
+		Total:  encoded.Total,
+	}
+	if encoded.Move != nil {
+		move, err := alphabet.DecodeMove(*encoded.Move)
+		if err != nil {
+			return Turn{}, err
+		}
+		turn.Move = &move
+	} else if turn.Type == PlayTurn {
+		return Turn{}, errors.New("play does not have a move")
+	}
+	if turn.Exchanged, _, err = alphabet.tokenise(encoded.Exchanged, true); err != nil {
+		return Turn{}, err
+	}
+	if len(turn.Exchanged) != encoded.TilesExchanged {
+		return Turn{}, fmt.Errorf(
+			"%v tiles were exchanged but tiles_exchanged is %v", len(turn.Exchanged), encoded.TilesExchanged,
+		)
+	}
+	if len(turn.Exchanged) == 0 {
+		turn.Exchanged = nil
+	}
+	return turn, nil
+}
+
+// JSON returns the JSON encoding of the state of the game as seen by viewer,
+// which is the index of a player, AllPlayers or Spectator
+func (g *Game) JSON(viewer int) GameJSON {
+	hidden := func(player int) bool {
+		return viewer != AllPlayers && viewer != player
+	}
+	encoded := GameJSON{
+		Version:        JSONVersion,
+		Viewer:         viewer,
+		Configuration:  g.config,
+		Players:        make([]PlayerJSON, len(g.players)),
+		Board:          g.alphabet.EncodeBoard(g.board),
+		TilesInBag:     len(g.letterBag),
+		CurrentPlayer:  g.currentPlayer,
+		ScorelessTurns: g.scorelessTurns,
+		Over:           g.over,
+		History:        make([]TurnJSON, len(g.record)),
+	}
+	for i, player := range g.players {
+		encoded.Players[i] = PlayerJSON{
+			Name:  player.name,
+			Score: player.score,
+			Rack:  g.alphabet.encodeRack(player.rack, hidden(i)),
+		}
+	}
+	if viewer == AllPlayers {
+		encoded.Bag = g.alphabet.formatTiles(g.letterBag.LetterCounts())
+	}
+	if g.pending != nil {
+		encoded.Challenge = &ChallengeJSON{
+			Player:         g.pending.player,
+			Move:           g.alphabet.EncodeMove(g.pending.move),
+			ScorelessTurns: g.pending.scorelessTurns,
+		}
+	}
+	for i, turn := range g.record {
+		adjustment := turn.Type == EndRackBonusTurn || turn.Type == EndRackPenaltyTurn
+		encoded.History[i] = g.alphabet.encodeTurn(turn, hidden(turn.Player) && !adjustment)
+	}
+	return encoded
+}
+
+// RestoreGame recreates a game from the JSON encoding of its state as seen by
+// AllPlayers. The players must have the names of the players in the encoding,
+// and are given their racks and scores. The board is rebuilt by replaying the
+// history, and an error is returned if the tiles on the board, the racks and
+// the bag do not add up to the tiles of the configuration.
+func RestoreGame(encoded GameJSON, lexicon Lexicon, players ...*Player) (*Game, error) {
+	return restoreGame(encoded, lexicon, rand.New(rand.NewSource(time.Now().UnixNano())), players)
+}
+
+// RestoreSeededGame restores a game like RestoreGame, whose bag is shuffled
+// in an order decided by seed like the bag of NewSeededGame
+func RestoreSeededGame(encoded GameJSON, lexicon Lexicon, seed int64, players ...*Player) (*Game, error) {
+	return restoreGame(encoded, lexicon, rand.New(rand.NewSource(seed)), players)
+}
+
+// restoreGame restores a game whose bag is shuffled by random
+func restoreGame(encoded GameJSON, lexicon Lexicon, random *rand.Rand, players []*Player) (*Game, error) {
+	if encoded.Version != JSONVersion {
+		return nil, fmt.Errorf("cannot restore version %v of a game, only version %v", encoded.Version, JSONVersion)
+	}
+	if encoded.Viewer != AllPlayers {
+		return nil, errors.New("cannot restore a game with hidden tiles")
+	}
+	if len(players) != len(encoded.Players) {
+		return nil, fmt.Errorf("game has %v players but %v were given", len(encoded.Players), len(players))
+	}
+	if errs := encoded.Configuration.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("configuration: %w", errs[0])
+	}
+	g, err := newGame(encoded.Configuration, lexicon, random, players)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, player := range encoded.Players {
+		if player.Name != players[i].name {
+			return nil, fmt.Errorf("player %v is %q but %q was given", i+1, player.Name, players[i].name)
+		}
+		rack, err := g.alphabet.DecodeRack(player.Rack)
+		if err != nil {
+			return nil, fmt.Errorf("player %v: %w", i+1, err)
+		}
+		players[i].rack = rack
+		players[i].score = player.Score
+	}
+	bag, _, err := g.alphabet.tokenise(encoded.Bag, true)
+	if err != nil {
+		return nil, fmt.Errorf("bag: %w", err)
+	}
+	g.letterBag = RandomLetterBag(bag)
+	g.letterBag.shuffle(g.random)
+
+	g.record = make([]Turn, len(encoded.History))
+	for i, turn := range encoded.History {
+		if turn.Player < 0 || turn.Player >= len(players) {
+			return nil, fmt.Errorf("history %v: there is no player %v", i+1, turn.Player)
+		}
+		if g.record[i], err = g.alphabet.decodeTurn(turn); err != nil {
+			return nil, fmt.Errorf("history %v: %w", i+1, err)
+		}
+	}
+	if g.board, err = g.replayBoard(g.record); err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	if err := g.checkTiles(); err != nil {
+		return nil, err
+	}
+
+	if encoded.CurrentPlayer < 0 || encoded.CurrentPlayer >= len(players) {
+		return nil, fmt.Errorf("there is no player %v", encoded.CurrentPlayer)
+	}
+	g.currentPlayer = encoded.CurrentPlayer
+	g.scorelessTurns = encoded.ScorelessTurns
+	g.over = encoded.Over
+	if encoded.Challenge != nil {
+		if err := g.restoreChallenge(*encoded.Challenge); err != nil {
+			return nil, fmt.Errorf("challenge: %w", err)
+		}
+	}
+	return g, nil
+}
+
+// checkTiles checks that the tiles on the board, the racks and in the bag are
+// the tiles of the configuration
+func (g *Game) checkTiles() error {
+	letterCounts, err := g.alphabet.LetterMap(g.config.LetterCounts)
+	if err != nil {
+		return err
+	}
+	tiles := g.letterBag.LetterCounts()
+	for _, player := range g.players {
+		for letter, count := range player.rack.LetterCounts() {
+			tiles[letter] += count
+		}
+	}
+	for _, row := range g.board.Tiles {
+		for _, tile := range row {
+			if tile.IsBlank() {
+				tiles[BlankTile]++
+			} else if !tile.Empty() {
+				tiles[tile.Letter]++
+			}
+		}
+	}
+	for _, letter := range append(g.alphabet.Letters(), BlankTile) {
+		if tiles[letter] != letterCounts[letter] {
+			return fmt.Errorf(
+				"game has %v %q tiles but the configuration has %v",
+				tiles[letter],
+				g.alphabet.Symbol(letter),
+				letterCounts[letter],
+			)
+		}
+	}
+	return nil
+}
+
+// restoreChallenge makes the last play of a restored game open to challenge
+func (g *Game) restoreChallenge(encoded ChallengeJSON) error {
+	if !g.challengeRule.allowsPhonies() {
+		return errors.New("plays cannot be challenged under the challenge rule")
+	}
+	last := len(g.record) - 1
+	if last < 0 || g.record[last].Type != PlayTurn || g.record[last].Player != encoded.Player {
+		return fmt.Errorf("the last turn is not a play by player %v", encoded.Player)
+	}
+	turn := g.record[last]
+	boardBefore := g.BoardBefore(last)
+	err := boardBefore.ValidateMove(*turn.Move, &turn.Rack, g.lexicon)
+	var phonyErr *PhonyError
+	phony := errors.As(err, &phonyErr)
+	if err != nil && !phony {
+		return err
+	}
+
+	// the tiles drawn after the play are the tiles on the rack which were not
+	// left on it by the play
+	drawn := g.players[turn.Player].rack.Copy()
+	left := turn.Rack.Copy()
+	for _, letter := range boardBefore.TilesPlaced(*turn.Move) {
+		left.RemoveLetter(letter)
+	}
+	for _, letter := range left.Letters() {
+		if !drawn.HasTile(letter) {
+			return errors.New("the rack of the player does not have the tiles left by the play")
+		}
+		drawn.RemoveLetter(letter)
+	}
+
+	g.pending = &pendingPlay{
+		player:         turn.Player,
+		move:           *turn.Move,
+		phony:          phony,
+		wentOut:        g.players[turn.Player].rack.TileCount() == 0,
+		boardBefore:    boardBefore,
+		rackBefore:     turn.Rack,
+		drawn:          drawn.Letters(),
+		scorelessTurns: encoded.ScorelessTurns,
+	}
+	return nil
+}
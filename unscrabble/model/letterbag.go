@@ -2,36 +2,59 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
+	"sort"
 )
 
 // RandomLetterBag is an abstract data structure which allows for efficient random
 // sampling without replacement. This is achieved by using stack-like item
 // popping and shuffling the underlying array any time a new item is added.
+// The methods which shuffle the bag are given the source of randomness to
+// shuffle it with, so that games with the same seed draw the same tiles.
 type RandomLetterBag []rune
 
-// NewRandomLetterBag is for constructing a new letterbag from counts of letters
-func NewRandomLetterBag(letterCounts map[rune]int) RandomLetterBag {
+// NewRandomLetterBag is for constructing a new letterbag from counts of
+// letters, shuffled by random
+func NewRandomLetterBag(letterCounts map[rune]int, random *rand.Rand) RandomLetterBag {
 	numLetters := 0
 	for _, count := range letterCounts {
 		numLetters += count
 	}
 	bag := make(RandomLetterBag, 0, numLetters)
-	bag.addLetterCounts(letterCounts)
+	bag.addLetterCounts(letterCounts, random)
 	return bag
 }
 
-func (bag *RandomLetterBag) addLetterCounts(letterCounts map[rune]int) {
-	for letter, count := range letterCounts {
-		for i := 0; i < count; i++ {
+// addLetterCounts adds the letters in sorted order before shuffling the bag,
+// so that seeding the source of randomness is enough to reproduce the order of
+// the bag
+func (bag *RandomLetterBag) addLetterCounts(letterCounts map[rune]int, random *rand.Rand) {
+	letters := make([]rune, 0, len(letterCounts))
+	for letter := range letterCounts {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	for _, letter := range letters {
+		for i := 0; i < letterCounts[letter]; i++ {
 			*bag = append(*bag, letter)
 		}
 	}
-	bag.shuffle()
+	bag.shuffle(random)
+}
+
+// addLetter inserts a letter at a uniformly random position in the bag. As the
+// bag is already a uniformly random permutation, this is a single step of an
+// inside-out shuffle and keeps the bag uniformly random without a full shuffle.
+func (bag *RandomLetterBag) addLetter(letter rune, random *rand.Rand) {
+	*bag = append(*bag, letter)
+	last := len(*bag) - 1
+	i := random.Intn(last + 1)
+	(*bag)[i], (*bag)[last] = (*bag)[last], (*bag)[i]
 }
 
-func (bag *RandomLetterBag) shuffle() {
-	rand.Shuffle(len(*bag), func(i, j int) {
+func (bag *RandomLetterBag) shuffle(random *rand.Rand) {
+	random.Shuffle(len(*bag), func(i, j int) {
 		(*bag)[i], (*bag)[j] = (*bag)[j], (*bag)[i]
 	})
 }
@@ -51,3 +74,71 @@ func (bag *RandomLetterBag) GetLetter() (rune, error) {
 func (bag *RandomLetterBag) HasLetter() bool {
 	return len(*bag) != 0
 }
+
+// ReturnLetters puts letters back into the bag at random positions so that
+// subsequent draws remain unbiased.
+func (bag *RandomLetterBag) ReturnLetters(random *rand.Rand, letters ...rune) {
+	for _, letter := range letters {
+		bag.addLetter(letter, random)
+	}
+}
+
+// DrawLetters removes the specified letters from the bag, e.g. for setting up
+// a position for analysis. If the bag does not contain all of the letters an
+// error is returned and the bag is left unchanged.
+func (bag *RandomLetterBag) DrawLetters(random *rand.Rand, letters ...rune) error {
+	remaining := bag.LetterCounts()
+	for _, letter := range letters {
+		if remaining[letter] == 0 {
+			return fmt.Errorf("bag does not contain enough %q tiles", letter)
+		}
+		remaining[letter]--
+	}
+
+	for _, letter := range letters {
+		for i := len(*bag) - 1; i >= 0; i-- {
+			if (*bag)[i] == letter {
+				last := len(*bag) - 1
+				(*bag)[i] = (*bag)[last]
+				*bag = (*bag)[:last]
+				break
+			}
+		}
+	}
+	// Removing specific letters depends on where they were in the bag, so the
+	// remaining letters are reshuffled to keep sampling uniform.
+	bag.shuffle(random)
+	return nil
+}
+
+// Exchange swaps letters from a rack for the same number of random letters
+// from the bag. The new letters are drawn before the old letters are returned,
+// so a player can never draw back the tiles they exchanged. An error is
+// returned, and the bag is left unchanged, if the bag has fewer letters than
+// are being exchanged.
+func (bag *RandomLetterBag) Exchange(random *rand.Rand, letters ...rune) ([]rune, error) {
+	if len(letters) > len(*bag) {
+		return nil, fmt.Errorf(
+			"cannot exchange %v letters as bag only has %v letters",
+			len(letters),
+			len(*bag),
+		)
+	}
+
+	drawn := make([]rune, 0, len(letters))
+	for range letters {
+		letter, _ := bag.GetLetter()
+		drawn = append(drawn, letter)
+	}
+	bag.ReturnLetters(random, letters...)
+	return drawn, nil
+}
+
+// LetterCounts returns the number of each letter remaining in the bag.
+func (bag *RandomLetterBag) LetterCounts() map[rune]int {
+	letterCounts := make(map[rune]int)
+	for _, letter := range *bag {
+		letterCounts[letter]++
+	}
+	return letterCounts
+}
@@ -0,0 +1,77 @@
+package model
+
+// LetterSet is a compact set of letters, stored as a bitset indexed by each
+// letter's position in the alphabet. Sets can be intersected with a single AND,
+// which makes them cheap to use in the inner loops of move generation.
+type LetterSet uint64
+
+const (
+	// EmptyLetterSet contains no letters
+	EmptyLetterSet LetterSet = 0
+	// UnconstrainedLetterSet contains every letter. It is used for the
+	// cross-check set of a Tile which has no placed tiles above or below it.
+	UnconstrainedLetterSet LetterSet = ^LetterSet(0)
+	// allLetters contains every letter of the alphabet. Unlike the
+	// UnconstrainedLetterSet, it does not have the bit above the last letter.
+	allLetters LetterSet = UnconstrainedLetterSet >> (64 - maxAlphabetSize)
+)
+
+// NewLetterSet returns a set of the provided letters. Letters outside of the
+// alphabet are ignored.
+func NewLetterSet(letters ...rune) LetterSet {
+	set := EmptyLetterSet
+	for _, letter := range letters {
+		set = set.With(letter)
+	}
+	return set
+}
+
+// With returns the set with letter added to it
+func (set LetterSet) With(letter rune) LetterSet {
+	index, ok := letterIndex(letter)
+	if !ok {
+		return set
+	}
+	return set | 1<<uint(index)
+}
+
+// Without returns the set with letter removed from it
+func (set LetterSet) Without(letter rune) LetterSet {
+	index, ok := letterIndex(letter)
+	if !ok {
+		return set
+	}
+	return set &^ (1 << uint(index))
+}
+
+// Contains tells us whether letter is in the set. Letters outside of the
+// alphabet are only contained in the unconstrained set.
+func (set LetterSet) Contains(letter rune) bool {
+	index, ok := letterIndex(letter)
+	if !ok {
+		return set.IsUnconstrained()
+	}
+	return set&(1<<uint(index)) != 0
+}
+
+// Intersect returns the letters that are in both sets
+func (set LetterSet) Intersect(other LetterSet) LetterSet {
+	return set & other
+}
+
+// IsUnconstrained tells us whether the set places no constraint on letters
+func (set LetterSet) IsUnconstrained() bool {
+	return set == UnconstrainedLetterSet
+}
+
+// Letters returns the letters in the set in alphabetical order. The blank is
+// not included.
+func (set LetterSet) Letters() []rune {
+	var letters []rune
+	for index := 1; index < maxAlphabetSize; index++ {
+		if set&(1<<uint(index)) != 0 {
+			letters = append(letters, indexLetter(index))
+		}
+	}
+	return letters
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"unicode/utf8"
 )
 
 // Move contains a single candidate word, and a position for that word, that a
@@ -18,41 +19,68 @@ type Word struct {
 	BlankTiles []bool
 }
 
+// Length returns the number of tiles in the word
+func (word Word) Length() int {
+	return utf8.RuneCountInString(word.Chars)
+}
+
+// steps returns the change in row and column between consecutive tiles of the move
+func (move *Move) steps() (rowStep, columnStep int) {
+	if move.Horizontal {
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// CalculateScore calculates the score of the move on the board. Tiles that are
+// already on the board are scored without their premiums, as are blanks.
 func (move *Move) CalculateScore(board Board, letterScores map[rune]int, rackSize, bingoPremium int) (int, error) {
 	y := move.StartPosition.Row
 	x := move.StartPosition.Column
+	rowStep, columnStep := move.steps()
 
-	if len(move.Word.BlankTiles) != len(move.Word.Chars) {
+	wordLength := move.Word.Length()
+	if len(move.Word.BlankTiles) != wordLength {
 		return 0, errors.New("blanks should be same length as word")
 	}
 
-	if len(move.Word.Chars) > (len(board.Tiles) - x) {
+	end := Position{Row: y + rowStep*(wordLength-1), Column: x + columnStep*(wordLength-1)}
+	if !board.Contains(*move.StartPosition) || !board.Contains(end) {
 		return 0, errors.New("word extends beyond end of board.tiles")
 	}
 
 	crossScore := 0
-	horizontalScore := 0
-	horizontalWordMultiplier := 1
+	mainScore := 0
+	mainWordMultiplier := 1
 	tilesPlaced := 0
 
-	for i, char := range move.Word.Chars {
-		tile := board.Tiles[y][x+i]
+	i := 0
+	for _, char := range move.Word.Chars {
+		tile := board.Tiles[y+i*rowStep][x+i*columnStep]
 		letterScore := letterScores[char] * tile.LetterMultiplier
 		if move.Word.BlankTiles[i] {
 			letterScore = 0
 		}
-		horizontalScore += letterScore
+		mainScore += letterScore
 
 		if tile.Letter == 0 {
-			horizontalWordMultiplier *= tile.WordMultiplier
-			if tile.CrossCheckSet != nil {
-				crossScore += (tile.CrossScore + letterScore) * tile.WordMultiplier
+			// The cross-check set constrains the word formed perpendicular
+			// to the move
+			crossCheckSet, tileCrossScore := tile.CrossCheckSet, tile.CrossScore
+			if !move.Horizontal {
+				crossCheckSet, tileCrossScore = tile.transposeCrossCheckSet, tile.transposeCrossScore
+			}
+
+			mainWordMultiplier *= tile.WordMultiplier
+			if !crossCheckSet.IsUnconstrained() {
+				crossScore += (tileCrossScore + letterScore) * tile.WordMultiplier
 			}
 			tilesPlaced += 1
 		}
+		i++
 	}
-	horizontalScore *= horizontalWordMultiplier
-	score := horizontalScore + crossScore
+	mainScore *= mainWordMultiplier
+	score := mainScore + crossScore
 	if tilesPlaced == rackSize {
 		score += bingoPremium
 	}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCoordinates converts standard coordinate notation to a zero-indexed
+// position. Horizontal moves are written with the row number first, e.g. "8H",
+// and vertical moves with the column letter first, e.g. "H8". Columns are
+// lettered from 'A', so boards can have at most 26 columns.
+func ParseCoordinates(coordinates string) (position Position, horizontal bool, err error) {
+	upper := strings.ToUpper(strings.TrimSpace(coordinates))
+	invalid := fmt.Errorf("invalid coordinates %q", coordinates)
+	if len(upper) < 2 {
+		return Position{}, false, invalid
+	}
+
+	var rowPart, columnPart string
+	if upper[0] >= '0' && upper[0] <= '9' {
+		horizontal = true
+		rowPart, columnPart = upper[:len(upper)-1], upper[len(upper)-1:]
+	} else {
+		columnPart, rowPart = upper[:1], upper[1:]
+	}
+	row, err := strconv.Atoi(rowPart)
+	if err != nil || row < 1 || columnPart[0] < 'A' || columnPart[0] > 'Z' {
+		return Position{}, false, invalid
+	}
+	return Position{Row: row - 1, Column: int(columnPart[0] - 'A')}, horizontal, nil
+}
+
+// Coordinates returns the position in standard coordinate notation for a
+// horizontal or vertical move starting at it, see ParseCoordinates
+func (position Position) Coordinates(horizontal bool) string {
+	column := string(rune('A' + position.Column))
+	if horizontal {
+		return strconv.Itoa(position.Row+1) + column
+	}
+	return column + strconv.Itoa(position.Row+1)
+}
+
+// Coordinates returns the coordinates of the move, e.g. "8H"
+func (move Move) Coordinates() string {
+	return move.StartPosition.Coordinates(move.Horizontal)
+}
+
+// FormatMove returns move in standard notation, e.g. "8D RE(T)AINs". The
+// tiles the move plays through on the board are in parentheses, and blanks
+// are in lower case.
+func (alphabet *Alphabet) FormatMove(board Board, move Move) string {
+	rowStep, columnStep := move.steps()
+	var sb strings.Builder
+	sb.WriteString(move.Coordinates())
+	sb.WriteRune(' ')
+	through := false
+	i := 0
+	for _, letter := range move.Word.Chars {
+		position := Position{
+			Row:    move.StartPosition.Row + i*rowStep,
+			Column: move.StartPosition.Column + i*columnStep,
+		}
+		onBoard := board.Contains(position) && !board.Tiles[position.Row][position.Column].Empty()
+		if onBoard != through {
+			if onBoard {
+				sb.WriteRune('(')
+			} else {
+				sb.WriteRune(')')
+			}
+			through = onBoard
+		}
+		blank := i < len(move.Word.BlankTiles) && move.Word.BlankTiles[i] && !onBoard
+		sb.WriteString(alphabet.FormatWord(Word{Chars: string(letter), BlankTiles: []bool{blank}}))
+		i++
+	}
+	if through {
+		sb.WriteRune(')')
+	}
+	return sb.String()
+}
+
+// ParseMove converts standard notation, such as "8D RE(T)AINs", to a move on
+// the board. Tiles played through can be written in parentheses, which must
+// match the tiles on the board, or as '.' for whatever tile is on the board.
+// Blanks are written in lower case.
+func (alphabet *Alphabet) ParseMove(board Board, notation string) (Move, error) {
+	fields := strings.Fields(notation)
+	if len(fields) != 2 {
+		return Move{}, fmt.Errorf("%q should be coordinates followed by a word", notation)
+	}
+	position, horizontal, err := ParseCoordinates(fields[0])
+	if err != nil {
+		return Move{}, err
+	}
+	move := Move{StartPosition: &position, Horizontal: horizontal}
+	rowStep, columnStep := move.steps()
+
+	var chars []rune
+	var blanks []bool
+	// boardLetter returns the letter on the board at the i-th tile of the move
+	boardLetter := func(i int) (rune, error) {
+		tile := Position{Row: position.Row + i*rowStep, Column: position.Column + i*columnStep}
+		if !board.Contains(tile) || board.Tiles[tile.Row][tile.Column].Empty() {
+			return 0, fmt.Errorf("%q plays through %v, which is empty", notation, tile.Coordinates(horizontal))
+		}
+		return board.Tiles[tile.Row][tile.Column].Letter, nil
+	}
+
+	word := fields[1]
+	for len(word) > 0 {
+		switch {
+		case word[0] == '.':
+			letter, err := boardLetter(len(chars))
+			if err != nil {
+				return Move{}, err
+			}
+			chars = append(chars, letter)
+			blanks = append(blanks, false)
+			word = word[1:]
+		case word[0] == '(':
+			end := strings.IndexByte(word, ')')
+			if end == -1 {
+				return Move{}, fmt.Errorf("%q has an unclosed '('", notation)
+			}
+			letters, err := alphabet.Tokenise(word[1:end])
+			if err != nil {
+				return Move{}, err
+			}
+			for _, letter := range letters {
+				onBoard, err := boardLetter(len(chars))
+				if err != nil {
+					return Move{}, err
+				}
+				if onBoard != letter {
+					return Move{}, fmt.Errorf("%q does not match the tiles on the board", notation)
+				}
+				chars = append(chars, letter)
+				blanks = append(blanks, false)
+			}
+			word = word[end+1:]
+		default:
+			end := strings.IndexAny(word, ".(")
+			if end == -1 {
+				end = len(word)
+			}
+			placed, err := alphabet.ParseWord(word[:end])
+			if err != nil {
+				return Move{}, err
+			}
+			chars = append(chars, []rune(placed.Chars)...)
+			blanks = append(blanks, placed.BlankTiles...)
+			word = word[end:]
+		}
+	}
+	move.Word = Word{Chars: string(chars), BlankTiles: blanks}
+	return move, nil
+}
@@ -0,0 +1,98 @@
+package model
+
+import "fmt"
+
+// GameEventType is the kind of a GameEvent
+type GameEventType int
+
+const (
+	// GameStarted is sent to an observer added before the first turn, and is
+	// followed by a RackDrawn event for the rack dealt to each player
+	GameStarted GameEventType = iota
+	// RackDrawn is tiles drawn from the bag onto a player's rack
+	RackDrawn
+	// MoveChosen is a move picked by a player's strategy in PlayTurn, before
+	// it is played
+	MoveChosen
+	// MoveScored is a move played on the board and the points it scored
+	MoveScored
+	// TilesExchanged is tiles returned to the bag in an exchange, which is
+	// followed by the RackDrawn event for the tiles drawn in their place
+	TilesExchanged
+	// TurnPassed is a player passing their turn
+	TurnPassed
+	// PlayChallenged is a challenge of the last play by the current player,
+	// sent before the play is withdrawn or the challenge is penalised
+	PlayChallenged
+	// GameEnded is sent once the game is over and the racks left at the end
+	// have been settled
+	GameEnded
+)
+
+func (eventType GameEventType) String() string {
+	switch eventType {
+	case GameStarted:
+		return "game started"
+	case RackDrawn:
+		return "rack drawn"
+	case MoveChosen:
+		return "move chosen"
+	case MoveScored:
+		return "move scored"
+	case TilesExchanged:
+		return "tiles exchanged"
+	case TurnPassed:
+		return "turn passed"
+	case PlayChallenged:
+		return "play challenged"
+	case GameEnded:
+		return "game ended"
+	}
+	return fmt.Sprintf("GameEventType(%d)", int(eventType))
+}
+
+// GameEvent is something that happened in a game, sent to its observers
+type GameEvent struct {
+	Type       GameEventType
+	Player     int    // Player is the index of the player, or -1 for GameStarted and GameEnded
+	Move       *Move  // Move is the move chosen, scored or challenged
+	Tiles      []rune // Tiles is the tiles drawn for RackDrawn, or returned to the bag for TilesExchanged
+	Score      int    // Score is the points scored by the move for MoveScored
+	Successful bool   // Successful is whether the play was withdrawn for PlayChallenged
+}
+
+// Observer is notified of the events of the games it observes, e.g. for
+// logging, collecting statistics, updating a display or writing a record of a
+// game. Observers are called synchronously as the game changes, by whoever is
+// taking the turns, and must not take turns themselves.
+type Observer interface {
+	Observe(game *Game, event GameEvent)
+}
+
+// ObserverFunc is a function that can be used as an Observer
+type ObserverFunc func(game *Game, event GameEvent)
+
+// Observe calls f
+func (f ObserverFunc) Observe(game *Game, event GameEvent) {
+	f(game, event)
+}
+
+// AddObserver adds an observer to the game. An observer added before the
+// first turn is told that the game has started, and which racks were dealt.
+func (g *Game) AddObserver(observer Observer) {
+	g.observers = append(g.observers, observer)
+	if len(g.record) > 0 || g.over {
+		return
+	}
+	observer.Observe(g, GameEvent{Type: GameStarted, Player: -1})
+	for i, player := range g.players {
+		observer.Observe(g, GameEvent{Type: RackDrawn, Player: i, Tiles: player.rack.Letters()})
+	}
+}
+
+// notify sends an event to the observers of the game
+func (g *Game) notify(event GameEvent) {
+	for _, observer := range g.observers {
+		observer.Observe(g, event)
+	}
+}
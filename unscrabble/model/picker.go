@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// DeadlineGrace is how long after the deadline of a turn a strategy's move is
+// still played. A move picked any later is not played, and the player
+// exchanges or passes as if their strategy had not found a move. A strategy
+// which is not a ContextMovePicker is not waited for once the grace has passed.
+const DeadlineGrace = 100 * time.Millisecond
+
+// ContextMovePicker is implemented by strategies which can be given a deadline
+// or be cancelled, such as simulation and search strategies which keep
+// improving their move for as long as they are given. Once ctx is done the
+// strategy should return the best move it has found so far at once.
+type ContextMovePicker interface {
+	PickMoveContext(ctx context.Context, board Board, rack Rack) *Move
+}
+
+// WithContext returns picker as a ContextMovePicker. A picker which is not one
+// already ignores the context, and always takes as long as it takes.
+func WithContext(picker MovePicker) ContextMovePicker {
+	if picker, ok := picker.(ContextMovePicker); ok {
+		return picker
+	}
+	return contextPicker{picker}
+}
+
+// contextPicker adapts a MovePicker to a ContextMovePicker
+type contextPicker struct {
+	picker MovePicker
+}
+
+func (p contextPicker) PickMoveContext(ctx context.Context, board Board, rack Rack) *Move {
+	return p.picker.PickMove(board, rack)
+}
+
+// pickMove returns the move of the current player's strategy, or nil if it
+// did not find one by the deadline of ctx and its grace. An error is returned
+// if ctx was cancelled, as the turn should not be taken at all.
+func (g *Game) pickMove(ctx context.Context, player *Player) (*Move, error) {
+	// a strategy left picking its move on an earlier turn may share a move
+	// generator with this one, so this one waits for it to finish
+	if g.picking != nil {
+		if err := waitForPick(ctx, g.picking); err == context.DeadlineExceeded {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		g.picking = nil
+	}
+	if _, ok := player.strategy.(ContextMovePicker); !ok && ctx.Done() != nil {
+		return g.pickMoveInBackground(ctx, player)
+	}
+
+	move := WithContext(player.strategy).PickMoveContext(ctx, g.board, *player.rack)
+	if err := ctx.Err(); err != nil && err != context.DeadlineExceeded {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().After(deadline.Add(DeadlineGrace)) {
+		return nil, nil
+	}
+	return move, nil
+}
+
+// pickMoveInBackground picks the move of a strategy which ignores contexts
+// on another goroutine, so that the turn does not wait for it past the
+// deadline of ctx and its grace. The strategy is given copies of the board
+// and rack, as it is left picking its move while the game goes on.
+func (g *Game) pickMoveInBackground(ctx context.Context, player *Player) (*Move, error) {
+	board, rack := g.board.Copy(), player.rack.Copy()
+	picked := make(chan struct{})
+	var move *Move
+	go func() {
+		defer close(picked)
+		move = player.strategy.PickMove(board, rack)
+	}()
+	if err := waitForPick(ctx, picked); err == context.DeadlineExceeded {
+		g.picking = picked
+		return nil, nil
+	} else if err != nil {
+		g.picking = picked
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil && err != context.DeadlineExceeded {
+		return nil, err
+	}
+	return move, nil
+}
+
+// waitForPick waits until picked is closed. It returns
+// context.DeadlineExceeded if the deadline of ctx and its grace pass first, or
+// the error of ctx if it is cancelled first.
+func waitForPick(ctx context.Context, picked <-chan struct{}) error {
+	var grace <-chan time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		timer := time.NewTimer(time.Until(deadline.Add(DeadlineGrace)))
+		defer timer.Stop()
+		grace = timer.C
+	}
+	done := ctx.Done()
+	for {
+		select {
+		case <-picked:
+			return nil
+		case <-grace:
+			return context.DeadlineExceeded
+		case <-done:
+			if err := ctx.Err(); err != context.DeadlineExceeded {
+				return err
+			}
+			// the move can still be played until the grace has passed
+			done = nil
+		}
+	}
+}
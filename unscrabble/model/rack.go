@@ -1,5 +1,14 @@
 package model
 
+import (
+	"fmt"
+	"math"
+	"unicode"
+)
+
+// BlankTile is the letter used to represent a blank tile
+const BlankTile = '*'
+
 // LetterGetter is for getting letters to fill the rack with
 type LetterGetter interface {
 	// GetLetter gets a letter, and should return an error if the getter does not have any letters
@@ -7,83 +16,217 @@ type LetterGetter interface {
 	HasLetter() bool
 }
 
+// MaxRackSize is the most tiles a rack can hold, as the rack keeps a byte for
+// the count of each letter. ParseRack, NewRackFromLetterCounts and Validate
+// reject larger racks.
+const MaxRackSize = math.MaxUint8
+
 // NewRack is for creating a new empty rack
 func NewRack(rackSize int) *Rack {
 	return &Rack{
-		letterCounts: map[rune]int{},
-		letterSet:    map[rune]bool{},
-		tileCount:    0,
-		capacity:     rackSize,
+		tileCount: 0,
+		capacity:  rackSize,
 	}
 }
 
 // The rack is an abstract data type which is essentially a multi-set.
-// However, the rack also maintains a traditional set based on the presence
-// of at least one count of the letter being present in the multiset.
-// This allows for efficient set operations to be calculated with other sets of
-// interest (e.g. cross-check sets and trie edge sets).
+// The multi-set is stored as a fixed-size array of counts indexed by each
+// letter's position in the alphabet, so a rack can be copied by value without
+// any allocations. This matters as the move generator copies the rack for
+// every prefix it considers.
+// The rack also maintains a LetterSet of the letters with at least one tile
+// on the rack. This allows for efficient set operations to be calculated with
+// other sets of interest (e.g. cross-check sets).
 type Rack struct {
-	letterCounts map[rune]int
-	letterSet    map[rune]bool
+	letterCounts [maxAlphabetSize]uint8
+	letterSet    LetterSet
 	tileCount    int
 	capacity     int
 }
 
 // Copy copies a rack
 func (r Rack) Copy() Rack {
-
-	copyLetterCounts := make(map[rune]int, len(r.letterCounts))
-	for key, value := range r.letterCounts {
-		copyLetterCounts[key] = value
-	}
-	copyLetterSet := make(map[rune]bool, len(r.letterSet))
-	for key, value := range r.letterSet {
-		copyLetterSet[key] = value
-	}
-
-	return Rack{
-		letterCounts: copyLetterCounts,
-		letterSet:    copyLetterSet,
-		tileCount:    r.tileCount,
-		capacity:     r.capacity,
-	}
+	return r
 }
 
-// AddLetter adds a new letter to the rack
+// AddLetter adds a new letter to the rack. It panics if the letter is not in the alphabet.
 func (rack *Rack) AddLetter(letter rune) {
-	rack.letterCounts[letter]++
+	index, ok := letterIndex(letter)
+	if !ok {
+		panic(fmt.Sprintf("tried to add %q to the rack but it's not in the alphabet!", letter))
+	}
+	rack.letterCounts[index]++
 	rack.tileCount++
-	rack.letterSet[letter] = true
+	rack.letterSet = rack.letterSet.With(letter)
 }
 
 // RemoveLetter removes an existing letter from the rack. It panics if the letter is not in the rack.
 func (rack *Rack) RemoveLetter(letter rune) {
-	if rack.letterCounts[letter] == 0 {
+	index, ok := letterIndex(letter)
+	if !ok || rack.letterCounts[index] == 0 {
 		panic("tried to remove a letter but it's not actually in the rack!")
 	}
-	rack.letterCounts[letter]--
+	rack.letterCounts[index]--
 	rack.tileCount--
-	if rack.letterCounts[letter] == 0 {
-		rack.letterSet[letter] = false
+	if rack.letterCounts[index] == 0 {
+		rack.letterSet = rack.letterSet.Without(letter)
 	}
 }
 
 // Contains tells us whether the rack contains a letter.
 // If the rack contains a blank tile, it contains all letters.
 func (rack *Rack) Contains(letter rune) bool {
-	return rack.letterSet[letter] || rack.letterCounts['*'] > 0
+	return rack.Playable().Contains(letter)
 }
 
 // Has tile asks if the rack actually contains the tile.
 // Wildcards are treated identically to all other tiles.
 func (rack *Rack) HasTile(letter rune) bool {
-	return rack.letterSet[letter]
+	return rack.letterSet.Contains(letter)
+}
+
+// Playable returns the set of letters that can be played from the rack.
+// If the rack contains a blank tile, every letter is playable.
+func (rack *Rack) Playable() LetterSet {
+	if rack.letterCounts[0] > 0 {
+		return UnconstrainedLetterSet
+	}
+	return rack.letterSet
 }
 
-// Fill fills the rack with tiles from a letterGetter
-func (rack *Rack) Fill(letterGetter LetterGetter) {
+// Fill fills the rack with tiles from a letterGetter and returns the tiles
+// that were drawn
+func (rack *Rack) Fill(letterGetter LetterGetter) []rune {
+	var drawn []rune
 	for rack.tileCount < rack.capacity && letterGetter.HasLetter() {
 		letter, _ := letterGetter.GetLetter()
 		rack.AddLetter(letter)
+		drawn = append(drawn, letter)
+	}
+	return drawn
+}
+
+// TileCount returns the number of tiles on the rack
+func (rack *Rack) TileCount() int {
+	return rack.tileCount
+}
+
+// Capacity returns the maximum number of tiles the rack can hold
+func (rack *Rack) Capacity() int {
+	return rack.capacity
+}
+
+// ParseRack creates a rack from notation such as "AEINRS?", where '?' or '*'
+// denotes a blank, using the English alphabet. See Alphabet.ParseRack.
+func ParseRack(notation string, rackSize int, letterCounts map[rune]int) (*Rack, error) {
+	return EnglishAlphabet.ParseRack(notation, rackSize, letterCounts)
+}
+
+// NewRackFromLetterCounts creates a rack from a multiset of letters. An error
+// is returned if the letters do not fit on the rack.
+func NewRackFromLetterCounts(rackSize int, letterCounts map[rune]int) (*Rack, error) {
+	tileCount := 0
+	for letter, count := range letterCounts {
+		if _, ok := letterIndex(letter); !ok {
+			return nil, fmt.Errorf("%q is not in the alphabet", letter)
+		}
+		tileCount += count
+	}
+	if err := checkFits(rackSize, tileCount); err != nil {
+		return nil, err
+	}
+	rack := NewRack(rackSize)
+	for letter, count := range letterCounts {
+		for i := 0; i < count; i++ {
+			rack.AddLetter(letter)
+		}
+	}
+	return rack, nil
+}
+
+// checkFits checks that a rack of rackSize tiles can hold tileCount tiles and
+// is no larger than MaxRackSize, so that the count of a letter cannot overflow
+func checkFits(rackSize, tileCount int) error {
+	if rackSize > MaxRackSize {
+		return fmt.Errorf("rack size must be at most %v but is %v", MaxRackSize, rackSize)
+	}
+	if tileCount > rackSize {
+		return fmt.Errorf("rack has %v tiles but can only hold %v", tileCount, rackSize)
+	}
+	return nil
+}
+
+// Validate checks that the rack does not hold more tiles than its capacity,
+// and that its capacity is no more than MaxRackSize.
+// If letterCounts is not nil, it also checks that every letter on the rack is
+// in the letter distribution and does not exceed its count.
+func (rack *Rack) Validate(letterCounts map[rune]int) error {
+	if err := checkFits(rack.capacity, rack.tileCount); err != nil {
+		return err
+	}
+	if letterCounts == nil {
+		return nil
+	}
+	for letter, count := range rack.LetterCounts() {
+		available, ok := letterCounts[letter]
+		if !ok {
+			return fmt.Errorf("%q is not in the alphabet", letter)
+		}
+		if count > available {
+			return fmt.Errorf(
+				"rack has %v %q tiles but there are only %v", count, letter, available,
+			)
+		}
+	}
+	return nil
+}
+
+// LetterCounts returns the rack as a multiset of letters.
+func (rack *Rack) LetterCounts() map[rune]int {
+	letterCounts := make(map[rune]int)
+	for index, count := range rack.letterCounts {
+		if count > 0 {
+			letterCounts[indexLetter(index)] = int(count)
+		}
+	}
+	return letterCounts
+}
+
+// String returns the canonical form of the rack: upper case letters in sorted
+// order followed by any blanks as '?'. Racks holding the same tiles always have
+// the same string, so it can be used as a key for leave tables and caches.
+func (rack *Rack) String() string {
+	letters := make([]rune, 0, rack.tileCount)
+	for index := 1; index < maxAlphabetSize; index++ {
+		for i := 0; i < int(rack.letterCounts[index]); i++ {
+			letters = append(letters, unicode.ToUpper(indexLetter(index)))
+		}
+	}
+	for i := 0; i < int(rack.letterCounts[0]); i++ {
+		letters = append(letters, '?')
+	}
+	return string(letters)
+}
+
+// Letters returns the tiles on the rack in the same order as String
+func (rack *Rack) Letters() []rune {
+	letters := make([]rune, 0, rack.tileCount)
+	for index := 1; index < maxAlphabetSize; index++ {
+		for i := 0; i < int(rack.letterCounts[index]); i++ {
+			letters = append(letters, indexLetter(index))
+		}
+	}
+	for i := 0; i < int(rack.letterCounts[0]); i++ {
+		letters = append(letters, BlankTile)
+	}
+	return letters
+}
+
+// Score returns the total score of the tiles on the rack
+func (rack *Rack) Score(letterScores map[rune]int) int {
+	score := 0
+	for index, count := range rack.letterCounts {
+		score += letterScores[indexLetter(index)] * int(count)
 	}
+	return score
 }
@@ -0,0 +1,115 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionsReplayGameExactly(t *testing.T) {
+	config := model.Configuration{
+		RackSize:          3,
+		BoardSize:         7,
+		ChallengeRule:     model.DoubleChallenge,
+		LetterScores:      map[string]int{"a": 1, "c": 3, "s": 1, "t": 1, "?": 0},
+		LetterCounts:      map[string]int{"a": 6, "c": 3, "s": 3, "t": 6, "?": 1},
+		LetterMultipliers: newMultipliers(7, 7),
+		WordMultipliers:   newMultipliers(7, 7),
+	}
+	require.Empty(t, config.Validate())
+	trie := newTestLexicon("act", "acts", "as", "at", "cat", "cats", "sat", "scat", "ta", "tas", "tat", "tats")
+	moveGenerator := triemovegen.NewTrieMoveGenertator(trie)
+	players := []*model.Player{
+		model.NewPlayer("A", strategy.NewHighScoreStrategy(&moveGenerator)),
+		model.NewPlayer("B", alwaysChallenge{strategy.NewHighScoreStrategy(&moveGenerator)}),
+	}
+	game, err := model.NewGame(config, trie, players...)
+	require.NoError(t, err)
+	initial := game.JSON(model.AllPlayers)
+	require.NoError(t, game.SetRack(0, 'a', 'c', 't'))
+
+	// the first turns are taken by hand, so that there is always a play that
+	// is challenged whatever tiles are drawn
+	require.NoError(t, game.Exchange('c'))
+	require.NoError(t, game.Pass())
+	require.NoError(t, game.PlayMove(newMove(3, 2, true, "at")))
+	require.NoError(t, game.Challenge())
+	_, err = game.Play()
+	require.NoError(t, err)
+
+	actions := game.Actions()
+	require.NotEmpty(t, actions)
+	assert.Equal(t, model.Action{Type: model.SetRackAction, Player: 0, Tiles: []rune{'a', 'c', 't'}}, actions[0])
+	assert.Equal(t, model.Action{Type: model.PassAction, Player: 1}, actions[2])
+	types := make(map[model.ActionType]bool)
+	for _, action := range actions {
+		types[action.Type] = true
+	}
+	assert.True(t, types[model.PlayAction])
+	assert.True(t, types[model.ChallengeAction])
+
+	alphabet, err := config.NewAlphabet()
+	require.NoError(t, err)
+	replay, err := model.RestoreGame(initial, trie, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	for i, action := range actions {
+		decoded, err := alphabet.DecodeAction(alphabet.EncodeAction(action))
+		require.NoError(t, err)
+		require.NoError(t, replay.Apply(decoded), "action %v", i)
+	}
+	assert.Equal(t, game.JSON(model.AllPlayers), replay.JSON(model.AllPlayers))
+	assert.Len(t, replay.Actions(), len(actions))
+}
+
+func TestPlayMoveDrawingChecksTilesDrawn(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "*": 0}
+	config.LetterCounts = map[string]int{"a": 4, "b": 2, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	require.NoError(t, game.SetRack(0, 'a', 'a'))
+	require.NoError(t, game.SetRack(1, 'a', 'a'))
+
+	move := newMove(1, 0, true, "aa")
+	assert.EqualError(t, game.PlayMoveDrawing(move, []rune{'b'}), "the play draws 2 tiles but 1 were given")
+	assert.EqualError(t, game.PlayMoveDrawing(move, []rune{'a', 'b'}), `bag does not contain enough 'a' tiles`)
+	require.NoError(t, game.PlayMoveDrawing(move, []rune{'b', 'b'}))
+	rack := game.Players()[0].Rack()
+	assert.Equal(t, "BB", rack.String())
+	assert.Equal(t, 0, game.TilesInBag())
+	assert.Equal(t, []model.Action{
+		{Type: model.SetRackAction, Player: 0, Tiles: []rune{'a', 'a'}},
+		{Type: model.SetRackAction, Player: 1, Tiles: []rune{'a', 'a'}},
+		{Type: model.PlayAction, Player: 0, Move: game.Record()[0].Move, Drawn: []rune{'b', 'b'}},
+	}, game.Actions())
+}
+
+func TestSetRackIsReplayedExactly(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "*": 0}
+	config.LetterCounts = map[string]int{"a": 3, "b": 2, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	initial := game.JSON(model.AllPlayers)
+
+	// A's rack is given both of B's tiles, and B draws two tiles for them
+	require.NoError(t, game.SetRack(1, 'b', 'b'))
+	require.NoError(t, game.SetRack(0, 'b', 'b'))
+	actions := game.Actions()
+	require.Len(t, actions, 2)
+	assert.Equal(t, model.Action{Type: model.SetRackAction, Player: 0, Tiles: []rune{'b', 'b'}, Drawn: []rune{'a', 'a'}}, actions[1])
+
+	replay, err := model.RestoreGame(initial, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	for i, action := range actions {
+		require.NoError(t, replay.Apply(action), "action %v", i)
+	}
+	assert.Equal(t, game.JSON(model.AllPlayers), replay.JSON(model.AllPlayers))
+
+	assert.EqualError(t, replay.SetRackDrawing(0, []rune{'a'}, []rune{'a'}), "the rack exchanges 0 tiles but 1 were given")
+	assert.EqualError(t, replay.SetRackDrawing(1, []rune{'b'}, []rune{'b'}), `bag does not contain enough 'b' tiles`)
+}
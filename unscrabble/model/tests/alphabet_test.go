@@ -0,0 +1,121 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var spanishSymbols = []string{
+	"a", "b", "c", "ch", "d", "e", "f", "g", "h", "i", "j", "l", "ll", "m",
+	"n", "ñ", "o", "p", "q", "r", "rr", "s", "t", "u", "v", "x", "y", "z",
+}
+
+func TestNewAlphabetKeepsLettersFromAToZ(t *testing.T) {
+	alphabet, err := model.NewAlphabet([]string{"a", "B", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, []rune{'a', 'b', 'c'}, alphabet.Letters())
+	assert.Equal(t, 3, alphabet.Size())
+
+	letter, ok := alphabet.Letter("B")
+	assert.True(t, ok)
+	assert.Equal(t, 'b', letter)
+}
+
+func TestNewAlphabetReturnsErrorForInvalidSymbols(t *testing.T) {
+	for _, symbols := range [][]string{
+		{"a", "a"},
+		{"a", ""},
+		{"a?"},
+	} {
+		_, err := model.NewAlphabet(symbols)
+		assert.Error(t, err, "%v", symbols)
+	}
+
+	tooManySymbols := make([]string, 40)
+	for i := range tooManySymbols {
+		tooManySymbols[i] = string(rune('α' + i))
+	}
+	_, err := model.NewAlphabet(tooManySymbols)
+	assert.Error(t, err)
+}
+
+func TestTokeniseSplitsWordsIntoTiles(t *testing.T) {
+	alphabet, err := model.NewAlphabet(spanishSymbols)
+	require.NoError(t, err)
+	ch, _ := alphabet.Letter("ch")
+	rr, _ := alphabet.Letter("rr")
+	ñ, _ := alphabet.Letter("ñ")
+
+	t.Run("multi-character tiles", func(t *testing.T) {
+		tiles, err := alphabet.Tokenise("CHURRO")
+		require.NoError(t, err)
+		assert.Equal(t, string([]rune{ch, 'u', rr, 'o'}), tiles)
+		assert.Equal(t, "churro", alphabet.Render(tiles))
+	})
+	t.Run("non-ascii tiles", func(t *testing.T) {
+		tiles, err := alphabet.Tokenise("niño")
+		require.NoError(t, err)
+		assert.Equal(t, string([]rune{'n', 'i', ñ, 'o'}), tiles)
+	})
+	t.Run("brackets override greedy split", func(t *testing.T) {
+		tiles, err := alphabet.Tokenise("[c]h")
+		require.NoError(t, err)
+		assert.Equal(t, "ch", tiles)
+	})
+	t.Run("letters outside alphabet", func(t *testing.T) {
+		_, err := alphabet.Tokenise("kiwi")
+		assert.Error(t, err)
+	})
+	t.Run("unclosed bracket", func(t *testing.T) {
+		_, err := alphabet.Tokenise("[ch")
+		assert.Error(t, err)
+	})
+}
+
+func TestAlphabetParseRackAndFormatRackRoundTrip(t *testing.T) {
+	alphabet, err := model.NewAlphabet(spanishSymbols)
+	require.NoError(t, err)
+	ch, _ := alphabet.Letter("ch")
+	ñ, _ := alphabet.Letter("ñ")
+
+	rack, err := alphabet.ParseRack("Ñ[CH]a?E", 7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]int{'a': 1, 'e': 1, ch: 1, ñ: 1, model.BlankTile: 1}, rack.LetterCounts())
+	assert.Equal(t, "A[CH]EÑ?", alphabet.FormatRack(rack))
+
+	parsed, err := alphabet.ParseRack(alphabet.FormatRack(rack), 7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rack.String(), parsed.String())
+}
+
+func TestLetterMapConvertsSymbolsToLetters(t *testing.T) {
+	alphabet, err := model.NewAlphabet(spanishSymbols)
+	require.NoError(t, err)
+	ll, _ := alphabet.Letter("ll")
+
+	letterMap, err := alphabet.LetterMap(map[string]int{"a": 1, "LL": 8, "*": 0})
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]int{'a': 1, ll: 8, model.BlankTile: 0}, letterMap)
+
+	_, err = alphabet.LetterMap(map[string]int{"k": 5})
+	assert.Error(t, err)
+}
+
+func TestAlphabetParseWordAndFormatWordRoundTrip(t *testing.T) {
+	alphabet, err := model.NewAlphabet(spanishSymbols)
+	require.NoError(t, err)
+
+	word, err := alphabet.ParseWord("CHiCO")
+	require.NoError(t, err)
+	assert.Equal(t, 4, word.Length())
+	assert.Equal(t, []bool{false, true, false, false}, word.BlankTiles)
+	assert.Equal(t, "[CH]iCO", alphabet.FormatWord(word))
+
+	word, err = alphabet.ParseWord("[ch]ICO")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false, false}, word.BlankTiles)
+	assert.Equal(t, "[ch]ICO", alphabet.FormatWord(word))
+}
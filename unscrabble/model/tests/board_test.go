@@ -0,0 +1,173 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultipliers(rows, columns int) [][]int {
+	multipliers := make([][]int, rows)
+	for y := range multipliers {
+		multipliers[y] = make([]int, columns)
+		for x := range multipliers[y] {
+			multipliers[y][x] = 1
+		}
+	}
+	return multipliers
+}
+
+func TestNewBoardCreatesRectangularBoard(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(3, 5), newMultipliers(3, 5))
+
+	assert.Equal(t, 3, board.Rows())
+	assert.Equal(t, 5, board.Columns())
+	assert.Equal(t, model.Position{Row: 1, Column: 2}, board.Centre())
+	assert.Equal(t, &model.Position{Row: 2, Column: 4}, board.Tiles[2][4].BoardPosition)
+	for y, row := range board.Tiles {
+		for x, tile := range row {
+			assert.Equal(t, y == 1 && x == 2, tile.IsAnchor, "(%v, %v)", y, x)
+		}
+	}
+}
+
+func TestNewBoardUsesStartSquares(t *testing.T) {
+	board := model.NewBoard(
+		nil,
+		newMultipliers(3, 5),
+		newMultipliers(3, 5),
+		model.Position{Row: 0, Column: 0},
+		model.Position{Row: 2, Column: 4},
+	)
+
+	assert.True(t, board.Tiles[0][0].IsAnchor)
+	assert.True(t, board.Tiles[2][4].IsAnchor)
+	assert.False(t, board.Tiles[1][2].IsAnchor)
+}
+
+func TestTransposeTransposesRectangularBoard(t *testing.T) {
+	wordMultipliers := [][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+	}
+	board := model.NewBoard(nil, wordMultipliers, newMultipliers(2, 3))
+
+	board.Transpose()
+	require.Equal(t, 3, board.Rows())
+	require.Equal(t, 2, board.Columns())
+	for y, row := range board.Tiles {
+		for x, tile := range row {
+			assert.Equal(t, wordMultipliers[x][y], tile.WordMultiplier)
+			assert.Equal(t, &model.Position{Row: y, Column: x}, tile.BoardPosition)
+		}
+	}
+
+	board.Transpose()
+	require.Equal(t, 2, board.Rows())
+	for y, row := range board.Tiles {
+		for x, tile := range row {
+			assert.Equal(t, wordMultipliers[y][x], tile.WordMultiplier)
+			assert.Equal(t, &model.Position{Row: y, Column: x}, tile.BoardPosition)
+		}
+	}
+}
+
+func TestGetAdjacentTileReturnsNilOffTheBoard(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(2, 3), newMultipliers(2, 3))
+
+	assert.Same(t, board.Tiles[1][2], board.Tiles[0][2].GetAdjacentTile(board, 1, 0))
+	assert.Nil(t, board.Tiles[1][2].GetAdjacentTile(board, 1, 0))
+	assert.Nil(t, board.Tiles[0][2].GetAdjacentTile(board, 0, 1))
+	assert.Nil(t, board.Tiles[0][0].GetAdjacentTile(board, -1, 0))
+
+	sentinel := board.Tiles[1][2].GetAdjacentTileOrSentinel(board, 1, 0)
+	assert.True(t, sentinel.Empty())
+	assert.Equal(t, model.EmptyLetterSet, sentinel.CrossCheckSet)
+}
+
+func TestGetAnchorsFindsTilesNextToLettersOnRectangularBoard(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(2, 4), newMultipliers(2, 4))
+	board.Tiles[1][3].Letter = 'a'
+
+	anchors := model.GetAnchors(board)
+	assert.ElementsMatch(t, []*model.Tile{board.Tiles[0][3], board.Tiles[1][2]}, anchors)
+}
+
+func TestNewBoardWithRulesAnchorsEveryTileIfFirstMoveCanBeAnywhere(t *testing.T) {
+	board := model.NewBoardWithRules(
+		nil,
+		newMultipliers(2, 3),
+		newMultipliers(2, 3),
+		model.FirstMoveRules{Anywhere: true, MinWordLength: 3},
+	)
+
+	for _, row := range board.Tiles {
+		for _, tile := range row {
+			assert.True(t, tile.IsAnchor)
+		}
+	}
+	assert.Equal(t, 3, board.MinWordLength())
+}
+
+func TestPlaceMoveUpdatesAnchorsAndCrossChecks(t *testing.T) {
+	trie := lexicon.NewTrieNode()
+	for _, word := range []string{"cat", "at", "ta", "tat"} {
+		trie.Insert(word)
+	}
+	wordMultipliers := newMultipliers(3, 5)
+	wordMultipliers[1][1] = 2
+	board := model.NewBoardWithRules(
+		trie,
+		wordMultipliers,
+		newMultipliers(3, 5),
+		model.FirstMoveRules{MinWordLength: 2},
+	)
+	letterScores := map[rune]int{'c': 3, 'a': 1, 't': 1}
+
+	move := model.Move{
+		StartPosition: &model.Position{Row: 1, Column: 1},
+		Horizontal:    true,
+		Word:          model.Word{Chars: "cat", BlankTiles: []bool{false, false, true}},
+	}
+	require.NoError(t, board.PlaceMove(move, letterScores))
+
+	assert.False(t, board.IsEmpty())
+	assert.Equal(t, 0, board.MinWordLength())
+	assert.Equal(t, 1, board.Tiles[1][1].WordMultiplier)
+	assert.True(t, board.Tiles[1][3].IsBlank())
+	assert.False(t, board.Tiles[1][2].IsBlank())
+
+	anchors := []*model.Tile{}
+	for _, row := range board.Tiles {
+		for _, tile := range row {
+			if tile.IsAnchor {
+				anchors = append(anchors, tile)
+			}
+		}
+	}
+	assert.ElementsMatch(t, model.GetAnchors(board), anchors)
+
+	// only "ta" and "at" can be formed vertically with the 'a'
+	assert.Equal(t, model.NewLetterSet('t'), board.Tiles[0][2].CrossCheckSet)
+	assert.Equal(t, 1, board.Tiles[0][2].CrossScore)
+	assert.Equal(t, model.NewLetterSet('t'), board.Tiles[2][2].CrossCheckSet)
+	// the blank 't' scores nothing in cross words
+	assert.Equal(t, 0, board.Tiles[0][3].CrossScore)
+	assert.True(t, board.Tiles[0][0].CrossCheckSet.IsUnconstrained())
+
+	// "tat" can be made by extending the word
+	vertical := model.Move{StartPosition: &model.Position{Row: 0, Column: 2}, Word: model.Word{Chars: "tat", BlankTiles: make([]bool, 3)}}
+	score, err := vertical.CalculateScore(board, letterScores, 7, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 3, score)
+
+	err = board.PlaceMove(model.Move{
+		StartPosition: &model.Position{Row: 1, Column: 0},
+		Horizontal:    true,
+		Word:          model.Word{Chars: "tat", BlankTiles: make([]bool, 3)},
+	}, letterScores)
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBoard(t *testing.T) {
+	wordMultipliers := newMultipliers(3, 5)
+	letterMultipliers := newMultipliers(3, 5)
+	wordMultipliers[0][0], wordMultipliers[1][1] = 3, 2
+	letterMultipliers[0][2], letterMultipliers[2][0] = 2, 3
+	board := model.NewBoard(nil, wordMultipliers, letterMultipliers)
+	move := newMove(1, 2, true, "cat")
+	move.Word.BlankTiles[2] = true
+	require.NoError(t, board.PlaceMove(move, nil))
+
+	expected := "" +
+		"   A B C D E\n" +
+		"1  = . ' . .\n" +
+		"2  . - C A t\n" +
+		"3  \" . . . .\n"
+	assert.Equal(t, expected, model.EnglishAlphabet.FormatBoard(board))
+}
+
+func TestParseBoardRoundTrip(t *testing.T) {
+	text := "" +
+		"   A B C D E\n" +
+		"1  = . ' . .\n" +
+		"2  . - C A t\n" +
+		"3  \" . . . .\n"
+	board, err := model.EnglishAlphabet.ParseBoard(text, nil, map[rune]int{'c': 3, 'a': 1, 't': 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, text, model.EnglishAlphabet.FormatBoard(board))
+	assert.Equal(t, 3, board.Tiles[0][0].WordMultiplier)
+	assert.Equal(t, 2, board.Tiles[0][2].LetterMultiplier)
+	assert.Equal(t, 'c', board.Tiles[1][2].Letter)
+	assert.True(t, board.Tiles[1][4].IsBlank())
+	assert.False(t, board.IsEmpty())
+	assert.True(t, board.Tiles[0][2].IsAnchor)
+	assert.False(t, board.Tiles[1][3].IsAnchor)
+	assert.Equal(t, 0, board.MinWordLength())
+}
+
+func TestParseBoardWithoutLabels(t *testing.T) {
+	board, err := model.EnglishAlphabet.ParseBoard("\n. . .\n. - .\n. . .\n", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, board.Rows())
+	assert.Equal(t, 3, board.Columns())
+	assert.True(t, board.IsEmpty())
+	assert.True(t, board.Tiles[1][1].IsAnchor)
+	assert.False(t, board.Tiles[0][0].IsAnchor)
+}
+
+func TestParseBoardErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		". . .\n. .\n",
+		". ? .\n",
+		". AB .\n",
+	} {
+		_, err := model.EnglishAlphabet.ParseBoard(text, nil, nil)
+		assert.Error(t, err, text)
+	}
+}
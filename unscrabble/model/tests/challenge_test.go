@@ -0,0 +1,141 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChallengeGame returns a game in which each player has the rack "AA" and
+// there are tiles left in the bag, so playing "aa" does not go out
+func newChallengeGame(t *testing.T, rule model.ChallengeRule, words ...string) *model.Game {
+	config := newTestConfiguration()
+	config.LetterCounts = map[string]int{"a": 8, "*": 0}
+	config.ChallengeRule = rule
+	require.Empty(t, config.Validate())
+	game, err := model.NewGame(config, newTestLexicon(words...), model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	return game
+}
+
+func TestVoidChallengeRejectsPhonies(t *testing.T) {
+	game := newChallengeGame(t, model.VoidChallenge)
+
+	err := game.PlayMove(newMove(1, 0, true, "aa"))
+	var phonyErr *model.PhonyError
+	require.ErrorAs(t, err, &phonyErr)
+	assert.Equal(t, 0, game.CurrentPlayer())
+	_, ok := game.ChallengeablePlay()
+	assert.False(t, ok)
+}
+
+func TestSuccessfulChallengeWithdrawsPhony(t *testing.T) {
+	game := newChallengeGame(t, model.DoubleChallenge)
+
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	assert.Equal(t, 'a', game.Board().Tiles[1][0].Letter)
+	assert.Equal(t, 2, game.TilesInBag())
+	move, ok := game.ChallengeablePlay()
+	require.True(t, ok)
+	assert.Equal(t, 2, move.Score)
+
+	require.NoError(t, game.Challenge())
+	assert.True(t, game.Board().Tiles[1][0].Empty())
+	assert.True(t, game.Board().Tiles[1][1].IsAnchor)
+	assert.Equal(t, 4, game.TilesInBag())
+	rack := game.Players()[0].Rack()
+	assert.Equal(t, "AA", rack.String())
+	assert.Equal(t, 0, game.Players()[0].Score())
+	assert.Equal(t, 1, game.CurrentPlayer())
+
+	record := game.Record()
+	require.Len(t, record, 2)
+	assert.Equal(t, model.PhonyWithdrawnTurn, record[1].Type)
+	assert.Equal(t, -2, record[1].Score)
+	assert.Equal(t, 0, record[1].Total)
+
+	assert.Error(t, game.Challenge())
+}
+
+func TestFailedChallengePenalties(t *testing.T) {
+	testCases := []struct {
+		rule            model.ChallengeRule
+		expectedScore   int
+		expectedPlayer  int
+		expectedRecords []model.TurnType
+	}{
+		{model.DoubleChallenge, 2, 0, []model.TurnType{model.PlayTurn, model.FailedChallengeTurn}},
+		{model.SingleChallenge, 2, 1, []model.TurnType{model.PlayTurn}},
+		{model.FivePointChallenge, 7, 1, []model.TurnType{model.PlayTurn, model.ChallengeBonusTurn}},
+		{model.TenPointChallenge, 12, 1, []model.TurnType{model.PlayTurn, model.ChallengeBonusTurn}},
+	}
+	for _, testCase := range testCases {
+		t.Run(string(testCase.rule), func(t *testing.T) {
+			game := newChallengeGame(t, testCase.rule, "aa")
+			require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+
+			require.NoError(t, game.Challenge())
+			assert.Equal(t, 'a', game.Board().Tiles[1][0].Letter)
+			assert.Equal(t, testCase.expectedScore, game.Players()[0].Score())
+			assert.Equal(t, testCase.expectedPlayer, game.CurrentPlayer())
+			var turnTypes []model.TurnType
+			for _, turn := range game.Record() {
+				turnTypes = append(turnTypes, turn.Type)
+			}
+			assert.Equal(t, testCase.expectedRecords, turnTypes)
+		})
+	}
+}
+
+func TestPlayThatGoesOutWaitsForChallenge(t *testing.T) {
+	config := newTestConfiguration()
+	config.ChallengeRule = model.DoubleChallenge
+	game, err := model.NewGame(config, newTestLexicon("aa"), model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	assert.False(t, game.IsOver())
+	assert.Equal(t, model.ErrPlayAwaitingChallenge, game.Pass())
+
+	require.NoError(t, game.AcceptPlay())
+	assert.True(t, game.IsOver())
+	assert.Equal(t, 6, game.Players()[0].Score())
+}
+
+type alwaysChallenge struct {
+	model.MovePicker
+}
+
+func (alwaysChallenge) ShouldChallenge(board model.Board, move model.Move) bool {
+	return true
+}
+
+type noMoves struct{}
+
+func (noMoves) PickMove(board model.Board, rack model.Rack) *model.Move {
+	return nil
+}
+
+func TestPlayTurnChallengesForChallengers(t *testing.T) {
+	config := newTestConfiguration()
+	config.ChallengeRule = model.SingleChallenge
+	players := []*model.Player{
+		model.NewPlayer("A", nil),
+		model.NewPlayer("B", alwaysChallenge{noMoves{}}),
+	}
+	game, err := model.NewGame(config, newTestLexicon(), players...)
+	require.NoError(t, err)
+
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	require.NoError(t, game.PlayTurn())
+
+	// the phony is withdrawn and B passes as there are no tiles to exchange
+	record := game.Record()
+	require.Len(t, record, 3)
+	assert.Equal(t, model.PhonyWithdrawnTurn, record[1].Type)
+	assert.Equal(t, model.PassTurn, record[2].Type)
+	assert.Equal(t, 0, game.CurrentPlayer())
+	assert.False(t, game.IsOver())
+}
@@ -0,0 +1,159 @@
+package model_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadConfiguration(t *testing.T, name string) model.Configuration {
+	config, err := model.LoadConfiguration(filepath.Join("..", "..", "data", name+".yaml"))
+	require.NoError(t, err)
+	return config
+}
+
+func TestLanguageConfigurationsConvertToLetters(t *testing.T) {
+	for name, expectedTiles := range map[string]int{
+		"words_with_friends": 52,
+		"spanish":            100,
+		"catalan":            100,
+		"german":             102,
+		"french":             102,
+		"polish":             100,
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := loadConfiguration(t, name)
+			alphabet, err := config.NewAlphabet()
+			require.NoError(t, err)
+
+			letterScores, err := alphabet.LetterMap(config.LetterScores)
+			require.NoError(t, err)
+			letterCounts, err := alphabet.LetterMap(config.LetterCounts)
+			require.NoError(t, err)
+
+			assert.Len(t, letterScores, alphabet.Size())
+			tiles := 0
+			for letter, count := range letterCounts {
+				if letter != model.BlankTile {
+					assert.Contains(t, letterScores, letter)
+				}
+				tiles += count
+			}
+			assert.Equal(t, expectedTiles, tiles)
+		})
+	}
+}
+
+func TestParseConfigurationRejectsUnknownKeys(t *testing.T) {
+	_, err := model.ParseConfiguration([]byte("rack_size: 7\nrack_sise: 8\n"))
+	assert.Error(t, err)
+}
+
+func TestValidateRejectsRacksLargerThanMaxRackSize(t *testing.T) {
+	config := loadConfiguration(t, "words_with_friends")
+	config.RackSize = model.MaxRackSize + 1
+	assert.Equal(t, []error{
+		fmt.Errorf("rack_size must be at most %v but is %v", model.MaxRackSize, model.MaxRackSize+1),
+	}, config.Validate())
+}
+
+func TestValidateReturnsAllErrors(t *testing.T) {
+	config := model.Configuration{
+		RackSize:     0,
+		BingoPremium: 50,
+		BoardSize:    3,
+		LetterScores: map[string]int{"a": 1, "b": -2},
+		LetterCounts: map[string]int{"a": 2, "c": 1},
+		LetterMultipliers: [][]int{
+			{1, 1, 1},
+			{1, 0, 1},
+			{1, 1, 1},
+		},
+		WordMultipliers: [][]int{
+			{1, 1, 1},
+			{1, 1},
+		},
+	}
+
+	var messages []string
+	for _, err := range config.Validate() {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"rack_size must be positive but is 0",
+		"letter_multipliers row 2 column 2 must be positive but is 0",
+		"word_multipliers has 2 rows but the board has 3 rows",
+		"word_multipliers row 2 has 2 columns but the board has 3 columns",
+		"letter_multipliers has 3 rows but word_multipliers has 2 rows",
+		`letter_counts has "c" but letter_scores does not have a score for it`,
+		`letter_counts has "c" but it is not in the alphabet`,
+		`letter_counts does not declare the blank tile ('*')`,
+		`letter_scores has a negative score for "b"`,
+	}, messages)
+}
+
+func TestValidateAcceptsRectangularBoards(t *testing.T) {
+	config := model.Configuration{
+		RackSize:          7,
+		BoardRows:         2,
+		BoardColumns:      3,
+		StartSquares:      []model.Position{{Row: 0, Column: 2}},
+		LetterScores:      map[string]int{"a": 1},
+		LetterCounts:      map[string]int{"a": 2, "*": 1},
+		LetterMultipliers: [][]int{{1, 1, 1}, {1, 1, 1}},
+		WordMultipliers:   [][]int{{1, 1, 1}, {1, 1, 2}},
+	}
+	assert.Empty(t, config.Validate())
+
+	board, err := config.NewBoard(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, board.Rows())
+	assert.Equal(t, 3, board.Columns())
+	assert.True(t, board.Tiles[0][2].IsAnchor)
+	assert.False(t, board.Tiles[1][1].IsAnchor)
+
+	config.StartSquares = []model.Position{{Row: 2, Column: 0}}
+	assert.Len(t, config.Validate(), 1)
+
+	config.StartSquares = nil
+	config.BoardColumns = 2
+	assert.Len(t, config.Validate(), 4)
+}
+
+func TestConfigurationPlacesInitialWords(t *testing.T) {
+	config, err := model.ParseConfiguration([]byte(`
+rack_size: 7
+board_size: 5
+first_move_anywhere: true
+min_first_word_length: 3
+initial_words:
+  - {row: 2, column: 1, horizontal: true, word: cat}
+  - {row: 1, column: 3, word: at}
+letter_scores: {a: 1, c: 3, t: 1}
+letter_counts: {a: 2, c: 1, t: 2, "*": 1}
+letter_multipliers: [[1, 1, 1, 1, 1], [1, 1, 1, 1, 1], [1, 1, 1, 1, 1], [1, 1, 1, 1, 1], [1, 1, 1, 1, 1]]
+word_multipliers: [[1, 1, 1, 1, 1], [1, 1, 1, 1, 1], [1, 1, 2, 1, 1], [1, 1, 1, 1, 1], [1, 1, 1, 1, 1]]
+`))
+	require.NoError(t, err)
+	require.Empty(t, config.Validate())
+
+	board, err := config.NewBoard(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 'c', board.Tiles[2][1].Letter)
+	assert.Equal(t, 'a', board.Tiles[1][3].Letter)
+	assert.Equal(t, 1, board.Tiles[2][2].WordMultiplier)
+	assert.True(t, board.Tiles[1][2].IsAnchor)
+	assert.False(t, board.Tiles[0][0].IsAnchor)
+	assert.Equal(t, 0, board.MinWordLength())
+
+	config.InitialWords = append(config.InitialWords, model.InitialWord{
+		Position:   model.Position{Row: 2, Column: 0},
+		Horizontal: true,
+		Word:       "cat",
+	})
+	assert.Len(t, config.Validate(), 1)
+}
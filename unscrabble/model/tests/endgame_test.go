@@ -0,0 +1,79 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseRacks(t *testing.T, notations ...string) []model.Rack {
+	var racks []model.Rack
+	for _, notation := range notations {
+		rack, err := model.ParseRack(notation, 7, nil)
+		require.NoError(t, err)
+		racks = append(racks, *rack)
+	}
+	return racks
+}
+
+func TestEndGameRulesAdjustments(t *testing.T) {
+	letterScores := map[rune]int{'a': 1, 'q': 10, 'z': 10}
+	racks := parseRacks(t, "", "AQ", "Z?")
+
+	testCases := []struct {
+		name     string
+		rules    model.EndGameRules
+		goneOut  int
+		expected []model.Turn
+	}{
+		{
+			name:    "double",
+			rules:   model.EndGameRules{},
+			goneOut: 0,
+			expected: []model.Turn{
+				{Player: 0, Type: model.EndRackBonusTurn, Rack: racks[1], Score: 22},
+				{Player: 0, Type: model.EndRackBonusTurn, Rack: racks[2], Score: 20},
+			},
+		},
+		{
+			name:    "single",
+			rules:   model.EndGameRules{GoingOutBonus: model.SingleRackBonus},
+			goneOut: 0,
+			expected: []model.Turn{
+				{Player: 1, Type: model.EndRackPenaltyTurn, Rack: racks[1], Score: -11},
+				{Player: 0, Type: model.EndRackBonusTurn, Rack: racks[1], Score: 11},
+				{Player: 2, Type: model.EndRackPenaltyTurn, Rack: racks[2], Score: -10},
+				{Player: 0, Type: model.EndRackBonusTurn, Rack: racks[2], Score: 10},
+			},
+		},
+		{
+			name:    "scoreless turns",
+			rules:   model.EndGameRules{},
+			goneOut: -1,
+			expected: []model.Turn{
+				{Player: 0, Type: model.EndRackPenaltyTurn, Rack: racks[0], Score: 0},
+				{Player: 1, Type: model.EndRackPenaltyTurn, Rack: racks[1], Score: -11},
+				{Player: 2, Type: model.EndRackPenaltyTurn, Rack: racks[2], Score: -10},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			adjustments := testCase.rules.Adjustments(racks, testCase.goneOut, letterScores)
+			assert.Equal(t, testCase.expected, adjustments)
+		})
+	}
+}
+
+func TestEndGameRulesWinners(t *testing.T) {
+	finalScores := []int{300, 310, 310}
+	scoresBeforeAdjustments := []int{300, 320, 305}
+
+	assert.Equal(t, []int{1, 2}, model.EndGameRules{}.Winners(finalScores, scoresBeforeAdjustments))
+
+	rules := model.EndGameRules{Tiebreak: model.TiesBrokenByScoreBeforeAdjustments}
+	assert.Equal(t, []int{1}, rules.Winners(finalScores, scoresBeforeAdjustments))
+	assert.Equal(t, []int{1}, rules.Winners([]int{1, 2, 0}, []int{5, 0, 0}))
+}
@@ -0,0 +1,213 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestConfiguration returns the configuration of a small game in which the
+// bag is empty once two players have been dealt their racks, so every rack is
+// known in advance.
+func newTestConfiguration() model.Configuration {
+	return model.Configuration{
+		RackSize:          2,
+		BoardSize:         3,
+		LetterScores:      map[string]int{"a": 1, "*": 0},
+		LetterCounts:      map[string]int{"a": 4, "*": 0},
+		LetterMultipliers: newMultipliers(3, 3),
+		WordMultipliers:   newMultipliers(3, 3),
+	}
+}
+
+func newTestLexicon(words ...string) *lexicon.TrieNode {
+	trie := lexicon.NewTrieNode()
+	for _, word := range words {
+		trie.Insert(word)
+	}
+	return trie
+}
+
+func TestGameEndsWhenPlayerGoesOut(t *testing.T) {
+	config := newTestConfiguration()
+	require.Empty(t, config.Validate())
+	game, err := model.NewGame(config, newTestLexicon("aa"), model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 0, game.TilesInBag())
+
+	err = game.PlayMove(newMove(0, 0, true, "aa"))
+	assert.EqualError(t, err, "first move must cover a start square")
+	assert.Equal(t, 0, game.CurrentPlayer())
+
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	assert.True(t, game.IsOver())
+	assert.Equal(t, model.ErrGameOver, game.Pass())
+
+	bRack := game.Players()[1].Rack()
+	assert.Equal(t, "AA", bRack.String())
+	record := game.Record()
+	require.Len(t, record, 2)
+	assert.Equal(t, model.PlayTurn, record[0].Type)
+	assert.Equal(t, 2, record[0].Score)
+	assert.Equal(t, 2, record[0].Move.Score)
+	assert.Equal(t, model.Turn{Player: 0, Type: model.EndRackBonusTurn, Rack: bRack, Score: 4, Total: 6}, record[1])
+
+	assert.Equal(t, 6, game.Players()[0].Score())
+	assert.Equal(t, []*model.Player{game.Players()[0]}, game.Winners())
+}
+
+func TestGameEndsAfterScorelessTurns(t *testing.T) {
+	config := newTestConfiguration()
+	config.EndGame = model.EndGameRules{MaxScorelessTurns: 3, Tiebreak: model.TiesBrokenByScoreBeforeAdjustments}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+
+	assert.Error(t, game.Exchange('a'))
+	for i := 0; i < 3; i++ {
+		assert.False(t, game.IsOver())
+		assert.Equal(t, i%2, game.CurrentPlayer())
+		require.NoError(t, game.Pass())
+	}
+	assert.True(t, game.IsOver())
+
+	record := game.Record()
+	require.Len(t, record, 5)
+	assert.Equal(t, model.Turn{Player: 0, Type: model.EndRackPenaltyTurn, Rack: record[0].Rack, Score: -2, Total: -2}, record[3])
+	assert.Equal(t, model.Turn{Player: 1, Type: model.EndRackPenaltyTurn, Rack: record[1].Rack, Score: -2, Total: -2}, record[4])
+	assert.Len(t, game.Winners(), 2)
+}
+
+func TestGameExchangesTiles(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterCounts = map[string]int{"a": 6, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+
+	assert.EqualError(t, game.Exchange('b'), "rack AA does not have the tiles to exchange")
+	require.NoError(t, game.Exchange('a', 'a'))
+	assert.Equal(t, 1, game.CurrentPlayer())
+	assert.Equal(t, 2, game.TilesInBag())
+	turn := game.Record()[0]
+	assert.Equal(t, model.ExchangeTurn, turn.Type)
+	assert.Equal(t, []rune{'a', 'a'}, turn.Exchanged)
+}
+
+func TestGameExchangesForChosenTiles(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "*": 0}
+	config.LetterCounts = map[string]int{"a": 4, "b": 2, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	require.NoError(t, game.SetRack(0, 'a', 'a'))
+	require.NoError(t, game.SetRack(1, 'a', 'a'))
+
+	assert.EqualError(t, game.ExchangeDrawing([]rune{'a'}, []rune{'b', 'b'}), "cannot draw 2 tiles in exchange for 1 tiles")
+	require.NoError(t, game.ExchangeDrawing([]rune{'a', 'a'}, []rune{'b', 'b'}))
+	rack := game.Players()[0].Rack()
+	assert.Equal(t, "BB", rack.String())
+	assert.Equal(t, 2, game.TilesInBag())
+}
+
+func TestSeededGamesDrawTheSameTiles(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "c": 3, "d": 2, "*": 0}
+	config.LetterCounts = map[string]int{"a": 5, "b": 5, "c": 5, "d": 5, "*": 0}
+	newSeededGame := func(seed int64) *model.Game {
+		game, err := model.NewSeededGame(config, nil, seed, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+		require.NoError(t, err)
+		return game
+	}
+	racks := func(game *model.Game) []string {
+		var racks []string
+		for _, player := range game.Players() {
+			rack := player.Rack()
+			racks = append(racks, rack.String())
+		}
+		return racks
+	}
+
+	first, second := newSeededGame(7), newSeededGame(7)
+	dealt := racks(first)
+	assert.Equal(t, dealt, racks(second))
+	for _, game := range []*model.Game{first, second} {
+		rack := game.Players()[0].Rack()
+		require.NoError(t, game.Exchange(rack.Letters()...))
+	}
+	assert.Equal(t, first.JSON(model.AllPlayers), second.JSON(model.AllPlayers))
+
+	differ := false
+	for seed := int64(8); seed < 16 && !differ; seed++ {
+		differ = !assert.ObjectsAreEqual(dealt, racks(newSeededGame(seed)))
+	}
+	assert.True(t, differ)
+}
+
+func TestSeededRestoredGamesDrawTheSameTiles(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "c": 3, "d": 2, "*": 0}
+	config.LetterCounts = map[string]int{"a": 5, "b": 5, "c": 5, "d": 5, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	restore := func() *model.Game {
+		restored, err := model.RestoreSeededGame(game.JSON(model.AllPlayers), nil, 7, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+		require.NoError(t, err)
+		return restored
+	}
+
+	first, second := restore(), restore()
+	for _, restored := range []*model.Game{first, second} {
+		rack := restored.Players()[0].Rack()
+		require.NoError(t, restored.Exchange(rack.Letters()...))
+	}
+	assert.Equal(t, first.JSON(model.AllPlayers), second.JSON(model.AllPlayers))
+}
+
+func TestGamePlaysToCompletionWithStrategies(t *testing.T) {
+	config := newTestConfiguration()
+	trie := newTestLexicon("aa")
+	moveGenerator := triemovegen.NewTrieMoveGenertator(trie)
+	players := []*model.Player{
+		model.NewPlayer("A", strategy.NewHighScoreStrategy(&moveGenerator)),
+		model.NewPlayer("B", strategy.NewHighScoreStrategy(&moveGenerator)),
+	}
+	game, err := model.NewGame(config, trie, players...)
+	require.NoError(t, err)
+
+	winners, err := game.Play()
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Player{players[0]}, winners)
+	assert.Equal(t, 6, players[0].Score())
+}
+
+func TestNewGameRejectsTooManyPlayers(t *testing.T) {
+	_, err := model.NewGame(
+		newTestConfiguration(),
+		nil,
+		model.NewPlayer("A", nil),
+		model.NewPlayer("B", nil),
+		model.NewPlayer("C", nil),
+	)
+	assert.Error(t, err)
+}
+
+func TestSetRackTakesTilesFromBagThenOtherRacks(t *testing.T) {
+	config := newTestConfiguration()
+	config.LetterScores = map[string]int{"a": 1, "b": 3, "*": 0}
+	config.LetterCounts = map[string]int{"a": 3, "b": 2, "*": 0}
+	game, err := model.NewGame(config, nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+
+	require.NoError(t, game.SetRack(0, 'b', 'b'))
+	aRack, bRack := game.Players()[0].Rack(), game.Players()[1].Rack()
+	assert.Equal(t, "BB", aRack.String())
+	assert.Equal(t, "AA", bRack.String())
+	assert.Equal(t, 1, game.TilesInBag())
+
+	assert.EqualError(t, game.SetRack(1, 'c'), `there are not enough 'c' tiles for the rack`)
+	assert.Error(t, game.SetRack(1, 'a', 'a', 'a'))
+}
@@ -0,0 +1,198 @@
+package model_test
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares the JSON encoding of value with a file in testdata
+func assertGolden(t *testing.T, name string, value interface{}) {
+	t.Helper()
+	actual, err := json.MarshalIndent(value, "", "  ")
+	require.NoError(t, err)
+	actual = append(actual, '\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, actual, 0644))
+	}
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+// newJSONTestGame returns a game in which bob's play is open to challenge.
+// The racks are set after every play so that the state does not depend on the
+// order of the tiles in the bag.
+func newJSONTestGame(t *testing.T) *model.Game {
+	wordMultipliers := newMultipliers(5, 5)
+	letterMultipliers := newMultipliers(5, 5)
+	wordMultipliers[2][2] = 2
+	letterMultipliers[1][2] = 3
+	config := model.Configuration{
+		RackSize:          3,
+		BoardSize:         5,
+		ChallengeRule:     model.FivePointChallenge,
+		LetterScores:      map[string]int{"a": 1, "c": 3, "t": 1, "?": 0},
+		LetterCounts:      map[string]int{"a": 4, "c": 2, "t": 3, "?": 1},
+		LetterMultipliers: letterMultipliers,
+		WordMultipliers:   wordMultipliers,
+	}
+	require.Empty(t, config.Validate())
+	game, err := model.NewGame(
+		config, newTestLexicon("at", "cat", "tat"), model.NewPlayer("alice", nil), model.NewPlayer("bob", nil),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, game.SetRack(0, 'c', 'a', 't'))
+	require.NoError(t, game.SetRack(1, 'a', 't', model.BlankTile))
+	require.NoError(t, game.PlayMove(newMove(2, 1, true, "cat")))
+	require.NoError(t, game.SetRack(0, 'a', 'a', 'c'))
+
+	move := newMove(1, 2, false, "tat")
+	move.Word.BlankTiles[2] = true
+	require.NoError(t, game.PlayMove(move))
+	require.Equal(t, 0, game.TilesInBag())
+	return game
+}
+
+func TestGameJSONGolden(t *testing.T) {
+	game := newJSONTestGame(t)
+	assertGolden(t, "game.json", game.JSON(model.AllPlayers))
+	assertGolden(t, "game_viewed_by_bob.json", game.JSON(1))
+}
+
+func TestGameJSONHidesEveryRackFromSpectator(t *testing.T) {
+	game := newJSONTestGame(t)
+	encoded := game.JSON(model.Spectator)
+	for _, player := range encoded.Players {
+		assert.Empty(t, player.Rack.Tiles)
+	}
+	for _, turn := range encoded.History {
+		if turn.Type == model.PlayTurn {
+			assert.Empty(t, turn.Rack.Tiles)
+		}
+	}
+	assert.Empty(t, encoded.Bag)
+}
+
+func TestRestoreGameFromJSON(t *testing.T) {
+	game := newJSONTestGame(t)
+	encoded, err := json.Marshal(game.JSON(model.AllPlayers))
+	require.NoError(t, err)
+
+	var decoded model.GameJSON
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	restored, err := model.RestoreGame(
+		decoded, newTestLexicon("at", "cat", "tat"), model.NewPlayer("alice", nil), model.NewPlayer("bob", nil),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, game.JSON(model.AllPlayers), restored.JSON(model.AllPlayers))
+
+	// the restored game carries on like the original
+	require.NoError(t, game.Challenge())
+	require.NoError(t, restored.Challenge())
+	assert.Equal(t, game.JSON(model.AllPlayers), restored.JSON(model.AllPlayers))
+	assert.Equal(t, model.ChallengeBonusTurn, restored.Record()[2].Type)
+	assert.Equal(t, 9, restored.Players()[1].Score())
+}
+
+func TestRestoreGameErrors(t *testing.T) {
+	game := newJSONTestGame(t)
+	players := func() []*model.Player {
+		return []*model.Player{model.NewPlayer("alice", nil), model.NewPlayer("bob", nil)}
+	}
+
+	_, err := model.RestoreGame(game.JSON(1), nil, players()...)
+	assert.EqualError(t, err, "cannot restore a game with hidden tiles")
+
+	encoded := game.JSON(model.AllPlayers)
+	encoded.Version = model.JSONVersion + 1
+	_, err = model.RestoreGame(encoded, nil, players()...)
+	assert.EqualError(t, err, "cannot restore version 2 of a game, only version 1")
+
+	_, err = model.RestoreGame(game.JSON(model.AllPlayers), nil, model.NewPlayer("alice", nil))
+	assert.EqualError(t, err, "game has 2 players but 1 were given")
+
+	encoded = game.JSON(model.AllPlayers)
+	encoded.Bag = "A"
+	_, err = model.RestoreGame(encoded, nil, players()...)
+	assert.EqualError(t, err, `game has 5 "a" tiles but the configuration has 4`)
+
+	encoded = game.JSON(model.AllPlayers)
+	encoded.History[0].Move.Row = 4
+	_, err = model.RestoreGame(encoded, nil, players()...)
+	assert.Error(t, err)
+}
+
+func TestBoardJSONRoundTrip(t *testing.T) {
+	alphabet, err := model.NewAlphabet([]string{"a", "ch", "t"})
+	require.NoError(t, err)
+	board, err := alphabet.ParseBoard(`
+	= . ' . .
+	. [CH] A t .
+	. . . . -
+	`, nil, nil)
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(alphabet.EncodeBoard(board))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `{"row":1,"column":1,"letter":"ch"}`)
+	assert.Contains(t, string(encoded), `{"row":1,"column":3,"letter":"t","blank":true}`)
+	assert.NotContains(t, string(encoded), "cross")
+
+	var decoded model.BoardJSON
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	restored, err := alphabet.DecodeBoard(decoded, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, alphabet.FormatBoard(board), alphabet.FormatBoard(restored))
+	assert.Equal(t, board.Tiles[0][1].IsAnchor, restored.Tiles[0][1].IsAnchor)
+	assert.Equal(t, board.Tiles[0][1].CrossCheckSet, restored.Tiles[0][1].CrossCheckSet)
+
+	decoded.Tiles = append(decoded.Tiles, model.PlacedTileJSON{Row: 1, Column: 1, Letter: "a"})
+	_, err = alphabet.DecodeBoard(decoded, nil, nil)
+	assert.EqualError(t, err, "tile 4 is on a square that already has a tile")
+}
+
+func TestRackAndMoveJSONRoundTrip(t *testing.T) {
+	alphabet := model.EnglishAlphabet
+	rack, err := alphabet.ParseRack("QIT?", 7, nil)
+	require.NoError(t, err)
+	encodedRack := alphabet.EncodeRack(rack)
+	assert.Equal(t, model.RackJSON{Tiles: "IQT?", TileCount: 4, Capacity: 7}, encodedRack)
+	decodedRack, err := alphabet.DecodeRack(encodedRack)
+	require.NoError(t, err)
+	assert.Equal(t, *rack, *decodedRack)
+
+	_, err = alphabet.DecodeRack(model.RackJSON{TileCount: 4, Capacity: 7})
+	assert.EqualError(t, err, `rack "" has 0 tiles but its tile_count is 4`)
+
+	move := newMove(7, 7, false, "quit")
+	move.Word.BlankTiles[1] = true
+	move.Score = 12
+	encodedMove := alphabet.EncodeMove(move)
+	assert.Equal(t, model.MoveJSON{Row: 7, Column: 7, Word: "QuIT", Score: 12}, encodedMove)
+	decodedMove, err := alphabet.DecodeMove(encodedMove)
+	require.NoError(t, err)
+	assert.Equal(t, move, decodedMove)
+}
+
+func TestTurnTypeText(t *testing.T) {
+	text, err := model.EndRackBonusTurn.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "end_rack_bonus", string(text))
+
+	var turnType model.TurnType
+	require.NoError(t, turnType.UnmarshalText([]byte("failed_challenge")))
+	assert.Equal(t, model.FailedChallengeTurn, turnType)
+	assert.Error(t, turnType.UnmarshalText([]byte("resign")))
+}
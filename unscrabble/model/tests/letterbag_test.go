@@ -1,6 +1,7 @@
 package model_test
 
 import (
+	"math/rand"
 	"testing"
 
 	"example.com/unscrabble/unscrabble/model"
@@ -8,9 +9,14 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// newRandom returns a source of randomness for shuffling letter bags
+func newRandom() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
 func TestNewLetterBagReturnsExpectedLetterBag(t *testing.T) {
 	letterCounts := map[rune]int{'a': 2, 'b': 1, 'c': 3}
-	letterBag := model.NewRandomLetterBag(letterCounts)
+	letterBag := model.NewRandomLetterBag(letterCounts, newRandom())
 
 	expectedContents := []rune{'a', 'a', 'b', 'c', 'c', 'c'}
 	assert.ElementsMatch(t, expectedContents, letterBag)
@@ -18,7 +24,7 @@ func TestNewLetterBagReturnsExpectedLetterBag(t *testing.T) {
 
 func TestGetLetterRemovesAndReturnsLetters(t *testing.T) {
 	letterCounts := map[rune]int{'a': 2, 'b': 1, 'c': 3}
-	letterBag := model.NewRandomLetterBag(letterCounts)
+	letterBag := model.NewRandomLetterBag(letterCounts, newRandom())
 
 	var letters []rune
 	for i := 0; i < 6; i++ {
@@ -35,14 +41,86 @@ func TestGetLetterRemovesAndReturnsLetters(t *testing.T) {
 
 func TestHasLetterReturnsFalseIfEmpty(t *testing.T) {
 	letterCounts := map[rune]int{'a': 0}
-	emptyLetterBag := model.NewRandomLetterBag(letterCounts)
+	emptyLetterBag := model.NewRandomLetterBag(letterCounts, newRandom())
 	assert.Empty(t, emptyLetterBag)
 	assert.False(t, emptyLetterBag.HasLetter())
 }
 
 func TestHasLetterReturnsTrueIfNotEmpty(t *testing.T) {
 	letterCounts := map[rune]int{'a': 1}
-	letterBag := model.NewRandomLetterBag(letterCounts)
+	letterBag := model.NewRandomLetterBag(letterCounts, newRandom())
 	assert.Len(t, letterBag, 1)
 	assert.True(t, letterBag.HasLetter())
 }
+
+func TestReturnLettersAddsLettersToBag(t *testing.T) {
+	letterBag := model.NewRandomLetterBag(map[rune]int{'a': 1}, newRandom())
+	letterBag.ReturnLetters(newRandom(), 'b', 'c', 'c')
+
+	assert.ElementsMatch(t, []rune{'a', 'b', 'c', 'c'}, letterBag)
+}
+
+func TestReturnLettersKeepsSamplingUnbiased(t *testing.T) {
+	const trials = 6000
+	drawCounts := map[rune]int{}
+	random := newRandom()
+	for i := 0; i < trials; i++ {
+		letterBag := model.NewRandomLetterBag(map[rune]int{'a': 1, 'b': 1}, random)
+		letterBag.ReturnLetters(random, 'c')
+		letter, err := letterBag.GetLetter()
+		require.NoError(t, err)
+		drawCounts[letter]++
+	}
+
+	for _, letter := range []rune{'a', 'b', 'c'} {
+		assert.InDelta(t, trials/3, drawCounts[letter], trials/10, "letter %q", letter)
+	}
+}
+
+func TestDrawLettersRemovesSpecifiedLetters(t *testing.T) {
+	letterBag := model.NewRandomLetterBag(map[rune]int{'a': 2, 'b': 1, 'c': 3}, newRandom())
+
+	err := letterBag.DrawLetters(newRandom(), 'a', 'c', 'c')
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []rune{'a', 'b', 'c'}, letterBag)
+}
+
+func TestDrawLettersLeavesBagUnchangedIfLettersAreMissing(t *testing.T) {
+	letterBag := model.NewRandomLetterBag(map[rune]int{'a': 2, 'b': 1}, newRandom())
+
+	err := letterBag.DrawLetters(newRandom(), 'a', 'b', 'b')
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []rune{'a', 'a', 'b'}, letterBag)
+}
+
+func TestExchangeSwapsLettersWithBag(t *testing.T) {
+	letterBag := model.NewRandomLetterBag(map[rune]int{'a': 2}, newRandom())
+
+	drawn, err := letterBag.Exchange(newRandom(), 'b', 'c')
+	require.NoError(t, err)
+	assert.Equal(t, []rune{'a', 'a'}, drawn)
+	assert.ElementsMatch(t, []rune{'b', 'c'}, letterBag)
+}
+
+func TestExchangeReturnsErrorIfBagHasTooFewLetters(t *testing.T) {
+	letterBag := model.NewRandomLetterBag(map[rune]int{'a': 1}, newRandom())
+
+	_, err := letterBag.Exchange(newRandom(), 'b', 'c')
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []rune{'a'}, letterBag)
+}
+
+func TestLetterCountsCountsRemainingLetters(t *testing.T) {
+	letterCounts := map[rune]int{'a': 2, 'b': 1, 'c': 3}
+	letterBag := model.NewRandomLetterBag(letterCounts, newRandom())
+	_, err := letterBag.GetLetter()
+	require.NoError(t, err)
+
+	remaining := letterBag.LetterCounts()
+	total := 0
+	for letter, count := range remaining {
+		assert.LessOrEqual(t, count, letterCounts[letter])
+		total += count
+	}
+	assert.Equal(t, 5, total)
+}
@@ -0,0 +1,61 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLetterSetContainsOnlyProvidedLetters(t *testing.T) {
+	set := model.NewLetterSet('a', 'c')
+	assert.True(t, set.Contains('a'))
+	assert.False(t, set.Contains('b'))
+	assert.True(t, set.Contains('c'))
+	assert.Equal(t, []rune{'a', 'c'}, set.Letters())
+}
+
+func TestWithoutRemovesLetter(t *testing.T) {
+	set := model.NewLetterSet('a', 'c').Without('a')
+	assert.Equal(t, model.NewLetterSet('c'), set)
+}
+
+func TestIntersectReturnsCommonLetters(t *testing.T) {
+	set := model.NewLetterSet('a', 'b', 'c').Intersect(model.NewLetterSet('b', 'c', 'd'))
+	assert.Equal(t, model.NewLetterSet('b', 'c'), set)
+}
+
+func TestUnconstrainedLetterSetContainsAllLetters(t *testing.T) {
+	set := model.UnconstrainedLetterSet
+	assert.True(t, set.IsUnconstrained())
+	assert.True(t, set.Contains('z'))
+	assert.True(t, set.Contains('ß'))
+	assert.Equal(t, model.NewLetterSet('q'), set.Intersect(model.NewLetterSet('q')))
+	assert.False(t, model.NewLetterSet('q').IsUnconstrained())
+}
+
+func TestSetOfEveryLetterIsNotUnconstrained(t *testing.T) {
+	// the largest alphabet has the letters 'a' to 'z' and 36 others
+	var symbols []string
+	for letter := 'a'; letter <= 'z'; letter++ {
+		symbols = append(symbols, string(letter))
+	}
+	for i := 0; i < 36; i++ {
+		symbols = append(symbols, string(rune('α'+i)))
+	}
+	alphabet, err := model.NewAlphabet(symbols)
+	require.NoError(t, err)
+	_, err = model.NewAlphabet(append(symbols, "ж"))
+	require.Error(t, err)
+
+	set := model.NewLetterSet(alphabet.Letters()...).With(model.BlankTile)
+	assert.False(t, set.IsUnconstrained())
+	assert.False(t, set.Contains('ß'))
+}
+
+func TestEmptyLetterSetContainsNoLetters(t *testing.T) {
+	assert.False(t, model.EmptyLetterSet.Contains('a'))
+	assert.False(t, model.EmptyLetterSet.Contains('ß'))
+	assert.Empty(t, model.EmptyLetterSet.Letters())
+}
@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateScoreScoresHorizontalAndVerticalMoves(t *testing.T) {
+	wordMultipliers := newMultipliers(3, 5)
+	wordMultipliers[0][0] = 2
+	letterMultipliers := newMultipliers(3, 5)
+	letterMultipliers[2][0] = 3
+	board := model.NewBoard(nil, wordMultipliers, letterMultipliers)
+	letterScores := map[rune]int{'c': 3, 'a': 1, 't': 1}
+
+	word := model.Word{Chars: "cat", BlankTiles: []bool{false, false, false}}
+
+	horizontal := model.Move{StartPosition: &model.Position{Row: 0, Column: 0}, Horizontal: true, Word: word}
+	score, err := horizontal.CalculateScore(board, letterScores, 7, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 10, score)
+
+	vertical := model.Move{StartPosition: &model.Position{Row: 0, Column: 0}, Horizontal: false, Word: word}
+	score, err = vertical.CalculateScore(board, letterScores, 7, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 14, score)
+}
+
+func TestCalculateScoreAddsBingoPremium(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(1, 3), newMultipliers(1, 3))
+	move := model.Move{
+		StartPosition: &model.Position{Row: 0, Column: 0},
+		Horizontal:    true,
+		Word:          model.Word{Chars: "cat", BlankTiles: []bool{true, false, false}},
+	}
+
+	score, err := move.CalculateScore(board, map[rune]int{'c': 3, 'a': 1, 't': 1}, 3, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 52, score)
+}
+
+func TestCalculateScoreReturnsErrorIfWordLeavesRectangularBoard(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(2, 5), newMultipliers(2, 5))
+	word := model.Word{Chars: "cat", BlankTiles: []bool{false, false, false}}
+
+	horizontal := model.Move{StartPosition: &model.Position{Row: 0, Column: 2}, Horizontal: true, Word: word}
+	_, err := horizontal.CalculateScore(board, nil, 7, 50)
+	assert.NoError(t, err)
+
+	vertical := model.Move{StartPosition: &model.Position{Row: 0, Column: 2}, Horizontal: false, Word: word}
+	_, err = vertical.CalculateScore(board, nil, 7, 50)
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	testCases := []struct {
+		coordinates string
+		position    model.Position
+		horizontal  bool
+	}{
+		{"8H", model.Position{Row: 7, Column: 7}, true},
+		{"H8", model.Position{Row: 7, Column: 7}, false},
+		{"15a", model.Position{Row: 14, Column: 0}, true},
+		{"o1", model.Position{Row: 0, Column: 14}, false},
+	}
+	for _, testCase := range testCases {
+		position, horizontal, err := model.ParseCoordinates(testCase.coordinates)
+		require.NoError(t, err, testCase.coordinates)
+		assert.Equal(t, testCase.position, position, testCase.coordinates)
+		assert.Equal(t, testCase.horizontal, horizontal, testCase.coordinates)
+	}
+
+	for _, invalid := range []string{"", "8", "H", "0H", "8H8", "HH", "8?"} {
+		_, _, err := model.ParseCoordinates(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	assert.Equal(t, "8H", model.Position{Row: 7, Column: 7}.Coordinates(true))
+	assert.Equal(t, "A15", model.Position{Row: 14, Column: 0}.Coordinates(false))
+}
+
+func TestFormatMoveAndParseMoveRoundTrip(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(5, 5), newMultipliers(5, 5))
+	require.NoError(t, board.PlaceMove(newMove(2, 1, true, "cat"), nil))
+	alphabet := model.EnglishAlphabet
+
+	move := newMove(2, 0, true, "scats")
+	move.Word.BlankTiles[4] = true
+	assert.Equal(t, "3A S(CAT)s", alphabet.FormatMove(board, move))
+
+	parsed, err := alphabet.ParseMove(board, "3A S(CAT)s")
+	require.NoError(t, err)
+	assert.Equal(t, move, parsed)
+
+	parsed, err = alphabet.ParseMove(board, "3a s...S")
+	require.NoError(t, err)
+	assert.Equal(t, "scats", parsed.Word.Chars)
+	assert.Equal(t, []bool{true, false, false, false, false}, parsed.Word.BlankTiles)
+
+	vertical := newMove(1, 2, false, "oaf")
+	assert.Equal(t, "C2 O(A)F", alphabet.FormatMove(board, vertical))
+	parsed, err = alphabet.ParseMove(board, "C2 O(A)F")
+	require.NoError(t, err)
+	assert.Equal(t, vertical, parsed)
+
+	_, err = alphabet.ParseMove(board, "3A S(COT)S")
+	assert.EqualError(t, err, `"3A S(COT)S" does not match the tiles on the board`)
+	_, err = alphabet.ParseMove(board, "1A (C)AT")
+	assert.EqualError(t, err, `"1A (C)AT" plays through 1A, which is empty`)
+}
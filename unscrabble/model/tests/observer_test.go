@@ -0,0 +1,91 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventTypes returns an observer that appends the types of the events it
+// observes to types
+func eventTypes(types *[]model.GameEventType) model.Observer {
+	return model.ObserverFunc(func(game *model.Game, event model.GameEvent) {
+		*types = append(*types, event.Type)
+	})
+}
+
+func TestObserverIsToldOfEveryEvent(t *testing.T) {
+	game := newChallengeGame(t, model.DoubleChallenge, "aa")
+	var events []model.GameEvent
+	game.AddObserver(model.ObserverFunc(func(game *model.Game, event model.GameEvent) {
+		events = append(events, event)
+	}))
+	require.Len(t, events, 3)
+	assert.Equal(t, model.GameEvent{Type: model.GameStarted, Player: -1}, events[0])
+	assert.Equal(t, model.GameEvent{Type: model.RackDrawn, Player: 1, Tiles: []rune{'a', 'a'}}, events[2])
+
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	require.Len(t, events, 5)
+	assert.Equal(t, model.MoveScored, events[3].Type)
+	assert.Equal(t, 0, events[3].Player)
+	assert.Equal(t, 2, events[3].Score)
+	assert.Equal(t, model.GameEvent{Type: model.RackDrawn, Player: 0, Tiles: []rune{'a', 'a'}}, events[4])
+
+	require.NoError(t, game.Challenge())
+	require.Len(t, events, 6)
+	assert.Equal(t, model.PlayChallenged, events[5].Type)
+	assert.Equal(t, 1, events[5].Player)
+	assert.False(t, events[5].Successful)
+
+	require.NoError(t, game.Exchange('a'))
+	require.Len(t, events, 8)
+	assert.Equal(t, model.GameEvent{Type: model.TilesExchanged, Player: 0, Tiles: []rune{'a'}}, events[6])
+	assert.Equal(t, model.GameEvent{Type: model.RackDrawn, Player: 0, Tiles: []rune{'a'}}, events[7])
+}
+
+func TestObserverIsToldOfGameEnd(t *testing.T) {
+	game, err := model.NewGame(newTestConfiguration(), nil, model.NewPlayer("A", nil), model.NewPlayer("B", nil))
+	require.NoError(t, err)
+	require.NoError(t, game.Pass())
+
+	// the observer is added after the game has started
+	var types []model.GameEventType
+	game.AddObserver(eventTypes(&types))
+	assert.Empty(t, types)
+	require.NoError(t, game.PlayMove(newMove(1, 0, true, "aa")))
+	assert.Equal(t, []model.GameEventType{model.MoveScored, model.GameEnded}, types)
+}
+
+func TestObserverIsToldOfMovesChosenByStrategies(t *testing.T) {
+	config := newTestConfiguration()
+	players := []*model.Player{
+		model.NewPlayer("A", alwaysPlays{newMove(1, 0, true, "aa")}),
+		model.NewPlayer("B", noMoves{}),
+	}
+	game, err := model.NewGame(config, newTestLexicon("aa"), players...)
+	require.NoError(t, err)
+	var types []model.GameEventType
+	game.AddObserver(eventTypes(&types))
+
+	_, err = game.Play()
+	require.NoError(t, err)
+	assert.Equal(t, []model.GameEventType{
+		model.GameStarted,
+		model.RackDrawn,
+		model.RackDrawn,
+		model.MoveChosen,
+		model.MoveScored,
+		model.GameEnded,
+	}, types)
+	assert.Equal(t, "game ended", model.GameEnded.String())
+}
+
+type alwaysPlays struct {
+	move model.Move
+}
+
+func (s alwaysPlays) PickMove(board model.Board, rack model.Rack) *model.Move {
+	return &s.move
+}
@@ -0,0 +1,137 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchesUntilDeadline is a strategy which searches for a better move until
+// its deadline, and then plays the move it was given
+type searchesUntilDeadline struct {
+	move model.Move
+}
+
+func (s searchesUntilDeadline) PickMove(board model.Board, rack model.Rack) *model.Move {
+	return &s.move
+}
+
+func (s searchesUntilDeadline) PickMoveContext(ctx context.Context, board model.Board, rack model.Rack) *model.Move {
+	<-ctx.Done()
+	return &s.move
+}
+
+// slowlyPlays is a strategy which does not know about deadlines, and takes
+// too long to pick its move
+type slowlyPlays struct {
+	move model.Move
+}
+
+func (s slowlyPlays) PickMove(board model.Board, rack model.Rack) *model.Move {
+	time.Sleep(model.DeadlineGrace + 50*time.Millisecond)
+	return &s.move
+}
+
+// blocksUntilReleased is a strategy which does not know about deadlines, and
+// does not pick its move until it is released
+type blocksUntilReleased struct {
+	released chan struct{}
+	move     model.Move
+}
+
+func (s blocksUntilReleased) PickMove(board model.Board, rack model.Rack) *model.Move {
+	<-s.released
+	return &s.move
+}
+
+// countsPicks is a strategy which counts the moves it is asked to pick
+type countsPicks struct {
+	picks *int
+}
+
+func (s countsPicks) PickMove(board model.Board, rack model.Rack) *model.Move {
+	*s.picks++
+	return nil
+}
+
+func TestStrategiesPlayTheirMoveAtTheDeadline(t *testing.T) {
+	players := []*model.Player{
+		model.NewPlayer("A", searchesUntilDeadline{newMove(1, 0, true, "aa")}),
+		model.NewPlayer("B", noMoves{}),
+	}
+	game, err := model.NewGame(newTestConfiguration(), newTestLexicon("aa"), players...)
+	require.NoError(t, err)
+
+	winners, err := game.PlayContext(context.Background(), 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Player{players[0]}, winners)
+}
+
+func TestMovesPickedAfterTheDeadlineAreNotPlayed(t *testing.T) {
+	players := []*model.Player{
+		model.NewPlayer("A", slowlyPlays{newMove(1, 0, true, "aa")}),
+		model.NewPlayer("B", noMoves{}),
+	}
+	game, err := model.NewGame(newTestConfiguration(), newTestLexicon("aa"), players...)
+	require.NoError(t, err)
+	var types []model.GameEventType
+	game.AddObserver(eventTypes(&types))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.NoError(t, game.PlayTurnContext(ctx))
+	assert.NotContains(t, types, model.MoveChosen)
+	assert.Equal(t, 0, players[0].Score())
+	assert.Equal(t, 1, game.CurrentPlayer())
+}
+
+func TestCancelledTurnsAreNotTaken(t *testing.T) {
+	players := []*model.Player{
+		model.NewPlayer("A", searchesUntilDeadline{newMove(1, 0, true, "aa")}),
+		model.NewPlayer("B", noMoves{}),
+	}
+	game, err := model.NewGame(newTestConfiguration(), newTestLexicon("aa"), players...)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	assert.Equal(t, context.Canceled, game.PlayTurnContext(ctx))
+	assert.Equal(t, 0, game.CurrentPlayer())
+	assert.Empty(t, game.Actions())
+
+	_, err = game.PlayContext(ctx, 0)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestStrategiesIgnoringTheDeadlineDoNotHoldUpTheGame(t *testing.T) {
+	released := make(chan struct{})
+	picks := 0
+	players := []*model.Player{
+		model.NewPlayer("A", blocksUntilReleased{released, newMove(1, 0, true, "aa")}),
+		model.NewPlayer("B", countsPicks{&picks}),
+	}
+	game, err := model.NewGame(newTestConfiguration(), newTestLexicon("aa"), players...)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.NoError(t, game.PlayTurnContext(ctx))
+	assert.Equal(t, 0, players[0].Score())
+	assert.Equal(t, 1, game.CurrentPlayer())
+
+	// the next strategy waits for the one still picking its move, as they
+	// may share a move generator, but only until its own deadline
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.NoError(t, game.PlayTurnContext(ctx))
+	assert.Equal(t, 0, picks)
+	assert.Equal(t, 0, game.CurrentPlayer())
+
+	close(released)
+	require.NoError(t, game.PlayTurnContext(context.Background()))
+	assert.NotZero(t, players[0].Score())
+}
@@ -2,12 +2,14 @@ package model_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"example.com/unscrabble/unscrabble/model"
 	"example.com/unscrabble/unscrabble/model/mock_model"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFillFillsRackFromLetterGetter(t *testing.T) {
@@ -74,3 +76,101 @@ func TestCopyCopiesRack(t *testing.T) {
 	assert.Equal(t, rack, &copyRack)
 	assert.NotSame(t, rack, &copyRack)
 }
+
+func TestParseRackParsesLettersAndBlanks(t *testing.T) {
+	rack, err := model.ParseRack("AEi?s*", 7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]int{'a': 1, 'e': 1, 'i': 1, 's': 1, '*': 2}, rack.LetterCounts())
+	assert.Equal(t, 6, rack.TileCount())
+	assert.Equal(t, 7, rack.Capacity())
+}
+
+func TestParseRackRejectsTooManyTiles(t *testing.T) {
+	_, err := model.ParseRack("ABCD", 3, nil)
+	assert.Error(t, err)
+}
+
+func TestParseRackValidatesAgainstLetterCounts(t *testing.T) {
+	letterCounts := map[rune]int{'a': 2, 'b': 1, '*': 1}
+
+	_, err := model.ParseRack("AB?", 7, letterCounts)
+	assert.NoError(t, err)
+
+	_, err = model.ParseRack("AZ", 7, letterCounts)
+	assert.Error(t, err, "letter not in alphabet")
+
+	_, err = model.ParseRack("BB", 7, letterCounts)
+	assert.Error(t, err, "more letters than in distribution")
+}
+
+func TestParseRackRejectsLettersOutsideAlphabet(t *testing.T) {
+	_, err := model.ParseRack("AÄ", 7, nil)
+	assert.Error(t, err)
+}
+
+func TestParseRackRejectsRacksLargerThanMaxRackSize(t *testing.T) {
+	_, err := model.ParseRack(strings.Repeat("A", 300), 300, nil)
+	assert.Error(t, err)
+	_, err = model.NewRackFromLetterCounts(300, map[rune]int{'a': 300})
+	assert.Error(t, err)
+
+	rack, err := model.ParseRack(strings.Repeat("A", model.MaxRackSize), model.MaxRackSize, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[rune]int{'a': model.MaxRackSize}, rack.LetterCounts())
+}
+
+func TestCopyDoesNotShareLetters(t *testing.T) {
+	rack := model.NewRack(2)
+	rack.AddLetter('a')
+
+	copyRack := rack.Copy()
+	copyRack.RemoveLetter('a')
+	copyRack.AddLetter('b')
+	assert.True(t, rack.HasTile('a'))
+	assert.False(t, rack.HasTile('b'))
+}
+
+func TestStringReturnsCanonicalRack(t *testing.T) {
+	rack := model.NewRack(7)
+	for _, letter := range []rune{'s', '*', 'r', 'a', 'e', 'a'} {
+		rack.AddLetter(letter)
+	}
+	assert.Equal(t, "AAERS?", rack.String())
+
+	parsed, err := model.ParseRack(rack.String(), 7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rack.String(), parsed.String())
+}
+
+func TestNewRackFromLetterCountsRoundTrips(t *testing.T) {
+	letterCounts := map[rune]int{'a': 2, 'q': 1, '*': 1}
+	rack, err := model.NewRackFromLetterCounts(7, letterCounts)
+	require.NoError(t, err)
+	assert.Equal(t, letterCounts, rack.LetterCounts())
+
+	_, err = model.NewRackFromLetterCounts(3, letterCounts)
+	assert.Error(t, err)
+}
+
+var rackSink model.Rack
+
+func BenchmarkRackCopy(b *testing.B) {
+	rack, err := model.ParseRack("AEILST?", 7, nil)
+	require.NoError(b, err)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rackSink = rack.Copy()
+	}
+}
+
+func TestPlayableReturnsRackLettersOrUnconstrainedIfBlank(t *testing.T) {
+	rack, err := model.ParseRack("AB", 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, model.NewLetterSet('a', 'b'), rack.Playable())
+
+	rack.RemoveLetter('a')
+	assert.Equal(t, model.NewLetterSet('b'), rack.Playable())
+
+	rack.AddLetter(model.BlankTile)
+	assert.True(t, rack.Playable().IsUnconstrained())
+}
@@ -0,0 +1,118 @@
+package model_test
+
+import (
+	"testing"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMove(row, column int, horizontal bool, chars string) model.Move {
+	return model.Move{
+		StartPosition: &model.Position{Row: row, Column: column},
+		Horizontal:    horizontal,
+		Word:          model.Word{Chars: chars, BlankTiles: make([]bool, len(chars))},
+	}
+}
+
+func TestValidateMoveChecksFirstMoveRules(t *testing.T) {
+	board := model.NewBoardWithRules(
+		nil,
+		newMultipliers(5, 5),
+		newMultipliers(5, 5),
+		model.FirstMoveRules{
+			StartSquares:  []model.Position{{Row: 0, Column: 0}, {Row: 4, Column: 4}},
+			MinWordLength: 3,
+		},
+	)
+
+	assert.NoError(t, board.ValidateMove(newMove(0, 0, true, "cat"), nil, nil))
+	assert.NoError(t, board.ValidateMove(newMove(2, 4, false, "cat"), nil, nil))
+	assert.EqualError(
+		t,
+		board.ValidateMove(newMove(2, 1, true, "cat"), nil, nil),
+		"first move must cover a start square",
+	)
+	assert.EqualError(
+		t,
+		board.ValidateMove(newMove(0, 0, true, "at"), nil, nil),
+		"first word must have at least 3 letters",
+	)
+
+	anywhere := model.NewBoardWithRules(
+		nil,
+		newMultipliers(5, 5),
+		newMultipliers(5, 5),
+		model.FirstMoveRules{Anywhere: true},
+	)
+	assert.NoError(t, anywhere.ValidateMove(newMove(2, 1, true, "cat"), nil, nil))
+	assert.EqualError(
+		t,
+		anywhere.ValidateMove(newMove(2, 1, true, "a"), nil, nil),
+		"move does not form a word of at least two letters",
+	)
+}
+
+func TestValidateMoveChecksMovesConnectToPlacedTiles(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(5, 5), newMultipliers(5, 5))
+	letterScores := map[rune]int{'c': 3, 'a': 1, 't': 1, 's': 1}
+	require.NoError(t, board.PlaceMove(newMove(2, 1, true, "cat"), letterScores))
+
+	testCases := []struct {
+		name string
+		move model.Move
+		err  string
+	}{
+		{"through placed tiles", newMove(2, 1, true, "cats"), ""},
+		{"hooking a placed tile", newMove(1, 3, false, "at"), ""},
+		{"not connected", newMove(0, 0, true, "at"), "move is not connected to the tiles on the board"},
+		{"no tiles placed", newMove(2, 1, true, "cat"), "move does not place any tiles"},
+		{"wrong letter", newMove(2, 1, true, "bat"), "word does not match the tile at row 2, column 1"},
+		{"partial word", newMove(2, 2, true, "ats"), "word does not include all of the adjacent tiles on the board"},
+		{"off the board", newMove(3, 3, false, "sat"), "word extends beyond end of board.tiles"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := board.ValidateMove(testCase.move, nil, nil)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, testCase.err)
+			}
+		})
+	}
+}
+
+func TestValidateMoveChecksRackAndLexicon(t *testing.T) {
+	board := model.NewBoard(nil, newMultipliers(5, 5), newMultipliers(5, 5))
+	letterScores := map[rune]int{'c': 3, 'a': 1, 't': 1, 's': 1}
+	require.NoError(t, board.PlaceMove(newMove(2, 1, true, "cat"), letterScores))
+
+	trie := lexicon.NewTrieNode()
+	for _, word := range []string{"cat", "cats", "as", "at"} {
+		trie.Insert(word)
+	}
+	rack, err := model.ParseRack("AS?", 7, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, board.ValidateMove(newMove(2, 1, true, "cats"), rack, trie))
+	assert.EqualError(
+		t,
+		board.ValidateMove(newMove(1, 0, false, "tat"), rack, trie),
+		"rack AS? does not have the tiles for the move",
+	)
+
+	blank := newMove(2, 1, true, "cats")
+	blank.Word.BlankTiles[3] = true
+	assert.NoError(t, board.ValidateMove(blank, rack, trie))
+
+	// "ac" and "sa" are formed by placing "as" above "ca"
+	move := newMove(1, 1, true, "as")
+	err = board.ValidateMove(move, rack, trie)
+	var phonyErr *model.PhonyError
+	require.ErrorAs(t, err, &phonyErr)
+	assert.Equal(t, []string{"ac", "sa"}, phonyErr.Words)
+	assert.Equal(t, []string{"as", "ac", "sa"}, board.WordsFormed(move))
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PhonyError is returned by ValidateMove for a move that could be played
+// except that it forms words which are not in the lexicon
+type PhonyError struct {
+	Words []string
+}
+
+func (err *PhonyError) Error() string {
+	return fmt.Sprintf("words not in lexicon: %v", strings.Join(err.Words, ", "))
+}
+
+// checkFits checks that move is well formed, fits on the board and agrees
+// with the tiles already on the board
+func (board Board) checkFits(move Move) error {
+	if move.StartPosition == nil {
+		return errors.New("move has no start position")
+	}
+	wordLength := move.Word.Length()
+	if wordLength == 0 {
+		return errors.New("move has no tiles")
+	}
+	if len(move.Word.BlankTiles) != wordLength {
+		return errors.New("blanks should be same length as word")
+	}
+
+	rowStep, columnStep := move.steps()
+	start := *move.StartPosition
+	end := Position{
+		Row:    start.Row + rowStep*(wordLength-1),
+		Column: start.Column + columnStep*(wordLength-1),
+	}
+	if !board.Contains(start) || !board.Contains(end) {
+		return errors.New("word extends beyond end of board.tiles")
+	}
+
+	i := 0
+	for _, letter := range move.Word.Chars {
+		tile := board.Tiles[start.Row+i*rowStep][start.Column+i*columnStep]
+		if !tile.Empty() && tile.Letter != letter {
+			return fmt.Errorf(
+				"word does not match the tile at row %v, column %v",
+				tile.BoardPosition.Row,
+				tile.BoardPosition.Column,
+			)
+		}
+		i++
+	}
+	return nil
+}
+
+// ValidateMove checks that move can be played on the board. The tiles placed
+// must come from the rack, and the words formed must be in the lexicon. The
+// rack and lexicon checks are skipped if they are nil. If the only problem
+// with the move is that it forms words not in the lexicon a *PhonyError is
+// returned.
+func (board Board) ValidateMove(move Move, rack *Rack, lexicon Lexicon) error {
+	if err := board.checkFits(move); err != nil {
+		return err
+	}
+
+	rowStep, columnStep := move.steps()
+	start := *move.StartPosition
+	before := Position{Row: start.Row - rowStep, Column: start.Column - columnStep}
+	after := Position{
+		Row:    start.Row + rowStep*move.Word.Length(),
+		Column: start.Column + columnStep*move.Word.Length(),
+	}
+	for _, position := range []Position{before, after} {
+		if board.Contains(position) && !board.Tiles[position.Row][position.Column].Empty() {
+			return errors.New("word does not include all of the adjacent tiles on the board")
+		}
+	}
+
+	placed := board.TilesPlaced(move)
+	coversAnchor := false
+	for i := 0; i < move.Word.Length(); i++ {
+		tile := board.Tiles[start.Row+i*rowStep][start.Column+i*columnStep]
+		coversAnchor = coversAnchor || (tile.Empty() && tile.IsAnchor)
+	}
+	if len(placed) == 0 {
+		return errors.New("move does not place any tiles")
+	}
+
+	if board.IsEmpty() {
+		if !coversAnchor {
+			return errors.New("first move must cover a start square")
+		}
+		if move.Word.Length() < board.firstMoveRules.MinWordLength {
+			return fmt.Errorf(
+				"first word must have at least %v letters",
+				board.firstMoveRules.MinWordLength,
+			)
+		}
+	} else if !coversAnchor {
+		return errors.New("move is not connected to the tiles on the board")
+	}
+
+	if rack != nil {
+		remaining := rack.Copy()
+		for _, letter := range placed {
+			if !remaining.HasTile(letter) {
+				return fmt.Errorf("rack %v does not have the tiles for the move", rack)
+			}
+			remaining.RemoveLetter(letter)
+		}
+	}
+
+	words := board.WordsFormed(move)
+	if len(words) == 0 {
+		return errors.New("move does not form a word of at least two letters")
+	}
+	if lexicon == nil {
+		return nil
+	}
+	var phonies []string
+	for _, word := range words {
+		if !lexicon.Contains(word) {
+			phonies = append(phonies, word)
+		}
+	}
+	if len(phonies) > 0 {
+		return &PhonyError{Words: phonies}
+	}
+	return nil
+}
+
+// TilesPlaced returns the tiles from the rack that move places on the board,
+// with blanks as BlankTile. The move must fit on the board.
+func (board Board) TilesPlaced(move Move) []rune {
+	var placed []rune
+	rowStep, columnStep := move.steps()
+	i := 0
+	for _, letter := range move.Word.Chars {
+		tile := board.Tiles[move.StartPosition.Row+i*rowStep][move.StartPosition.Column+i*columnStep]
+		if tile.Empty() {
+			if move.Word.BlankTiles[i] {
+				placed = append(placed, BlankTile)
+			} else {
+				placed = append(placed, letter)
+			}
+		}
+		i++
+	}
+	return placed
+}
+
+// WordsFormed returns the words of at least two letters that move forms on
+// the board: the word of the move itself, followed by the cross words formed
+// by each tile placed. The move must fit on the board.
+func (board Board) WordsFormed(move Move) []string {
+	var words []string
+	if move.Word.Length() > 1 {
+		words = append(words, move.Word.Chars)
+	}
+
+	// cross words run perpendicular to the move
+	rowStep, columnStep := move.steps()
+	crossRowStep, crossColumnStep := columnStep, rowStep
+	i := 0
+	for _, letter := range move.Word.Chars {
+		position := Position{
+			Row:    move.StartPosition.Row + i*rowStep,
+			Column: move.StartPosition.Column + i*columnStep,
+		}
+		i++
+		if !board.Tiles[position.Row][position.Column].Empty() {
+			continue
+		}
+		prefix := board.lettersFrom(position, -crossRowStep, -crossColumnStep)
+		suffix := board.lettersFrom(position, crossRowStep, crossColumnStep)
+		if prefix == "" && suffix == "" {
+			continue
+		}
+		words = append(words, prefix+string(letter)+suffix)
+	}
+	return words
+}
+
+// lettersFrom returns the letters placed next to position in the direction of
+// the steps, in board order
+func (board Board) lettersFrom(position Position, rowStep, columnStep int) string {
+	var letters []rune
+	for y, x := position.Row+rowStep, position.Column+columnStep; board.Contains(Position{Row: y, Column: x}) &&
+		!board.Tiles[y][x].Empty(); y, x = y+rowStep, x+columnStep {
+		letters = append(letters, board.Tiles[y][x].Letter)
+	}
+	if rowStep < 0 || columnStep < 0 {
+		return reverse(string(letters))
+	}
+	return string(letters)
+}
@@ -15,22 +15,31 @@ type TrieMoveGenerator struct {
 	trieRoot *lexicon.TrieNode
 }
 
+// GenerateMoves generates every move that can be played from the rack on the board. Moves are
+// generated along the rows of the board and then along the rows of the transposed board, which
+// are the columns of the original board. Words shorter than the board's minimum word length,
+// such as a short first move, are skipped. The transposing is done on a copy of the board, so
+// the caller's board is never changed and may be shared between goroutines.
 func (t *TrieMoveGenerator) GenerateMoves(board model.Board, rack model.Rack) []model.Move {
 	var moves []model.Move
-	t.board = board
+	t.board = board.Copy()
 	t.rack = rack
+	minWordLength := board.MinWordLength()
 
 	for _, transposed := range []bool{false, true} {
-		for _, row := range board.Tiles {
+		for _, row := range t.board.Tiles {
 			for _, tile := range row {
 				if !tile.IsAnchor {
 					continue
 				}
 				for _, prefixResult := range t.generatePrefixResults(tile) {
 					for _, extendedPrefix := range t.extendPrefix(prefixResult, tile) {
+						if extendedPrefix.Length() < minWordLength {
+							continue
+						}
 						startPos := model.Position{
-							Row:    tile.BoardPosition.Row - len(prefixResult.prefix.Chars),
-							Column: tile.BoardPosition.Column,
+							Row:    tile.BoardPosition.Row,
+							Column: tile.BoardPosition.Column - prefixResult.prefix.Length(),
 						}
 						if transposed {
 							startPos.Row, startPos.Column = startPos.Column, startPos.Row
@@ -39,7 +48,7 @@ func (t *TrieMoveGenerator) GenerateMoves(board model.Board, rack model.Rack) []
 							moves,
 							model.Move{
 								StartPosition: &startPos,
-								Horizontal:    transposed,
+								Horizontal:    !transposed,
 								Word:          extendedPrefix,
 							},
 						)
@@ -47,7 +56,7 @@ func (t *TrieMoveGenerator) GenerateMoves(board model.Board, rack model.Rack) []
 				}
 			}
 		}
-		model.Transpose(t.board)
+		t.board.Transpose()
 	}
 	return moves
 }
@@ -56,14 +65,18 @@ func (t TrieMoveGenerator) generatePrefixResults(anchor *model.Tile) []partialPr
 	// Return the prefix that is already on the board if it exists
 	placedPrefixChars := make([]rune, 0)
 	for adjTile := anchor.GetAdjacentTile(t.board, 0, -1); adjTile != nil && !adjTile.Empty(); adjTile = adjTile.GetAdjacentTile(t.board, 0, -1) {
-		placedPrefixChars = append(placedPrefixChars, adjTile.Letter)
+		placedPrefixChars = append([]rune{adjTile.Letter}, placedPrefixChars...)
 	}
 	placedPrefix := string(placedPrefixChars)
 	if len(placedPrefix) > 0 {
+		prefixNode := t.trieRoot.Find(placedPrefix)
+		if prefixNode == nil {
+			return nil
+		}
 
 		// blank *placed* tiles are not blank for the purpose of moves as we
 		// don't need to use a blank tile from the rack
-		noBlankTiles := make([]bool, len(placedPrefix))
+		noBlankTiles := make([]bool, len(placedPrefixChars))
 		return []partialPrefixResult{
 			{
 				prefix: model.Word{
@@ -71,12 +84,18 @@ func (t TrieMoveGenerator) generatePrefixResults(anchor *model.Tile) []partialPr
 					BlankTiles: noBlankTiles,
 				},
 				remainingRack: t.rack.Copy(),
+				node:          prefixNode,
 			}}
 	}
 
-	// otherwise generate all valid prefixes that can be placed from
-	// the rack
-	prefixGenerator := newPrefixResultGenerator(t.rack, anchor.BoardPosition.Column)
+	// otherwise generate all valid prefixes that can be placed from the rack on the empty
+	// tiles to the left of the anchor. The prefix stops before any other anchor, as moves
+	// through that anchor are generated from it.
+	maxPrefixLength := 0
+	for adjTile := anchor.GetAdjacentTile(t.board, 0, -1); adjTile != nil && adjTile.Empty() && !adjTile.IsAnchor; adjTile = adjTile.GetAdjacentTile(t.board, 0, -1) {
+		maxPrefixLength++
+	}
+	prefixGenerator := newPrefixResultGenerator(t.rack, maxPrefixLength)
 	t.trieRoot.VisitNodesWithPruning(prefixGenerator)
 
 	return prefixGenerator.results
@@ -105,6 +124,9 @@ type prefixResultGenerator struct {
 	prefixBlanks    []bool
 	maxPrefixLength int
 	results         []partialPrefixResult
+	// depth is the length of the prefix of the node being visited, which is
+	// counted as the trie is walked rather than from the node's label
+	depth int
 }
 
 func (t *prefixResultGenerator) IsValidEdge(edge rune) bool {
@@ -112,24 +134,30 @@ func (t *prefixResultGenerator) IsValidEdge(edge rune) bool {
 }
 
 func (t *prefixResultGenerator) Terminate(node *lexicon.TrieNode) bool {
-	return len(node.Label) >= t.maxPrefixLength
+	return t.depth >= t.maxPrefixLength
 }
 
 // Visit vists a TrieNode by removing a tile from the rack and adding the prefix
-// to the set of results
+// to the set of results. The root is the empty prefix, for words starting at the anchor.
 func (t *prefixResultGenerator) Visit(node *lexicon.TrieNode) {
 	if node.IsRoot() {
+		t.results = append(t.results, partialPrefixResult{
+			prefix:        model.Word{Chars: "", BlankTiles: []bool{}},
+			remainingRack: t.rack.Copy(),
+			node:          node,
+		})
 		return
 	}
+	t.depth++
 	if t.rack.HasTile(node.IncomingEdge()) {
 		t.rack.RemoveLetter(node.IncomingEdge())
-	} else if t.rack.HasTile('*') {
-		t.rack.RemoveLetter('*')
-		t.prefixBlanks[len(node.Label)-1] = true
+	} else if t.rack.HasTile(model.BlankTile) {
+		t.rack.RemoveLetter(model.BlankTile)
+		t.prefixBlanks[t.depth-1] = true
 	}
 
-	prefixBlanks := make([]bool, len(node.Label), len(node.Label))
-	for i := 0; i < len(node.Label); i++ {
+	prefixBlanks := make([]bool, t.depth, t.depth)
+	for i := 0; i < t.depth; i++ {
 		prefixBlanks[i] = t.prefixBlanks[i]
 	}
 
@@ -150,9 +178,10 @@ func (t *prefixResultGenerator) Exit(node *lexicon.TrieNode) {
 	if node.IsRoot() {
 		return
 	}
-	if lastTileWasBlank := t.prefixBlanks[len(node.Label)-1]; lastTileWasBlank {
-		t.rack.AddLetter('*')
-		t.prefixBlanks[len(node.Label)-1] = false
+	t.depth--
+	if lastTileWasBlank := t.prefixBlanks[t.depth]; lastTileWasBlank {
+		t.rack.AddLetter(model.BlankTile)
+		t.prefixBlanks[t.depth] = false
 		return
 	}
 	t.rack.AddLetter(node.IncomingEdge())
@@ -165,7 +194,7 @@ type partialPrefixResult struct {
 }
 
 func newPrefixExtender(board model.Board, rack model.Rack, prefixBlanks []bool, prefixRoot *lexicon.TrieNode, anchor *model.Tile) *prefixExtender {
-	blanks := make([]bool, len(board.Tiles), len(board.Tiles))
+	blanks := make([]bool, board.Columns())
 	for i := 0; i < len(prefixBlanks); i++ {
 		blanks[i] = prefixBlanks[i]
 	}
@@ -175,6 +204,7 @@ func newPrefixExtender(board model.Board, rack model.Rack, prefixBlanks []bool,
 		blanks:     blanks,
 		prefixRoot: prefixRoot,
 		currTile:   anchor,
+		depth:      len(prefixBlanks),
 	}
 }
 
@@ -185,11 +215,14 @@ type prefixExtender struct {
 	prefixRoot *lexicon.TrieNode
 	blanks     []bool
 	words      []model.Word
+	// depth is the length of the word of the node being visited, counted in
+	// the same way as prefixResultGenerator.depth
+	depth int
 }
 
 func (t *prefixExtender) IsValidEdge(edge rune) bool {
 	if t.currTile.Empty() {
-		return t.rack.Contains(edge) && (t.currTile.CrossCheckSet == nil || t.currTile.CrossCheckSet[edge])
+		return t.rack.Playable().Intersect(t.currTile.CrossCheckSet).Contains(edge)
 	}
 	return edge == t.currTile.Letter
 }
@@ -204,13 +237,25 @@ func (t *prefixExtender) Visit(node *lexicon.TrieNode) {
 	if node == t.prefixRoot {
 		return
 	}
+	t.depth++
+
+	// the tile is taken from the rack before the word is added, so that the
+	// last letter of the word is marked as a blank if it needs one
+	if t.currTile.Empty() {
+		if t.rack.HasTile(node.IncomingEdge()) {
+			t.rack.RemoveLetter(node.IncomingEdge())
+		} else if t.rack.HasTile(model.BlankTile) {
+			t.rack.RemoveLetter(model.BlankTile)
+			t.blanks[t.depth-1] = true
+		}
+	}
 
 	nextTile := t.currTile.GetAdjacentTileOrSentinel(t.board, 0, 1)
 
-	// note that the sentinel square is 'empty'
-	if node.Terminal && t.currTile.Empty() {
-		blanks := make([]bool, len(node.Label))
-		for i := 0; i < len(node.Label); i++ {
+	// a word can only end if the next square is empty, note that the sentinel square is 'empty'
+	if node.Terminal && nextTile.Empty() {
+		blanks := make([]bool, t.depth)
+		for i := 0; i < t.depth; i++ {
 			blanks[i] = t.blanks[i]
 		}
 		t.words = append(
@@ -222,15 +267,6 @@ func (t *prefixExtender) Visit(node *lexicon.TrieNode) {
 		)
 	}
 
-	if t.currTile.Empty() {
-		if t.rack.HasTile(node.IncomingEdge()) {
-			t.rack.RemoveLetter(node.IncomingEdge())
-		} else if t.rack.HasTile('*') {
-			t.rack.RemoveLetter('*')
-			t.blanks[len(node.Label)-1] = true
-		}
-	}
-
 	t.currTile = nextTile
 }
 
@@ -241,17 +277,18 @@ func (t *prefixExtender) Exit(node *lexicon.TrieNode) {
 	if node == t.prefixRoot {
 		return
 	}
+	t.depth--
 
-	currTileHasLetter := t.currTile.Empty()
 	t.currTile = t.currTile.GetAdjacentTile(t.board, 0, -1)
 
-	if !currTileHasLetter {
+	// the letter was already on the board so it was not taken from the rack
+	if !t.currTile.Empty() {
 		return
 	}
 
-	if lastTileWasBlank := t.blanks[len(node.Label)-1]; lastTileWasBlank {
-		t.rack.AddLetter('*')
-		t.blanks[len(node.Label)-1] = false
+	if lastTileWasBlank := t.blanks[t.depth]; lastTileWasBlank {
+		t.rack.AddLetter(model.BlankTile)
+		t.blanks[t.depth] = false
 		return
 	}
 
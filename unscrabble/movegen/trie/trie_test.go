@@ -1,6 +1,9 @@
 package trie_test
 
 import (
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 
 	"example.com/unscrabble/lexicon"
@@ -8,14 +11,15 @@ import (
 	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
 
 	assert "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockCrossCheckSetGenerator struct{}
 
 func (m MockCrossCheckSetGenerator) ValidLettersBetweenPrefixAndSuffix(
 	prefix, suffix string,
-) map[rune]bool {
-	return nil
+) model.LetterSet {
+	return model.UnconstrainedLetterSet
 }
 
 func TestTrieMoveGeneratorGeneratesMoves(t *testing.T) {
@@ -63,3 +67,278 @@ func TestTrieMoveGeneratorGeneratesMoves(t *testing.T) {
 	}
 	assert.ElementsMatch(t, expectedMoves, moves)
 }
+
+func TestTrieMoveGeneratorGeneratesMovesWithMultiCharacterTiles(t *testing.T) {
+	emptyMultipliers := make([][]int, 5)
+	for y := range emptyMultipliers {
+		emptyMultipliers[y] = make([]int, 5)
+	}
+	testBoard := model.NewBoard(MockCrossCheckSetGenerator{}, emptyMultipliers, emptyMultipliers)
+
+	alphabet, err := model.NewAlphabet([]string{"a", "c", "ch", "e", "l", "ll", "o"})
+	require.NoError(t, err)
+	testTrieRoot := lexicon.NewTrieNode()
+	require.NoError(t, testTrieRoot.InsertWordsFromReader(strings.NewReader("calle\n"), alphabet))
+
+	testRack, err := alphabet.ParseRack("[LL]EAC", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+	require.Len(t, moves, 4)
+	for _, move := range moves {
+		assert.Equal(t, "calle", alphabet.Render(move.Word.Chars))
+		assert.Equal(t, 4, move.Word.Length())
+		assert.Len(t, move.Word.BlankTiles, 4)
+	}
+}
+
+func TestTrieMoveGeneratorGeneratesMovesOnRectangularBoard(t *testing.T) {
+	emptyMultipliers := make([][]int, 2)
+	for y := range emptyMultipliers {
+		emptyMultipliers[y] = make([]int, 6)
+	}
+	testBoard := model.NewBoard(
+		MockCrossCheckSetGenerator{}, emptyMultipliers, emptyMultipliers, model.Position{Row: 1, Column: 4},
+	)
+
+	testTrieRoot := lexicon.NewTrieNode()
+	testTrieRoot.Insert("at")
+	testTrieRoot.Insert("tea")
+
+	testRack, err := model.ParseRack("TEA", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+
+	newMove := func(row, column int, horizontal bool, chars string) model.Move {
+		return model.Move{
+			StartPosition: &model.Position{Row: row, Column: column},
+			Horizontal:    horizontal,
+			Word:          model.Word{Chars: chars, BlankTiles: make([]bool, len(chars))},
+		}
+	}
+	expectedMoves := []model.Move{
+		newMove(1, 3, true, "at"),
+		newMove(1, 4, true, "at"),
+		newMove(1, 2, true, "tea"),
+		newMove(1, 3, true, "tea"),
+		newMove(0, 4, false, "at"),
+	}
+	assert.ElementsMatch(t, expectedMoves, moves)
+}
+
+func TestTrieMoveGeneratorGeneratesMovesThroughPlacedTiles(t *testing.T) {
+	emptyMultipliers := make([][]int, 3)
+	for y := range emptyMultipliers {
+		emptyMultipliers[y] = make([]int, 6)
+	}
+	testBoard := model.NewBoard(MockCrossCheckSetGenerator{}, emptyMultipliers, emptyMultipliers)
+	testBoard.Tiles[1][3].IsAnchor = false
+	for x, letter := range "at" {
+		testBoard.Tiles[1][2+x].Letter = letter
+	}
+	for _, anchor := range model.GetAnchors(testBoard) {
+		anchor.IsAnchor = true
+	}
+
+	testTrieRoot := lexicon.NewTrieNode()
+	testTrieRoot.Insert("cat")
+	testTrieRoot.Insert("ats")
+
+	testRack, err := model.ParseRack("CS", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+	expectedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "cat", BlankTiles: make([]bool, 3)},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 2},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "ats", BlankTiles: make([]bool, 3)},
+		},
+	}
+	assert.ElementsMatch(t, expectedMoves, moves)
+}
+
+func TestTrieMoveGeneratorFollowsFirstMoveRules(t *testing.T) {
+	emptyMultipliers := make([][]int, 3)
+	for y := range emptyMultipliers {
+		emptyMultipliers[y] = make([]int, 3)
+	}
+	testBoard := model.NewBoardWithRules(
+		MockCrossCheckSetGenerator{},
+		emptyMultipliers,
+		emptyMultipliers,
+		model.FirstMoveRules{
+			StartSquares:  []model.Position{{Row: 0, Column: 0}},
+			MinWordLength: 3,
+		},
+	)
+
+	testTrieRoot := lexicon.NewTrieNode()
+	testTrieRoot.Insert("at")
+	testTrieRoot.Insert("cat")
+
+	testRack, err := model.ParseRack("ACT", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+	expectedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 0, Column: 0},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "cat", BlankTiles: make([]bool, 3)},
+		},
+		{
+			StartPosition: &model.Position{Row: 0, Column: 0},
+			Horizontal:    false,
+			Word:          model.Word{Chars: "cat", BlankTiles: make([]bool, 3)},
+		},
+	}
+	assert.ElementsMatch(t, expectedMoves, moves)
+}
+
+func TestTrieMoveGeneratorGeneratesMovesOnTextBoard(t *testing.T) {
+	testTrieRoot := lexicon.NewTrieNode()
+	for _, word := range []string{"cat", "cats", "as"} {
+		testTrieRoot.Insert(word)
+	}
+	testBoard, err := model.EnglishAlphabet.ParseBoard(`
+	   A B C D E
+	1  . . . . .
+	2  . C A T .
+	3  . . . . .
+	`, testTrieRoot, nil)
+	require.NoError(t, err)
+
+	testRack, err := model.ParseRack("S", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+	expectedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "cats", BlankTiles: make([]bool, 4)},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 2},
+			Horizontal:    false,
+			Word:          model.Word{Chars: "as", BlankTiles: make([]bool, 2)},
+		},
+	}
+	assert.ElementsMatch(t, expectedMoves, moves)
+}
+
+func TestTrieMoveGeneratorMarksBlankAsLastLetter(t *testing.T) {
+	testTrieRoot := lexicon.NewTrieNode()
+	testTrieRoot.Insert("er")
+	testBoard, err := model.EnglishAlphabet.ParseBoard(`
+	. . .
+	. E .
+	. . .
+	`, testTrieRoot, nil)
+	require.NoError(t, err)
+
+	testRack, err := model.ParseRack("?", 7, nil)
+	require.NoError(t, err)
+
+	testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+	moves := testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+	expectedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "er", BlankTiles: []bool{false, true}},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    false,
+			Word:          model.Word{Chars: "er", BlankTiles: []bool{false, true}},
+		},
+	}
+	assert.ElementsMatch(t, expectedMoves, moves)
+}
+
+// benchmarkTrie creates a trie of pseudo-random words over common letters so
+// that the benchmarks explore a realistic number of prefixes.
+func benchmarkTrie() *lexicon.TrieNode {
+	const letters = "aeiourstlndcmpbgh"
+	random := rand.New(rand.NewSource(1))
+	trieRoot := lexicon.NewTrieNode()
+	for i := 0; i < 20000; i++ {
+		word := make([]byte, 2+random.Intn(6))
+		for j := range word {
+			word[j] = letters[random.Intn(len(letters))]
+		}
+		trieRoot.Insert(string(word))
+	}
+	return trieRoot
+}
+
+func BenchmarkGenerateMovesEmptyBoard(b *testing.B) {
+	multipliers := make([][]int, 15)
+	for y := range multipliers {
+		multipliers[y] = make([]int, 15)
+	}
+	board := model.NewBoard(MockCrossCheckSetGenerator{}, multipliers, multipliers)
+	trieMoveGen := triemovegen.NewTrieMoveGenertator(benchmarkTrie())
+
+	for _, notation := range []string{"RETAINS", "AEILST?"} {
+		rack, err := model.ParseRack(notation, 7, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(notation, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				trieMoveGen.GenerateMoves(board, *rack)
+			}
+		})
+	}
+}
+
+func TestTrieMoveGeneratorSharesBoardBetweenGoroutines(t *testing.T) {
+	emptyMultipliers := make([][]int, 2)
+	for y := range emptyMultipliers {
+		emptyMultipliers[y] = make([]int, 6)
+	}
+	testBoard := model.NewBoard(
+		MockCrossCheckSetGenerator{}, emptyMultipliers, emptyMultipliers, model.Position{Row: 1, Column: 4},
+	)
+	before := testBoard.Copy()
+
+	testTrieRoot := lexicon.NewTrieNode()
+	testTrieRoot.Insert("at")
+	testTrieRoot.Insert("tea")
+	testRack, err := model.ParseRack("TEA", 7, nil)
+	require.NoError(t, err)
+
+	// each goroutine has its own generator but they all share the board
+	moves := make([][]model.Move, 4)
+	var wg sync.WaitGroup
+	for i := range moves {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			testTrieMoveGen := triemovegen.NewTrieMoveGenertator(testTrieRoot)
+			moves[i] = testTrieMoveGen.GenerateMoves(testBoard, *testRack)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, before, testBoard)
+	for _, generated := range moves[1:] {
+		assert.ElementsMatch(t, moves[0], generated)
+	}
+	assert.Len(t, moves[0], 5)
+}
@@ -0,0 +1,44 @@
+package rating
+
+import "math"
+
+// InitialElo is the Elo rating of a player that has not played
+const InitialElo = 1500
+
+// DefaultK is the K factor of the Elo ratings, the most a rating can change by
+// in one game. It is low because bots play many games and do not improve
+// between them.
+const DefaultK = 16
+
+// Elo updates Elo ratings game by game
+type Elo struct {
+	K float64
+}
+
+// expected returns the expected score of a player rated rating against an
+// opponent rated opponent
+func expected(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/400))
+}
+
+// Update returns the ratings after the results, in order. Players without a
+// rating start at InitialElo. The ratings given are not changed.
+func (e Elo) Update(ratings map[string]float64, results []Result) map[string]float64 {
+	updated := make(map[string]float64, len(ratings))
+	for name, rating := range ratings {
+		updated[name] = rating
+	}
+	rating := func(name string) float64 {
+		if rating, ok := updated[name]; ok {
+			return rating
+		}
+		return InitialElo
+	}
+	for _, result := range results {
+		player, opponent := rating(result.Player), rating(result.Opponent)
+		change := e.K * (result.Score - expected(player, opponent))
+		updated[result.Player] = player + change
+		updated[result.Opponent] = opponent - change
+	}
+	return updated
+}
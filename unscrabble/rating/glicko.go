@@ -0,0 +1,143 @@
+package rating
+
+import "math"
+
+// The rating, deviation and volatility of a player that has not played
+const (
+	InitialGlicko     = 1500
+	InitialDeviation  = 350
+	InitialVolatility = 0.06
+)
+
+// DefaultTau is the Glicko-2 system constant, which limits how quickly the
+// volatility of a rating changes
+const DefaultTau = 0.5
+
+// glickoScale converts between Glicko ratings and the Glicko-2 scale
+const glickoScale = 173.7178
+
+// convergence is the tolerance of the iteration for the new volatility
+const convergence = 0.000001
+
+// Glicko is a Glicko-2 rating. Deviation is the uncertainty of the rating,
+// which falls as the player plays more games, and Volatility is how much the
+// player's strength is expected to vary.
+type Glicko struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// NewGlicko returns the rating of a player that has not played
+func NewGlicko() Glicko {
+	return Glicko{Rating: InitialGlicko, Deviation: InitialDeviation, Volatility: InitialVolatility}
+}
+
+// Glicko2 updates Glicko-2 ratings a rating period at a time, as described in
+// Mark Glickman's "Example of the Glicko-2 system"
+type Glicko2 struct {
+	Tau float64
+}
+
+// Update returns the ratings after a rating period with the results. Every
+// player's rating is updated from the ratings at the start of the period, and
+// players without a rating start with NewGlicko. The ratings of players
+// without any results are not changed: their deviation is not increased, as a
+// bot's strength does not change while it is not playing. The ratings given
+// are not changed.
+func (g Glicko2) Update(ratings map[string]Glicko, results []Result) map[string]Glicko {
+	rating := func(name string) Glicko {
+		if rating, ok := ratings[name]; ok {
+			return rating
+		}
+		return NewGlicko()
+	}
+	byPlayer := make(map[string][]Result)
+	for _, result := range results {
+		byPlayer[result.Player] = append(byPlayer[result.Player], result)
+		byPlayer[result.Opponent] = append(byPlayer[result.Opponent], result.reverse())
+	}
+
+	updated := make(map[string]Glicko, len(ratings)+len(byPlayer))
+	for name, rating := range ratings {
+		updated[name] = rating
+	}
+	for name, results := range byPlayer {
+		opponents := make([]Glicko, len(results))
+		for i, result := range results {
+			opponents[i] = rating(result.Opponent)
+		}
+		updated[name] = g.update(rating(name), opponents, results)
+	}
+	return updated
+}
+
+// update returns a player's rating after their results against opponents
+func (g Glicko2) update(player Glicko, opponents []Glicko, results []Result) Glicko {
+	mu := (player.Rating - InitialGlicko) / glickoScale
+	phi := player.Deviation / glickoScale
+
+	// v is the estimated variance of the rating from the results, and
+	// improvement is the sum that, scaled by v, is the estimated improvement
+	var inverseV, improvement float64
+	for i, opponent := range opponents {
+		muJ := (opponent.Rating - InitialGlicko) / glickoScale
+		gJ := glickoG(opponent.Deviation / glickoScale)
+		e := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+		inverseV += gJ * gJ * e * (1 - e)
+		improvement += gJ * (results[i].Score - e)
+	}
+	v := 1 / inverseV
+	delta := v * improvement
+
+	volatility := g.volatility(phi, player.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + volatility*volatility)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*improvement
+	return Glicko{
+		Rating:     newMu*glickoScale + InitialGlicko,
+		Deviation:  newPhi * glickoScale,
+		Volatility: volatility,
+	}
+}
+
+// glickoG reduces the weight of a result against an opponent by the
+// uncertainty of the opponent's rating
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// volatility returns the new volatility of a rating, found with the Illinois
+// algorithm
+func (g Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+	}
+
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+v {
+		upper = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		upper = a - k*g.Tau
+	}
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > convergence {
+		next := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fNext := f(next)
+		if fNext*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = next, fNext
+	}
+	return math.Exp(lower / 2)
+}
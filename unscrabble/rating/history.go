@@ -0,0 +1,189 @@
+package rating
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"example.com/unscrabble/unscrabble/tournament"
+)
+
+// Rating is a player's ratings, and the number of games they have been rated
+// on
+type Rating struct {
+	Name   string  `json:"name"`
+	Games  int     `json:"games"`
+	Elo    float64 `json:"elo"`
+	Glicko Glicko  `json:"glicko"`
+}
+
+// Period is a rating period, the games of a tournament. Ratings are the
+// ratings at the end of the period of the players who played in it, in order
+// of name.
+type Period struct {
+	Time    time.Time `json:"time"`
+	Seed    int64     `json:"seed"`
+	Games   int       `json:"games"`
+	Ratings []Rating  `json:"ratings"`
+}
+
+// History is the rating periods of players so far. It is stored as JSON
+// lines, a period on each line, so a period is added by appending a line.
+type History struct {
+	Periods []Period
+	Elo     Elo
+	Glicko  Glicko2
+}
+
+// NewHistory returns an empty history rated with the default K factor and
+// system constant
+func NewHistory() *History {
+	return &History{Elo: Elo{K: DefaultK}, Glicko: Glicko2{Tau: DefaultTau}}
+}
+
+// ReadHistory reads a history from r
+func ReadHistory(r io.Reader) (*History, error) {
+	history := NewHistory()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var period Period
+		if err := json.Unmarshal(scanner.Bytes(), &period); err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+		history.Periods = append(history.Periods, period)
+	}
+	return history, scanner.Err()
+}
+
+// LoadHistory reads the history in the file at path, which is empty if the
+// file does not exist
+func LoadHistory(path string) (*History, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return NewHistory(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	history, err := ReadHistory(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return history, nil
+}
+
+// AppendPeriod adds a period to the end of the history in the file at path,
+// creating it if it does not exist
+func AppendPeriod(path string, period Period) error {
+	data, err := json.Marshal(period)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Ratings returns the latest rating of every player in the history
+func (h *History) Ratings() map[string]Rating {
+	ratings := make(map[string]Rating)
+	for _, period := range h.Periods {
+		for _, rating := range period.Ratings {
+			ratings[rating.Name] = rating
+		}
+	}
+	return ratings
+}
+
+// Rate rates the players of a tournament from its games, as a rating period
+// that ended at end, and adds the period to the history
+func (h *History) Rate(report *tournament.Report, end time.Time) Period {
+	current := h.Ratings()
+	elo := make(map[string]float64, len(current))
+	glicko := make(map[string]Glicko, len(current))
+	for name, rating := range current {
+		elo[name] = rating.Elo
+		glicko[name] = rating.Glicko
+	}
+	results := Results(report.Games)
+	elo = h.Elo.Update(elo, results)
+	glicko = h.Glicko.Update(glicko, results)
+
+	games := make(map[string]int)
+	for _, game := range report.Games {
+		for _, player := range game.Players {
+			games[player.Entrant]++
+		}
+	}
+	period := Period{Time: end, Seed: report.Seed, Games: len(report.Games)}
+	for name, n := range games {
+		period.Ratings = append(period.Ratings, Rating{
+			Name:   name,
+			Games:  current[name].Games + n,
+			Elo:    elo[name],
+			Glicko: glicko[name],
+		})
+	}
+	sort.Slice(period.Ratings, func(i, j int) bool {
+		return period.Ratings[i].Name < period.Ratings[j].Name
+	})
+	h.Periods = append(h.Periods, period)
+	return period
+}
+
+// Standing is a player's place on the leaderboard. The changes are since the
+// player's previous rating period, and Updated is the time of their latest.
+type Standing struct {
+	Rating
+	EloChange    float64   `json:"elo_change"`
+	GlickoChange float64   `json:"glicko_change"`
+	Updated      time.Time `json:"updated"`
+}
+
+// Leaderboard returns the standings of the players in the history, from the
+// highest Glicko-2 rating
+func (h *History) Leaderboard() []Standing {
+	standings := make(map[string]*Standing)
+	for _, period := range h.Periods {
+		for _, rating := range period.Ratings {
+			standing := standings[rating.Name]
+			if standing == nil {
+				standings[rating.Name] = &Standing{Rating: rating, Updated: period.Time}
+				continue
+			}
+			standing.EloChange = rating.Elo - standing.Elo
+			standing.GlickoChange = rating.Glicko.Rating - standing.Glicko.Rating
+			standing.Rating = rating
+			standing.Updated = period.Time
+		}
+	}
+
+	leaderboard := make([]Standing, 0, len(standings))
+	for _, standing := range standings {
+		leaderboard = append(leaderboard, *standing)
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Glicko.Rating != leaderboard[j].Glicko.Rating {
+			return leaderboard[i].Glicko.Rating > leaderboard[j].Glicko.Rating
+		}
+		return leaderboard[i].Name < leaderboard[j].Name
+	})
+	return leaderboard
+}
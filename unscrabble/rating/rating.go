@@ -0,0 +1,41 @@
+// Package rating rates strategies from the games they play in tournaments,
+// with the Elo and Glicko-2 rating systems. The ratings are kept in a history
+// of the rating periods, one for each tournament, so a change to a strategy
+// that makes it play worse shows up as a fall in its rating.
+package rating
+
+import "example.com/unscrabble/unscrabble/tournament"
+
+// Result is the result of a game between two players, from the point of view
+// of the first. Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+type Result struct {
+	Player   string
+	Opponent string
+	Score    float64
+}
+
+// Results returns the results of tournament games, in the order in which they
+// were played. A game of more than two players is counted as a game between
+// each pair of its players.
+func Results(games []tournament.GameResult) []Result {
+	var results []Result
+	for _, game := range games {
+		for i, player := range game.Players {
+			for _, opponent := range game.Players[i+1:] {
+				score := 0.5
+				if player.Score > opponent.Score {
+					score = 1
+				} else if player.Score < opponent.Score {
+					score = 0
+				}
+				results = append(results, Result{Player: player.Entrant, Opponent: opponent.Entrant, Score: score})
+			}
+		}
+	}
+	return results
+}
+
+// reverse returns the result from the point of view of the opponent
+func (r Result) reverse() Result {
+	return Result{Player: r.Opponent, Opponent: r.Player, Score: 1 - r.Score}
+}
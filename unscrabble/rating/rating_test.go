@@ -0,0 +1,110 @@
+package rating_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/unscrabble/unscrabble/rating"
+	"example.com/unscrabble/unscrabble/tournament"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlicko2MatchesGlickmansExample(t *testing.T) {
+	ratings := map[string]rating.Glicko{
+		"player": {Rating: 1500, Deviation: 200, Volatility: 0.06},
+		"a":      {Rating: 1400, Deviation: 30, Volatility: 0.06},
+		"b":      {Rating: 1550, Deviation: 100, Volatility: 0.06},
+		"c":      {Rating: 1700, Deviation: 300, Volatility: 0.06},
+		"idle":   {Rating: 1600, Deviation: 50, Volatility: 0.06},
+	}
+	results := []rating.Result{
+		{Player: "player", Opponent: "a", Score: 1},
+		{Player: "b", Opponent: "player", Score: 1},
+		{Player: "player", Opponent: "c", Score: 0},
+	}
+	updated := rating.Glicko2{Tau: 0.5}.Update(ratings, results)
+	player := updated["player"]
+	assert.InDelta(t, 1464.06, player.Rating, 0.01)
+	assert.InDelta(t, 151.52, player.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, player.Volatility, 0.00001)
+	assert.Equal(t, ratings["idle"], updated["idle"])
+	assert.Equal(t, 1500.0, ratings["player"].Rating)
+}
+
+func TestEloUpdatesGameByGame(t *testing.T) {
+	elo := rating.Elo{K: 16}
+	updated := elo.Update(nil, []rating.Result{{Player: "a", Opponent: "b", Score: 1}})
+	assert.Equal(t, map[string]float64{"a": 1508, "b": 1492}, updated)
+
+	updated = elo.Update(updated, []rating.Result{{Player: "a", Opponent: "b", Score: 0.5}})
+	assert.InDelta(t, 1507.63, updated["a"], 0.01)
+	assert.InDelta(t, 3000, updated["a"]+updated["b"], 0.0001)
+}
+
+func TestResultsPairPlayers(t *testing.T) {
+	games := []tournament.GameResult{
+		{Players: []tournament.PlayerResult{{Entrant: "a", Score: 10}, {Entrant: "b", Score: 10}, {Entrant: "c", Score: 12}}},
+	}
+	assert.Equal(t, []rating.Result{
+		{Player: "a", Opponent: "b", Score: 0.5},
+		{Player: "a", Opponent: "c", Score: 0},
+		{Player: "b", Opponent: "c", Score: 0},
+	}, rating.Results(games))
+}
+
+func TestHistoryIsStoredAndRanked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.jsonl")
+	history, err := rating.LoadHistory(path)
+	require.NoError(t, err)
+	assert.Empty(t, history.Leaderboard())
+
+	game := func(winner, loser string) tournament.GameResult {
+		return tournament.GameResult{Players: []tournament.PlayerResult{
+			{Entrant: winner, Score: 300},
+			{Entrant: loser, Score: 200},
+		}}
+	}
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	period := history.Rate(&tournament.Report{Seed: 1, Games: []tournament.GameResult{
+		game("highscore", "random"), game("highscore", "random"), game("random", "highscore"),
+	}}, first)
+	require.NoError(t, rating.AppendPeriod(path, period))
+	period = history.Rate(&tournament.Report{Seed: 2, Games: []tournament.GameResult{
+		game("random", "highscore"), game("equity", "random"),
+	}}, first.Add(time.Hour))
+	require.NoError(t, rating.AppendPeriod(path, period))
+	assert.Equal(t, 2, period.Games)
+	require.Len(t, period.Ratings, 3)
+	assert.Equal(t, "equity", period.Ratings[0].Name)
+
+	loaded, err := rating.LoadHistory(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Periods, 2)
+	assert.Equal(t, history.Ratings(), loaded.Ratings())
+
+	leaderboard := loaded.Leaderboard()
+	require.Len(t, leaderboard, 3)
+	standings := make(map[string]rating.Standing)
+	for i, standing := range leaderboard {
+		standings[standing.Name] = standing
+		if i > 0 {
+			assert.GreaterOrEqual(t, leaderboard[i-1].Glicko.Rating, standing.Glicko.Rating)
+		}
+	}
+	highscore := standings["highscore"]
+	assert.Equal(t, 4, highscore.Games)
+	assert.Less(t, highscore.GlickoChange, 0.0)
+	assert.Less(t, highscore.EloChange, 0.0)
+	assert.Equal(t, first.Add(time.Hour), highscore.Updated)
+	assert.Equal(t, 5, standings["random"].Games)
+	assert.Zero(t, standings["equity"].GlickoChange)
+}
+
+func TestReadHistoryReportsBadLines(t *testing.T) {
+	_, err := rating.ReadHistory(strings.NewReader("{}\n\nnot json\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+}
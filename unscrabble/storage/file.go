@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	snapshotFile = "snapshot.json"
+	eventsFile   = "events.jsonl"
+)
+
+// FileStore is a Store that keeps each game in a directory named after its
+// id. The directory has the game's latest snapshot, and an append-only log of
+// the events after it with one JSON event per line. Files are replaced by
+// renaming a complete new file over them, and appended events are synced to
+// disk before Append returns, so a crash loses at most the events that were
+// being appended. A partly written event at the end of a log is discarded
+// when the game is next loaded.
+type FileStore struct {
+	dir string
+
+	mutex sync.Mutex
+	// lastSeq caches the number of the last event of each game which has
+	// been appended to or loaded
+	lastSeq map[string]int
+}
+
+// NewFileStore returns a FileStore keeping games in dir, which is created if
+// it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, lastSeq: make(map[string]int)}, nil
+}
+
+// path returns the path of a file of a game, checking that the id is a single
+// path element
+func (s *FileStore) path(id, name string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid game id %q", id)
+	}
+	return filepath.Join(s.dir, id, name), nil
+}
+
+// SaveSnapshot implements Store. The events included in the snapshot are
+// removed from the log by rewriting it.
+func (s *FileStore) SaveSnapshot(id string, snapshot Snapshot) error {
+	path, err := s.path(id, snapshotFile)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := writeFile(path, append(encoded, '\n')); err != nil {
+		return err
+	}
+
+	// a crash before the log is rewritten leaves events in it which are in the
+	// snapshot, and these are skipped by Load
+	events, err := s.readEvents(id)
+	if err != nil {
+		return err
+	}
+	var log bytes.Buffer
+	lastSeq := snapshot.Seq
+	for _, event := range events {
+		if event.Seq > snapshot.Seq {
+			if err := encodeEvent(&log, event); err != nil {
+				return err
+			}
+			lastSeq = event.Seq
+		}
+	}
+	eventsPath, _ := s.path(id, eventsFile)
+	if err := writeFile(eventsPath, log.Bytes()); err != nil {
+		return err
+	}
+	s.lastSeq[id] = lastSeq
+	return nil
+}
+
+// Append implements Store
+func (s *FileStore) Append(id string, events ...Event) error {
+	path, err := s.path(id, eventsFile)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lastSeq, ok := s.lastSeq[id]
+	if !ok {
+		snapshot, err := s.readSnapshot(id)
+		if err != nil {
+			return err
+		}
+		stored, err := s.readEvents(id)
+		if err != nil {
+			return err
+		}
+		lastSeq = snapshot.Seq
+		if len(stored) > 0 && stored[len(stored)-1].Seq > lastSeq {
+			lastSeq = stored[len(stored)-1].Seq
+		}
+	}
+	if err := checkSeq(lastSeq, events); err != nil {
+		return err
+	}
+
+	var log bytes.Buffer
+	for _, event := range events {
+		if err := encodeEvent(&log, event); err != nil {
+			return err
+		}
+	}
+	if err := appendFile(path, log.Bytes()); err != nil {
+		// the log may end with part of an event, which is cut off when it is
+		// next read
+		delete(s.lastSeq, id)
+		return err
+	}
+	if len(events) > 0 {
+		lastSeq = events[len(events)-1].Seq
+	}
+	s.lastSeq[id] = lastSeq
+	return nil
+}
+
+// Load implements Store
+func (s *FileStore) Load(id string) (Snapshot, []Event, error) {
+	if _, err := s.path(id, snapshotFile); err != nil {
+		return Snapshot{}, nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snapshot, err := s.readSnapshot(id)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	stored, err := s.readEvents(id)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	var events []Event
+	for _, event := range stored {
+		if event.Seq > snapshot.Seq {
+			events = append(events, event)
+		}
+	}
+	if err := checkSeq(snapshot.Seq, events); err != nil {
+		return Snapshot{}, nil, fmt.Errorf("game %v: %w", id, err)
+	}
+	s.lastSeq[id] = snapshot.Seq + len(events)
+	return snapshot, events, nil
+}
+
+// IDs implements Store. Directories without a snapshot are not games.
+func (s *FileStore) IDs() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.dir, entry.Name(), snapshotFile)); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(id string) error {
+	path, err := s.path(id, snapshotFile)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	delete(s.lastSeq, id)
+	return os.RemoveAll(filepath.Dir(path))
+}
+
+func (s *FileStore) readSnapshot(id string) (Snapshot, error) {
+	path, _ := s.path(id, snapshotFile)
+	encoded, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, ErrNotFound
+	} else if err != nil {
+		return Snapshot{}, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(encoded, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("%v: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// readEvents reads the log of a game. A last line that is not terminated was
+// being written when the process stopped, and is cut off the log so that
+// later events are appended after the complete events.
+func (s *FileStore) readEvents(id string) ([]Event, error) {
+	path, _ := s.path(id, eventsFile)
+	log, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	complete := bytes.LastIndexByte(log, '\n') + 1
+	if complete < len(log) {
+		if err := os.Truncate(path, int64(complete)); err != nil {
+			return nil, err
+		}
+		log = log[:complete]
+	}
+
+	var events []Event
+	for i, line := range bytes.Split(log, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", path, i+1, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func encodeEvent(log *bytes.Buffer, event Event) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	log.Write(encoded)
+	log.WriteByte('\n')
+	return nil
+}
+
+// appendFile appends data to the file at path and syncs it to disk
+func appendFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeFile replaces the file at path with data by writing a temporary file
+// and renaming it, so the file is never left partly written
+func writeFile(path string, data []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps games in memory, e.g. for tests. Games
+// are kept in their JSON encoding, so what is loaded never shares memory with
+// what was stored.
+type MemoryStore struct {
+	mutex sync.Mutex
+	games map[string]*memoryGame
+}
+
+type memoryGame struct {
+	snapshot []byte
+	lastSeq  int
+	events   [][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{games: make(map[string]*memoryGame)}
+}
+
+// SaveSnapshot implements Store
+func (s *MemoryStore) SaveSnapshot(id string, snapshot Snapshot) error {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	game, ok := s.games[id]
+	if !ok {
+		game = &memoryGame{}
+		s.games[id] = game
+	}
+	var events [][]byte
+	for _, encodedEvent := range game.events {
+		var event Event
+		if err := json.Unmarshal(encodedEvent, &event); err != nil {
+			return err
+		}
+		if event.Seq > snapshot.Seq {
+			events = append(events, encodedEvent)
+		}
+	}
+	game.snapshot = encoded
+	game.events = events
+	if len(events) == 0 {
+		game.lastSeq = snapshot.Seq
+	}
+	return nil
+}
+
+// Append implements Store
+func (s *MemoryStore) Append(id string, events ...Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	game, ok := s.games[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := checkSeq(game.lastSeq, events); err != nil {
+		return err
+	}
+	for _, event := range events {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		game.events = append(game.events, encoded)
+		game.lastSeq = event.Seq
+	}
+	return nil
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(id string) (Snapshot, []Event, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	game, ok := s.games[id]
+	if !ok {
+		return Snapshot{}, nil, ErrNotFound
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(game.snapshot, &snapshot); err != nil {
+		return Snapshot{}, nil, err
+	}
+	events := make([]Event, len(game.events))
+	for i, encoded := range game.events {
+		if err := json.Unmarshal(encoded, &events[i]); err != nil {
+			return Snapshot{}, nil, err
+		}
+	}
+	return snapshot, events, nil
+}
+
+// IDs implements Store
+func (s *MemoryStore) IDs() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ids := make([]string, 0, len(s.games))
+	for id := range s.games {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.games[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.games, id)
+	return nil
+}
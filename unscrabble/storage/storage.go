@@ -0,0 +1,217 @@
+// Package storage stores games so that they survive a restart. A stored game
+// is a snapshot of its state followed by a log of the actions taken since the
+// snapshot, from which the game is reconstructed exactly, including the tiles
+// that were drawn from the bag. The log is compacted by replacing it with a
+// new snapshot from time to time.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// SnapshotInterval is the number of events after which a Recorder replaces the
+// log of a game with a snapshot
+const SnapshotInterval = 64
+
+// ErrNotFound is returned for games that are not stored
+var ErrNotFound = errors.New("game not found")
+
+// Snapshot is the state of a game after the event numbered Seq, which is 0 if
+// the snapshot was taken before any events were stored. The metadata belongs
+// to the owner of the game, e.g. the seats of a hosted game, and is kept with
+// the snapshot without being interpreted.
+type Snapshot struct {
+	Seq      int             `json:"seq"`
+	Game     model.GameJSON  `json:"game"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Event is an action taken in a game. The events of a game are numbered from
+// one after the sequence number of its first snapshot.
+type Event struct {
+	Seq    int              `json:"seq"`
+	Action model.ActionJSON `json:"action"`
+}
+
+// Store stores the snapshots and events of games by their ids. A Store must be
+// safe for concurrent use.
+type Store interface {
+	// SaveSnapshot stores a snapshot of a game, which is created if it is not
+	// stored. The snapshot replaces the earlier snapshot of the game and the
+	// events it includes.
+	SaveSnapshot(id string, snapshot Snapshot) error
+	// Append adds events to the log of a game. The first event must follow
+	// the last event stored, or the snapshot if there are no events after it.
+	Append(id string, events ...Event) error
+	// Load returns the latest snapshot of a game and the events after it
+	Load(id string) (Snapshot, []Event, error)
+	// IDs returns the ids of the stored games in order
+	IDs() ([]string, error)
+	// Delete removes a game and its events
+	Delete(id string) error
+}
+
+// checkSeq returns an error if events are not numbered consecutively after
+// last
+func checkSeq(last int, events []Event) error {
+	for _, event := range events {
+		if event.Seq != last+1 {
+			return fmt.Errorf("event %v does not follow event %v", event.Seq, last)
+		}
+		last = event.Seq
+	}
+	return nil
+}
+
+// Replay reconstructs a game from a snapshot and the events after it. The
+// players must have the names of the players in the snapshot.
+func Replay(snapshot Snapshot, events []Event, lexicon model.Lexicon, players ...*model.Player) (*model.Game, error) {
+	if err := checkSeq(snapshot.Seq, events); err != nil {
+		return nil, err
+	}
+	alphabet, err := snapshot.Game.Configuration.NewAlphabet()
+	if err != nil {
+		return nil, err
+	}
+	game, err := model.RestoreGame(snapshot.Game, lexicon, players...)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		action, err := alphabet.DecodeAction(event.Action)
+		if err != nil {
+			return nil, fmt.Errorf("event %v: %w", event.Seq, err)
+		}
+		if err := game.Apply(action); err != nil {
+			return nil, fmt.Errorf("event %v: %w", event.Seq, err)
+		}
+	}
+	return game, nil
+}
+
+// Recorder stores the actions taken in a game as they are taken
+type Recorder struct {
+	store    Store
+	id       string
+	game     *model.Game
+	alphabet *model.Alphabet
+	metadata json.RawMessage
+	// seq is the number of the last event stored, and snapshotSeq is the
+	// number of the last event in the latest snapshot
+	seq         int
+	snapshotSeq int
+	// recorded is the number of the game's actions that have been stored
+	recorded int
+}
+
+// NewRecorder stores a snapshot of game as it is now, with metadata, and
+// returns a recorder for the actions taken in it from now on
+func NewRecorder(store Store, id string, game *model.Game, metadata json.RawMessage) (*Recorder, error) {
+	recorder := &Recorder{
+		store:    store,
+		id:       id,
+		game:     game,
+		metadata: metadata,
+		recorded: len(game.Actions()),
+	}
+	if err := recorder.init(); err != nil {
+		return nil, err
+	}
+	if err := recorder.Snapshot(); err != nil {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// Restore reconstructs a game from the snapshot and events loaded from store,
+// and returns a recorder that goes on storing the game's actions
+func Restore(
+	store Store,
+	id string,
+	snapshot Snapshot,
+	events []Event,
+	lexicon model.Lexicon,
+	players ...*model.Player,
+) (*Recorder, error) {
+	game, err := Replay(snapshot, events, lexicon, players...)
+	if err != nil {
+		return nil, err
+	}
+	recorder := &Recorder{
+		store:       store,
+		id:          id,
+		game:        game,
+		metadata:    snapshot.Metadata,
+		seq:         snapshot.Seq + len(events),
+		snapshotSeq: snapshot.Seq,
+		recorded:    len(game.Actions()),
+	}
+	return recorder, recorder.init()
+}
+
+func (r *Recorder) init() error {
+	config := r.game.JSON(model.AllPlayers).Configuration
+	alphabet, err := config.NewAlphabet()
+	if err != nil {
+		return err
+	}
+	r.alphabet = alphabet
+	return nil
+}
+
+// Game returns the recorded game
+func (r *Recorder) Game() *model.Game {
+	return r.game
+}
+
+// Metadata returns the metadata stored with the game
+func (r *Recorder) Metadata() json.RawMessage {
+	return r.metadata
+}
+
+// Record stores the actions taken in the game since it was last recorded. If
+// storing them fails they are stored by the next call to Record. The log is
+// replaced with a snapshot once it has SnapshotInterval events.
+func (r *Recorder) Record() error {
+	actions := r.game.Actions()[r.recorded:]
+	if len(actions) == 0 {
+		return nil
+	}
+	events := make([]Event, len(actions))
+	for i, action := range actions {
+		events[i] = Event{Seq: r.seq + i + 1, Action: r.alphabet.EncodeAction(action)}
+	}
+	if err := r.store.Append(r.id, events...); err != nil {
+		return err
+	}
+	r.seq += len(events)
+	r.recorded += len(events)
+	if r.seq-r.snapshotSeq >= SnapshotInterval {
+		return r.Snapshot()
+	}
+	return nil
+}
+
+// SetMetadata replaces the metadata stored with the game, and records its
+// actions in a snapshot
+func (r *Recorder) SetMetadata(metadata json.RawMessage) error {
+	r.metadata = metadata
+	return r.Snapshot()
+}
+
+// Snapshot replaces the log of the game with a snapshot of its state
+func (r *Recorder) Snapshot() error {
+	seq := r.seq + len(r.game.Actions()) - r.recorded
+	snapshot := Snapshot{Seq: seq, Game: r.game.JSON(model.AllPlayers), Metadata: r.metadata}
+	if err := r.store.SaveSnapshot(r.id, snapshot); err != nil {
+		return err
+	}
+	r.seq = seq
+	r.snapshotSeq = seq
+	r.recorded = len(r.game.Actions())
+	return nil
+}
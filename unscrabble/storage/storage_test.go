@@ -0,0 +1,178 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/storage"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGame returns a game between two bots, with enough tiles in the bag
+// for the draws to be random
+func newTestGame(t *testing.T) (*model.Game, *lexicon.TrieNode) {
+	config := testutil.Configuration(t, 3, map[string]int{"a": 6, "c": 3, "s": 3, "t": 6, "*": 1})
+	config.ChallengeRule = model.SingleChallenge
+	lex := testutil.Lexicon("act", "acts", "as", "at", "cat", "cats", "sat", "scat", "ta", "tas", "tat", "tats")
+	game, err := model.NewGame(config, lex, newPlayers(lex)...)
+	require.NoError(t, err)
+	return game, lex
+}
+
+func newPlayers(lex *lexicon.TrieNode) []*model.Player {
+	moveGenerator := triemovegen.NewTrieMoveGenertator(lex)
+	return []*model.Player{
+		model.NewPlayer("alice", strategy.NewHighScoreStrategy(&moveGenerator)),
+		model.NewPlayer("bob", strategy.NewHighScoreStrategy(&moveGenerator)),
+	}
+}
+
+func newStores(t *testing.T) map[string]storage.Store {
+	fileStore, err := storage.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return map[string]storage.Store{"memory": storage.NewMemoryStore(), "file": fileStore}
+}
+
+func TestStoreKeepsSnapshotsAndEvents(t *testing.T) {
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, storage.ErrNotFound, store.Append("g1", storage.Event{Seq: 1}))
+			_, _, err := store.Load("g1")
+			assert.Equal(t, storage.ErrNotFound, err)
+
+			snapshot := storage.Snapshot{Seq: 0, Metadata: json.RawMessage(`{"seats":2}`)}
+			require.NoError(t, store.SaveSnapshot("g1", snapshot))
+			require.NoError(t, store.SaveSnapshot("g2", snapshot))
+			pass := model.ActionJSON{Type: model.PassAction}
+			require.NoError(t, store.Append("g1", storage.Event{Seq: 1, Action: pass}, storage.Event{Seq: 2, Action: pass}))
+			require.NoError(t, store.Append("g1", storage.Event{Seq: 3, Action: pass}))
+			assert.EqualError(t, store.Append("g1", storage.Event{Seq: 5, Action: pass}), "event 5 does not follow event 3")
+
+			loaded, events, err := store.Load("g1")
+			require.NoError(t, err)
+			assert.Equal(t, snapshot.Metadata, loaded.Metadata)
+			assert.Len(t, events, 3)
+
+			// the events in a snapshot are dropped from the log
+			require.NoError(t, store.SaveSnapshot("g1", storage.Snapshot{Seq: 2}))
+			loaded, events, err = store.Load("g1")
+			require.NoError(t, err)
+			assert.Equal(t, 2, loaded.Seq)
+			assert.Equal(t, []storage.Event{{Seq: 3, Action: pass}}, events)
+			require.NoError(t, store.Append("g1", storage.Event{Seq: 4, Action: pass}))
+
+			ids, err := store.IDs()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"g1", "g2"}, ids)
+			require.NoError(t, store.Delete("g2"))
+			assert.Equal(t, storage.ErrNotFound, store.Delete("g2"))
+			ids, err = store.IDs()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"g1"}, ids)
+		})
+	}
+}
+
+func TestRecorderReconstructsGameExactly(t *testing.T) {
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			game, lex := newTestGame(t)
+			recorder, err := storage.NewRecorder(store, "game", game, json.RawMessage(`"meta"`))
+			require.NoError(t, err)
+
+			for turn := 0; !game.IsOver(); turn++ {
+				if turn == 2 {
+					// bob is given alice's tiles, as when racks are set by an import
+					rack := game.Players()[0].Rack()
+					require.NoError(t, game.SetRack(1, rack.Letters()...))
+				}
+				require.NoError(t, game.PlayTurn())
+				require.NoError(t, recorder.Record())
+				if turn == 5 {
+					require.NoError(t, recorder.Snapshot())
+				}
+
+				snapshot, events, err := store.Load("game")
+				require.NoError(t, err)
+				restored, err := storage.Restore(store, "game", snapshot, events, lex, newPlayers(lex)...)
+				require.NoError(t, err)
+				require.Equal(t, game.JSON(model.AllPlayers), restored.Game().JSON(model.AllPlayers), "turn %v", turn)
+				assert.Equal(t, json.RawMessage(`"meta"`), restored.Metadata())
+			}
+		})
+	}
+}
+
+func TestRestoredGameGoesOnBeingRecorded(t *testing.T) {
+	store := storage.NewMemoryStore()
+	game, lex := newTestGame(t)
+	recorder, err := storage.NewRecorder(store, "game", game, nil)
+	require.NoError(t, err)
+	require.NoError(t, game.PlayTurn())
+	require.NoError(t, recorder.Record())
+
+	snapshot, events, err := store.Load("game")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	restored, err := storage.Restore(store, "game", snapshot, events, lex, newPlayers(lex)...)
+	require.NoError(t, err)
+	_, err = restored.Game().Play()
+	require.NoError(t, err)
+	require.NoError(t, restored.Record())
+
+	snapshot, events, err = store.Load("game")
+	require.NoError(t, err)
+	replayed, err := storage.Replay(snapshot, events, lex, newPlayers(lex)...)
+	require.NoError(t, err)
+	assert.True(t, replayed.IsOver())
+	assert.Equal(t, restored.Game().JSON(model.AllPlayers), replayed.JSON(model.AllPlayers))
+}
+
+func TestFileStoreDiscardsPartlyWrittenEvent(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewFileStore(dir)
+	require.NoError(t, err)
+	pass := model.ActionJSON{Type: model.PassAction}
+	require.NoError(t, store.SaveSnapshot("game", storage.Snapshot{}))
+	require.NoError(t, store.Append("game", storage.Event{Seq: 1, Action: pass}))
+
+	// a crash while appending the second event
+	path := filepath.Join(dir, "game", "events.jsonl")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"act`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// the store is opened again after the crash
+	store, err = storage.NewFileStore(dir)
+	require.NoError(t, err)
+	_, events, err := store.Load("game")
+	require.NoError(t, err)
+	assert.Equal(t, []storage.Event{{Seq: 1, Action: pass}}, events)
+	require.NoError(t, store.Append("game", storage.Event{Seq: 2, Action: pass}))
+	_, events, err = store.Load("game")
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	log, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"seq\":1,\"action\":{\"type\":\"pass\",\"player\":0}}\n{\"seq\":2,\"action\":{\"type\":\"pass\",\"player\":0}}\n", string(log))
+}
+
+func TestFileStoreRejectsInvalidIDs(t *testing.T) {
+	store, err := storage.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	assert.EqualError(t, store.SaveSnapshot("../game", storage.Snapshot{}), `invalid game id "../game"`)
+	_, _, err = store.Load("")
+	assert.Error(t, err)
+}
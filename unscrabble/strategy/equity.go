@@ -0,0 +1,122 @@
+package strategy
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// Leaves are the values of the tiles left on a rack after a move, by the rack
+// of the leave as formatted by Alphabet.FormatRack. A leave that is not in the
+// table is worth nothing.
+type Leaves map[string]float64
+
+// ReadLeaves reads a table of leaves from CSV with a leave and its value on
+// each line, e.g. "ERS?,25.5". The first line may be the header
+// "leave,value".
+func ReadLeaves(r io.Reader, alphabet *model.Alphabet) (Leaves, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	leaves := make(Leaves)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return leaves, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if line == 1 && record[0] == "leave" && record[1] == "value" {
+			continue
+		}
+		// the errors do not quote the line, as the file may not be a table of
+		// leaves at all. Any leave that fits on a rack is read, including the
+		// empty leave of a move which plays every tile.
+		rack, err := alphabet.ParseRack(record[0], model.MaxRackSize, nil)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: the leave is not a rack", line)
+		}
+		value, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: the value is not a number", line)
+		}
+		leaves[alphabet.FormatRack(rack)] = value
+	}
+}
+
+// LoadLeaves reads a table of leaves from a CSV file. See ReadLeaves.
+func LoadLeaves(path string, alphabet *model.Alphabet) (Leaves, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	leaves, err := ReadLeaves(file, alphabet)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return leaves, nil
+}
+
+func NewEquityStrategy(moveGenerator MoveGenerator, alphabet *model.Alphabet, leaves Leaves) *EquityStrategy {
+	return &EquityStrategy{
+		moveGenerator: moveGenerator,
+		alphabet:      alphabet,
+		leaves:        leaves,
+	}
+}
+
+// EquityStrategy plays the move with the highest equity, its score plus the
+// value of the tiles it leaves on the rack, so that it keeps good tiles for
+// later turns rather than spending them on a few extra points
+type EquityStrategy struct {
+	moveGenerator MoveGenerator
+	// alphabet formats the leaves of moves the same way as the keys of leaves
+	alphabet *model.Alphabet
+	leaves   Leaves
+}
+
+// PickMove returns the move with the highest equity out of all the moves
+// generated by the provided board and rack. If multiple moves have the highest
+// equity, the first one provided by the generator is returned. If no moves are
+// generated a nil Move is returned.
+func (e *EquityStrategy) PickMove(board model.Board, rack model.Rack) *model.Move {
+	var best *model.Move
+	var bestEquity float64
+	moves := e.moveGenerator.GenerateMoves(board, rack)
+	for i, move := range moves {
+		leave := rack.Copy()
+		for _, tile := range board.TilesPlaced(move) {
+			leave.RemoveLetter(tile)
+		}
+		equity := float64(move.Score) + e.leaves[e.alphabet.FormatRack(&leave)]
+		if best == nil || equity > bestEquity {
+			best, bestEquity = &moves[i], equity
+		}
+	}
+	return best
+}
+
+var equityDefinition = Definition{
+	Name:        "equity",
+	Description: "plays the move with the highest score plus the value of the tiles it leaves",
+	Params: []Param{
+		{Name: "leaves", Description: "CSV file of the values of leaves", Required: true},
+	},
+	New: func(params Params, alphabet *model.Alphabet) (NewPicker, error) {
+		if alphabet == nil {
+			alphabet = model.EnglishAlphabet
+		}
+		leaves, err := LoadLeaves(params["leaves"], alphabet)
+		if err != nil {
+			return nil, err
+		}
+		return func(moveGenerator MoveGenerator, _ *rand.Rand) model.MovePicker {
+			return NewEquityStrategy(moveGenerator, alphabet, leaves)
+		}, nil
+	},
+}
@@ -1,6 +1,8 @@
 package strategy
 
 import (
+	"math/rand"
+
 	"example.com/unscrabble/unscrabble/model"
 )
 
@@ -32,3 +34,13 @@ func (h *HighScoreStrategy) PickMove(board model.Board, rack model.Rack) *model.
 	}
 	return &highScoreMove
 }
+
+var highScoreDefinition = Definition{
+	Name:        "highscore",
+	Description: "plays the highest scoring move",
+	New: func(Params, *model.Alphabet) (NewPicker, error) {
+		return func(moveGenerator MoveGenerator, _ *rand.Rand) model.MovePicker {
+			return NewHighScoreStrategy(moveGenerator)
+		}, nil
+	},
+}
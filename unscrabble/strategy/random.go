@@ -0,0 +1,42 @@
+package strategy
+
+import (
+	"math/rand"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+func NewRandomStrategy(moveGenerator MoveGenerator, random *rand.Rand) *RandomStrategy {
+	return &RandomStrategy{
+		moveGenerator: moveGenerator,
+		random:        random,
+	}
+}
+
+// RandomStrategy plays a move chosen uniformly at random. It is a baseline for
+// measuring other strategies against.
+type RandomStrategy struct {
+	moveGenerator MoveGenerator
+	random        *rand.Rand
+}
+
+// PickMove returns a random move out of all the moves generated by the provided board and rack.
+// If no moves are generated a nil Move is returned.
+func (r *RandomStrategy) PickMove(board model.Board, rack model.Rack) *model.Move {
+	moves := r.moveGenerator.GenerateMoves(board, rack)
+	if len(moves) == 0 {
+		return nil
+	}
+	move := moves[r.random.Intn(len(moves))]
+	return &move
+}
+
+var randomDefinition = Definition{
+	Name:        "random",
+	Description: "plays a move chosen at random",
+	New: func(Params, *model.Alphabet) (NewPicker, error) {
+		return func(moveGenerator MoveGenerator, random *rand.Rand) model.MovePicker {
+			return NewRandomStrategy(moveGenerator, random)
+		}, nil
+	},
+}
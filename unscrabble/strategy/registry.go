@@ -0,0 +1,193 @@
+package strategy
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// NewPicker returns a move picker of a strategy for a game. The picker picks
+// its moves with moveGenerator, which scores and ranks them, and must only use
+// random for its random choices.
+type NewPicker func(moveGenerator MoveGenerator, random *rand.Rand) model.MovePicker
+
+// Param is a parameter of a strategy
+type Param struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Default is the value of the parameter if the spec does not give it,
+	// unless the parameter is required
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// Params are the values of the parameters of a strategy, by name
+type Params map[string]string
+
+// Int returns the value of an integer parameter
+func (p Params) Int(name string) (int, error) {
+	value, err := strconv.Atoi(p[name])
+	if err != nil {
+		return 0, fmt.Errorf("%v must be a whole number, not %q", name, p[name])
+	}
+	return value, nil
+}
+
+// Definition is a strategy in a registry
+type Definition struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Params      []Param `json:"params,omitempty"`
+	// New returns the maker of the strategy's pickers with params, which has
+	// a value for each of the definition's parameters. Anything the pickers
+	// share, such as a file they need, is loaded by New, so a strategy with
+	// the same params can be used in many games. Letters in params are
+	// written with alphabet, or the English alphabet if it is nil.
+	New func(params Params, alphabet *model.Alphabet) (NewPicker, error) `json:"-"`
+}
+
+// Registry is the strategies that can be made from specs. A spec is the name
+// of a strategy, followed by a colon and a comma separated list of parameters
+// with their values if it has any, e.g. "random" or
+// "equity:leaves=leaves.csv".
+type Registry struct {
+	definitions map[string]Definition
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{definitions: make(map[string]Definition)}
+}
+
+// DefaultRegistry has the strategies of this package
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for _, definition := range []Definition{highScoreDefinition, randomDefinition, equityDefinition} {
+		if err := registry.Register(definition); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register adds a strategy to the registry
+func (r *Registry) Register(definition Definition) error {
+	if definition.Name == "" || strings.ContainsAny(definition.Name, ":,= ") {
+		return fmt.Errorf("%q is not a valid strategy name", definition.Name)
+	}
+	if _, ok := r.definitions[definition.Name]; ok {
+		return fmt.Errorf("strategy %q is registered twice", definition.Name)
+	}
+	if definition.New == nil {
+		return fmt.Errorf("strategy %q cannot be made", definition.Name)
+	}
+	r.definitions[definition.Name] = definition
+	return nil
+}
+
+// Definitions returns the strategies in the registry in order of name
+func (r *Registry) Definitions() []Definition {
+	definitions := make([]Definition, 0, len(r.definitions))
+	for _, definition := range r.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// Names returns the names of the strategies in the registry in order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.definitions))
+	for _, definition := range r.Definitions() {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// Parse returns the strategy of a spec and the values of its parameters, with
+// the defaults of the parameters the spec does not give
+func (r *Registry) Parse(spec string) (Definition, Params, error) {
+	name, list := spec, ""
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		name, list = spec[:i], spec[i+1:]
+	}
+	definition, ok := r.definitions[name]
+	if !ok {
+		return Definition{}, nil, fmt.Errorf(
+			"unknown strategy %q, the strategies are: %v", name, strings.Join(r.Names(), ", "),
+		)
+	}
+
+	given := make(Params)
+	if list != "" {
+		for _, param := range strings.Split(list, ",") {
+			i := strings.IndexByte(param, '=')
+			if i < 0 {
+				return Definition{}, nil, fmt.Errorf("%v: parameter %q does not have a value", name, param)
+			}
+			if _, ok := given[param[:i]]; ok {
+				return Definition{}, nil, fmt.Errorf("%v: parameter %v is given twice", name, param[:i])
+			}
+			given[param[:i]] = param[i+1:]
+		}
+	}
+	params := make(Params, len(definition.Params))
+	for _, param := range definition.Params {
+		value, ok := given[param.Name]
+		if !ok && param.Required {
+			return Definition{}, nil, fmt.Errorf("%v: parameter %v is required", name, param.Name)
+		} else if !ok {
+			value = param.Default
+		}
+		params[param.Name] = value
+		delete(given, param.Name)
+	}
+	if len(given) > 0 {
+		unknown := make([]string, 0, len(given))
+		for param := range given {
+			unknown = append(unknown, param)
+		}
+		sort.Strings(unknown)
+		return Definition{}, nil, fmt.Errorf("%v: unknown parameters %v", name, strings.Join(unknown, ", "))
+	}
+	return definition, params, nil
+}
+
+// New returns the maker of the pickers of the strategy of a spec
+func (r *Registry) New(spec string, alphabet *model.Alphabet) (NewPicker, error) {
+	definition, params, err := r.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	newPicker, err := definition.New(params, alphabet)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", definition.Name, err)
+	}
+	return newPicker, nil
+}
+
+// Usage returns a description of the strategies in the registry and their
+// parameters, a line for each
+func (r *Registry) Usage() string {
+	var sb strings.Builder
+	for _, definition := range r.Definitions() {
+		fmt.Fprintf(&sb, "%v: %v\n", definition.Name, definition.Description)
+		for _, param := range definition.Params {
+			switch {
+			case param.Required:
+				fmt.Fprintf(&sb, "  %v: %v (required)\n", param.Name, param.Description)
+			case param.Default != "":
+				fmt.Fprintf(&sb, "  %v: %v (default %v)\n", param.Name, param.Description, param.Default)
+			default:
+				fmt.Fprintf(&sb, "  %v: %v\n", param.Name, param.Description)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package strategy_test
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/strategy/mock_strategy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(t *testing.T) *strategy.Registry {
+	registry := strategy.NewRegistry()
+	require.NoError(t, registry.Register(strategy.Definition{
+		Name:        "sim",
+		Description: "simulates moves",
+		Params: []strategy.Param{
+			{Name: "plies", Description: "plies to look ahead", Default: "2"},
+			{Name: "iters", Description: "iterations of each move", Required: true},
+		},
+		New: func(params strategy.Params, _ *model.Alphabet) (strategy.NewPicker, error) {
+			if _, err := params.Int("iters"); err != nil {
+				return nil, err
+			}
+			return func(moveGenerator strategy.MoveGenerator, _ *rand.Rand) model.MovePicker {
+				return strategy.NewHighScoreStrategy(moveGenerator)
+			}, nil
+		},
+	}))
+	return registry
+}
+
+func TestRegistryParsesSpecs(t *testing.T) {
+	registry := newTestRegistry(t)
+	definition, params, err := registry.Parse("sim:iters=500")
+	require.NoError(t, err)
+	assert.Equal(t, "sim", definition.Name)
+	assert.Equal(t, strategy.Params{"plies": "2", "iters": "500"}, params)
+
+	_, params, err = registry.Parse("sim:plies=3,iters=10")
+	require.NoError(t, err)
+	assert.Equal(t, strategy.Params{"plies": "3", "iters": "10"}, params)
+
+	for spec, message := range map[string]string{
+		"clever":                 `unknown strategy "clever", the strategies are: sim`,
+		"sim":                    "sim: parameter iters is required",
+		"sim:iters":              `sim: parameter "iters" does not have a value`,
+		"sim:iters=1,iters=2":    "sim: parameter iters is given twice",
+		"sim:iters=1,depth=2,x=": "sim: unknown parameters depth, x",
+	} {
+		_, _, err := registry.Parse(spec)
+		assert.EqualError(t, err, message, spec)
+	}
+
+	_, err = registry.New("sim:iters=many", nil)
+	assert.EqualError(t, err, `sim: iters must be a whole number, not "many"`)
+	newPicker, err := registry.New("sim:iters=1", nil)
+	require.NoError(t, err)
+	assert.NotNil(t, newPicker(nil, nil))
+
+	assert.EqualError(t, registry.Register(strategy.Definition{Name: "sim"}), `strategy "sim" is registered twice`)
+	assert.Equal(t, "sim: simulates moves\n  plies: plies to look ahead (default 2)\n  iters: iterations of each move (required)\n", registry.Usage())
+}
+
+func TestDefaultRegistryHasTheStrategiesOfThePackage(t *testing.T) {
+	assert.Equal(t, []string{"equity", "highscore", "random"}, strategy.DefaultRegistry.Names())
+	newPicker, err := strategy.DefaultRegistry.New("random", nil)
+	require.NoError(t, err)
+	assert.IsType(t, &strategy.RandomStrategy{}, newPicker(nil, rand.New(rand.NewSource(1))))
+
+	_, err = strategy.DefaultRegistry.New("equity", nil)
+	assert.EqualError(t, err, "equity: parameter leaves is required")
+}
+
+func TestReadLeaves(t *testing.T) {
+	leaves, err := strategy.ReadLeaves(strings.NewReader("leave,value\nS?,20.5\nQ,-7\n,0\n"), model.EnglishAlphabet)
+	require.NoError(t, err)
+	assert.Equal(t, strategy.Leaves{"S?": 20.5, "Q": -7, "": 0}, leaves)
+
+	// multi-character tiles are keyed in the notation of the alphabet
+	alphabet, err := model.NewAlphabet([]string{"a", "ch", "ñ"})
+	require.NoError(t, err)
+	leaves, err = strategy.ReadLeaves(strings.NewReader("Ñ[CH]A,3\n"), alphabet)
+	require.NoError(t, err)
+	assert.Equal(t, strategy.Leaves{"A[CH]Ñ": 3}, leaves)
+
+	_, err = strategy.ReadLeaves(strings.NewReader("S,1\n12,x\n"), model.EnglishAlphabet)
+	assert.EqualError(t, err, "line 2: the leave is not a rack")
+	_, err = strategy.ReadLeaves(strings.NewReader("S,one\n"), model.EnglishAlphabet)
+	assert.EqualError(t, err, "line 1: the value is not a number")
+}
+
+func TestEquityStrategyPicksMoveWithBestLeave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMoveGenerator := mock_strategy.NewMockMoveGenerator(ctrl)
+
+	multipliers := [][]int{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}
+	board := model.NewBoard(nil, multipliers, multipliers)
+	generatedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 0},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "as", BlankTiles: []bool{false, false}},
+			Score:         6,
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 0},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, true}},
+			Score:         4,
+		},
+	}
+	mockMoveGenerator.
+		EXPECT().
+		GenerateMoves(gomock.Any(), gomock.Any()).
+		Return(generatedMoves)
+
+	path := filepath.Join(t.TempDir(), "leaves.csv")
+	require.NoError(t, os.WriteFile(path, []byte("S,25\n?,5\n"), 0600))
+	newPicker, err := strategy.DefaultRegistry.New("equity:leaves="+path, nil)
+	require.NoError(t, err)
+	rack, err := model.ParseRack("AS?", 3, nil)
+	require.NoError(t, err)
+
+	// playing the blank as a B keeps the S, which is worth more to this table
+	// than the two points the other move scores over it
+	expectedMove := generatedMoves[1]
+	assert.Equal(t, &expectedMove, newPicker(mockMoveGenerator, nil).PickMove(board, *rack))
+}
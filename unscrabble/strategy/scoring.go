@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"sort"
+
+	"example.com/unscrabble/unscrabble/model"
+)
+
+// NewScoringMoveGenerator returns a MoveGenerator which scores the moves of
+// moveGenerator using the scoring rules of a game
+func NewScoringMoveGenerator(
+	moveGenerator MoveGenerator,
+	letterScores map[rune]int,
+	rackSize, bingoPremium int,
+) *ScoringMoveGenerator {
+	return &ScoringMoveGenerator{
+		moveGenerator: moveGenerator,
+		letterScores:  letterScores,
+		rackSize:      rackSize,
+		bingoPremium:  bingoPremium,
+	}
+}
+
+// ScoringMoveGenerator scores the moves of a MoveGenerator which does not score
+// them itself
+type ScoringMoveGenerator struct {
+	moveGenerator MoveGenerator
+	letterScores  map[rune]int
+	rackSize      int
+	bingoPremium  int
+}
+
+// GenerateMoves returns the moves of the wrapped generator with their scores,
+// ranked by RankMoves. Moves which cannot be scored on the board are dropped.
+func (s *ScoringMoveGenerator) GenerateMoves(board model.Board, rack model.Rack) []model.Move {
+	moves := s.moveGenerator.GenerateMoves(board, rack)
+	scored := moves[:0]
+	for _, move := range moves {
+		score, err := move.CalculateScore(board, s.letterScores, s.rackSize, s.bingoPremium)
+		if err != nil {
+			continue
+		}
+		move.Score = score
+		scored = append(scored, move)
+	}
+	RankMoves(scored)
+	return scored
+}
+
+// RankMoves sorts moves from the highest score. Moves with the same score are
+// sorted by position, with horizontal moves first, and then by word, so the
+// order does not depend on the order the moves were generated in.
+func RankMoves(moves []model.Move) {
+	sort.SliceStable(moves, func(i, j int) bool {
+		a, b := moves[i], moves[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.StartPosition.Row != b.StartPosition.Row:
+			return a.StartPosition.Row < b.StartPosition.Row
+		case a.StartPosition.Column != b.StartPosition.Column:
+			return a.StartPosition.Column < b.StartPosition.Column
+		case a.Horizontal != b.Horizontal:
+			return a.Horizontal
+		case a.Word.Chars != b.Word.Chars:
+			return a.Word.Chars < b.Word.Chars
+		}
+		return blankPattern(a.Word) < blankPattern(b.Word)
+	})
+}
+
+// blankPattern returns a string of which tiles of word are blanks, for ordering
+// words which only differ in their blanks
+func blankPattern(word model.Word) string {
+	pattern := make([]byte, len(word.BlankTiles))
+	for i, blank := range word.BlankTiles {
+		pattern[i] = '0'
+		if blank {
+			pattern[i] = '1'
+		}
+	}
+	return string(pattern)
+}
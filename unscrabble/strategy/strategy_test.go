@@ -1,6 +1,7 @@
 package strategy_test
 
 import (
+	"math/rand"
 	"testing"
 
 	"example.com/unscrabble/unscrabble/model"
@@ -90,3 +91,94 @@ func TestHighScoreStrategyPickMoveReturnsFirstMoveWithHighestScore(t *testing.T)
 	expectedMove := generatedMoves[0]
 	assert.Equal(t, &expectedMove, highScoreStrategy.PickMove(model.Board{}, model.Rack{}))
 }
+
+func TestScoringMoveGeneratorScoresAndRanksMoves(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMoveGenerator := mock_strategy.NewMockMoveGenerator(ctrl)
+
+	multipliers := [][]int{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}
+	board := model.NewBoard(nil, multipliers, multipliers)
+	generatedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    false,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, false}},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 0},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "abc", BlankTiles: []bool{false, true, false}},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, false}},
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 2},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, false}},
+		},
+	}
+	mockMoveGenerator.
+		EXPECT().
+		GenerateMoves(gomock.Any(), gomock.Any()).
+		Return(generatedMoves)
+
+	letterScores := map[rune]int{'a': 1, 'b': 3, 'c': 2}
+	scoringMoveGenerator := strategy.NewScoringMoveGenerator(mockMoveGenerator, letterScores, 7, 50)
+	moves := scoringMoveGenerator.GenerateMoves(board, model.Rack{})
+
+	// the move off the edge of the board cannot be scored
+	expectedMoves := []model.Move{
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, false}},
+			Score:         4,
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 1},
+			Horizontal:    false,
+			Word:          model.Word{Chars: "ab", BlankTiles: []bool{false, false}},
+			Score:         4,
+		},
+		{
+			StartPosition: &model.Position{Row: 1, Column: 0},
+			Horizontal:    true,
+			Word:          model.Word{Chars: "abc", BlankTiles: []bool{false, true, false}},
+			Score:         3,
+		},
+	}
+	assert.Equal(t, expectedMoves, moves)
+}
+
+func TestRandomStrategyPickMove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMoveGenerator := mock_strategy.NewMockMoveGenerator(ctrl)
+
+	generatedMoves := []model.Move{
+		{StartPosition: &model.Position{Row: 0, Column: 0}, Word: model.Word{Chars: "ab"}},
+		{StartPosition: &model.Position{Row: 0, Column: 1}, Word: model.Word{Chars: "ab"}},
+		{StartPosition: &model.Position{Row: 0, Column: 2}, Word: model.Word{Chars: "ab"}},
+	}
+	mockMoveGenerator.
+		EXPECT().
+		GenerateMoves(gomock.Any(), gomock.Any()).
+		Return(generatedMoves).
+		Times(2)
+	mockMoveGenerator.
+		EXPECT().
+		GenerateMoves(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	randomStrategy := strategy.NewRandomStrategy(mockMoveGenerator, rand.New(rand.NewSource(1)))
+	expected := rand.New(rand.NewSource(1))
+	for i := 0; i < 2; i++ {
+		expectedMove := generatedMoves[expected.Intn(len(generatedMoves))]
+		assert.Equal(t, &expectedMove, randomStrategy.PickMove(model.Board{}, model.Rack{}))
+	}
+	assert.Nil(t, randomStrategy.PickMove(model.Board{}, model.Rack{}))
+}
@@ -0,0 +1,260 @@
+// Package terminal plays games between a person at a terminal and bots. The
+// board is drawn as text and moves are typed in standard coordinate notation.
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// DefaultHints is the number of moves listed by the hint command
+const DefaultHints = 5
+
+const help = `Commands:
+  8H WORD          play WORD across from 8H, or down from H8, e.g. "8H CAT" or
+                   "H8 S(CAT)". Tiles on the board can be written in
+                   parentheses or as '.', and blanks in lower case.
+  exchange TILES   exchange tiles from your rack, with '?' for a blank
+  pass             pass your turn
+  hint [n]         list the n highest scoring moves
+  challenge        challenge your opponent's last play
+  accept           accept your opponent's last play when it ends the game
+  help             show this message
+  quit             stop playing
+`
+
+// Session is a game between a person at a terminal and bots. The bots take
+// their turns with their players' strategies.
+type Session struct {
+	game          *model.Game
+	alphabet      *model.Alphabet
+	human         int
+	moveGenerator strategy.MoveGenerator
+	in            *bufio.Scanner
+	out           io.Writer
+}
+
+// NewSession returns a session in which the person plays as the player with
+// index human in game. The moves of moveGenerator are listed as hints, so they
+// should be scored and ranked.
+func NewSession(
+	game *model.Game,
+	alphabet *model.Alphabet,
+	human int,
+	moveGenerator strategy.MoveGenerator,
+	in io.Reader,
+	out io.Writer,
+) *Session {
+	return &Session{
+		game:          game,
+		alphabet:      alphabet,
+		human:         human,
+		moveGenerator: moveGenerator,
+		in:            bufio.NewScanner(in),
+		out:           out,
+	}
+}
+
+// Run plays the game until it is over, or until the person quits or their
+// input ends
+func (s *Session) Run() error {
+	fmt.Fprintln(s.out, `Type "help" for the commands.`)
+	for !s.game.IsOver() {
+		if s.game.CurrentPlayer() != s.human {
+			if err := s.playBot(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.showPosition()
+		fmt.Fprint(s.out, "> ")
+		if !s.in.Scan() {
+			fmt.Fprintln(s.out)
+			return s.in.Err()
+		}
+		quit, err := s.command(s.in.Text())
+		if quit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(s.out, "%v\n", err)
+		}
+	}
+	s.showResult()
+	return nil
+}
+
+// playBot takes the current bot's turn and shows the turns it took
+func (s *Session) playBot() error {
+	turns := len(s.game.Record())
+	if err := s.game.PlayTurn(); err != nil {
+		return err
+	}
+	s.showTurns(turns)
+	return nil
+}
+
+// command carries out a line typed by the person. It returns true if they
+// want to stop playing.
+func (s *Session) command(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	turns := len(s.game.Record())
+	var err error
+	switch strings.ToLower(fields[0]) {
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		fmt.Fprint(s.out, help)
+		return false, nil
+	case "hint":
+		return false, s.hint(fields[1:])
+	case "pass":
+		err = s.game.Pass()
+	case "exchange":
+		err = s.exchange(fields[1:])
+	case "challenge":
+		err = s.game.Challenge()
+	case "accept":
+		err = s.game.AcceptPlay()
+	default:
+		err = s.play(line)
+	}
+	if err != nil {
+		return false, err
+	}
+	s.showTurns(turns)
+	return false, nil
+}
+
+func (s *Session) play(notation string) error {
+	move, err := s.alphabet.ParseMove(s.game.Board(), notation)
+	if err != nil {
+		return fmt.Errorf(`%v, type "help" for the commands`, err)
+	}
+	err = s.game.PlayMove(move)
+	var phonyErr *model.PhonyError
+	if errors.As(err, &phonyErr) {
+		words := make([]string, len(phonyErr.Words))
+		for i, word := range phonyErr.Words {
+			words[i] = strings.ToUpper(s.alphabet.Render(word))
+		}
+		return fmt.Errorf("not in the lexicon: %v", strings.Join(words, ", "))
+	}
+	return err
+}
+
+func (s *Session) exchange(args []string) error {
+	if len(args) != 1 {
+		return errors.New("type the tiles to exchange, e.g. \"exchange QU?\"")
+	}
+	rack, err := s.alphabet.ParseRack(args[0], utf8.RuneCountInString(args[0]), nil)
+	if err != nil {
+		return err
+	}
+	return s.game.Exchange(rack.Letters()...)
+}
+
+func (s *Session) hint(args []string) error {
+	n := DefaultHints
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 {
+			return fmt.Errorf("%q is not a number of hints", args[0])
+		}
+	}
+	board := s.game.Board()
+	moves := s.moveGenerator.GenerateMoves(board, s.game.Players()[s.human].Rack())
+	if len(moves) == 0 {
+		fmt.Fprintln(s.out, "there are no moves, you could exchange or pass")
+		return nil
+	}
+	if len(moves) > n {
+		moves = moves[:n]
+	}
+	for i, move := range moves {
+		fmt.Fprintf(s.out, "%3d. %-24v %4d\n", i+1, s.alphabet.FormatMove(board, move), move.Score)
+	}
+	return nil
+}
+
+// showPosition shows the board, the scores and the person's rack
+func (s *Session) showPosition() {
+	fmt.Fprintln(s.out)
+	fmt.Fprint(s.out, s.alphabet.FormatBoard(s.game.Board()))
+	fmt.Fprintln(s.out)
+	for _, player := range s.game.Players() {
+		fmt.Fprintf(s.out, "%v %v   ", player.Name(), player.Score())
+	}
+	fmt.Fprintf(s.out, "tiles in bag: %v\n", s.game.TilesInBag())
+	if _, ok := s.game.ChallengeablePlay(); ok {
+		fmt.Fprintln(s.out, `You can "challenge" the last play.`)
+	}
+	rack := s.game.Players()[s.human].Rack()
+	fmt.Fprintf(s.out, "Your rack: %v\n", s.alphabet.FormatRack(&rack))
+}
+
+// showTurns shows the turns in the record from index first
+func (s *Session) showTurns(first int) {
+	record := s.game.Record()
+	for i := first; i < len(record); i++ {
+		turn := record[i]
+		fmt.Fprintf(
+			s.out,
+			"%v: %v %+d (%d)\n",
+			s.game.Players()[turn.Player].Name(),
+			DescribeTurn(s.game, s.alphabet, i),
+			turn.Score,
+			turn.Total,
+		)
+	}
+}
+
+// showResult shows the final scores and the winners
+func (s *Session) showResult() {
+	fmt.Fprintln(s.out)
+	fmt.Fprint(s.out, s.alphabet.FormatBoard(s.game.Board()))
+	fmt.Fprintln(s.out, "Game over")
+	for _, player := range s.game.Players() {
+		fmt.Fprintf(s.out, "%v %v\n", player.Name(), player.Score())
+	}
+	winners := s.game.Winners()
+	if len(winners) > 1 {
+		fmt.Fprintln(s.out, "It's a draw")
+		return
+	}
+	fmt.Fprintf(s.out, "%v won\n", winners[0].Name())
+}
+
+// DescribeTurn describes a turn of the game's record, with moves written in
+// standard notation
+func DescribeTurn(game *model.Game, alphabet *model.Alphabet, i int) string {
+	turn := game.Record()[i]
+	switch turn.Type {
+	case model.PlayTurn:
+		return alphabet.FormatMove(game.BoardBefore(i), *turn.Move)
+	case model.PhonyWithdrawnTurn:
+		// the withdrawn play is the turn before
+		return "withdrew " + alphabet.FormatMove(game.BoardBefore(i-1), *turn.Move)
+	case model.ExchangeTurn:
+		exchanged := model.NewRack(len(turn.Exchanged))
+		for _, letter := range turn.Exchanged {
+			exchanged.AddLetter(letter)
+		}
+		return "exchanged " + alphabet.FormatRack(exchanged)
+	case model.EndRackBonusTurn, model.EndRackPenaltyTurn:
+		return turn.Type.String() + " " + alphabet.FormatRack(&turn.Rack)
+	}
+	return turn.Type.String()
+}
@@ -0,0 +1,83 @@
+package terminal_test
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/terminal"
+	"example.com/unscrabble/unscrabble/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSession returns a session of a game on a Scrabble board in which the
+// only tiles are two sets of CAT, so the bag is empty once the racks of three
+// tiles are dealt. The person is the first player, with the rack CAT, and the
+// bot has the rack ACT.
+func newTestSession(t *testing.T, input string) (*terminal.Session, *model.Game, *strings.Builder) {
+	config := testutil.Configuration(t, 3, map[string]int{"a": 2, "c": 2, "t": 2})
+	lex := testutil.Lexicon("act", "at", "cat")
+	letterScores, err := model.EnglishAlphabet.LetterMap(config.LetterScores)
+	require.NoError(t, err)
+	trieMoveGenerator := triemovegen.NewTrieMoveGenertator(lex)
+	moveGenerator := strategy.NewScoringMoveGenerator(&trieMoveGenerator, letterScores, config.RackSize, 0)
+
+	game, err := model.NewGame(
+		config,
+		lex,
+		model.NewPlayer("You", nil),
+		model.NewPlayer("Bot", strategy.NewHighScoreStrategy(moveGenerator)),
+	)
+	require.NoError(t, err)
+	require.NoError(t, game.SetRack(0, 'c', 'a', 't'))
+	require.NoError(t, game.SetRack(1, 'a', 'c', 't'))
+
+	var out strings.Builder
+	session := terminal.NewSession(game, model.EnglishAlphabet, 0, moveGenerator, strings.NewReader(input), &out)
+	return session, game, &out
+}
+
+func TestSessionPlaysMoveInNotation(t *testing.T) {
+	session, game, out := newTestSession(t, "8G CAT\n")
+	require.NoError(t, session.Run())
+
+	assert.True(t, game.IsOver())
+	assert.Contains(t, out.String(), "Your rack: ACT\n")
+	assert.Contains(t, out.String(), "You: 8G CAT +10 (10)\n")
+	assert.Contains(t, out.String(), "Game over\nYou 20\nBot 0\nYou won\n")
+}
+
+func TestSessionRejectsInvalidMoves(t *testing.T) {
+	session, game, out := newTestSession(t, "8G TAC\nCAT\nexchange A\nquit\n")
+	require.NoError(t, session.Run())
+
+	assert.False(t, game.IsOver())
+	assert.Empty(t, game.Record())
+	assert.Contains(t, out.String(), "not in the lexicon: TAC\n")
+	assert.Contains(t, out.String(), `type "help" for the commands`)
+}
+
+func TestSessionListsHints(t *testing.T) {
+	session, _, out := newTestSession(t, "hint 2\nhint x\n")
+	require.NoError(t, session.Run())
+
+	assert.Contains(t, out.String(), "  1. H6 ACT                     10\n  2. H6 CAT                     10\n")
+	assert.Contains(t, out.String(), `"x" is not a number of hints`)
+}
+
+func TestSessionLetsBotReply(t *testing.T) {
+	session, game, out := newTestSession(t, "pass\n")
+	require.NoError(t, session.Run())
+
+	// the bot goes out with its reply
+	record := game.Record()
+	require.Len(t, record, 3)
+	assert.Equal(t, model.PassTurn, record[0].Type)
+	assert.Equal(t, model.PlayTurn, record[1].Type)
+	assert.Contains(t, out.String(), "You: pass +0 (0)\n")
+	assert.Contains(t, out.String(), "Bot: "+terminal.DescribeTurn(game, model.EnglishAlphabet, 1)+" +10 (10)\n")
+	assert.Contains(t, out.String(), "Game over\nYou 0\nBot 20\nBot won\n")
+}
@@ -0,0 +1,58 @@
+// Package testutil contains fixtures shared by the tests of several packages.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/unscrabble/lexicon"
+	"example.com/unscrabble/unscrabble/data"
+	"example.com/unscrabble/unscrabble/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Configuration returns the rules of the "scrabble" preset with racks of
+// rackSize tiles and no bingo premium, in which the only tiles in the bag are
+// those of letterCounts. Small bags let tests know which tiles are drawn.
+func Configuration(t testing.TB, rackSize int, letterCounts map[string]int) model.Configuration {
+	config, err := data.LoadPreset("scrabble")
+	require.NoError(t, err)
+	config.RackSize = rackSize
+	config.BingoPremium = 0
+	for letter := range config.LetterCounts {
+		config.LetterCounts[letter] = letterCounts[letter]
+	}
+	require.Empty(t, config.Validate())
+	return config
+}
+
+// Lexicon returns a lexicon of words
+func Lexicon(words ...string) *lexicon.TrieNode {
+	lex := lexicon.NewTrieNode()
+	for _, word := range words {
+		lex.Insert(word)
+	}
+	return lex
+}
+
+// Request sends a request to a test server of a JSON API, with body encoded as
+// JSON if it is not nil, and decodes the response into response. It returns
+// the response's status.
+func Request(t testing.TB, server *httptest.Server, method, path string, body, response interface{}) int {
+	var encoded bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&encoded).Encode(body))
+	}
+	req, err := http.NewRequest(method, server.URL+path, &encoded)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+	return resp.StatusCode
+}
@@ -0,0 +1,157 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// z is the quantile of the normal distribution for a 95% confidence interval
+const z = 1.959964
+
+// Stats summarises the games of an entrant, either against every opponent or,
+// if Opponent is not empty, against one opponent
+type Stats struct {
+	Entrant  string `json:"entrant"`
+	Opponent string `json:"opponent,omitempty"`
+	Games    int    `json:"games"`
+	Wins     int    `json:"wins"`
+	Draws    int    `json:"draws"`
+	Losses   int    `json:"losses"`
+	// WinRate counts a draw as half a win, and WinRateLow and WinRateHigh are
+	// the bounds of its 95% confidence interval
+	WinRate     float64 `json:"win_rate"`
+	WinRateLow  float64 `json:"win_rate_low"`
+	WinRateHigh float64 `json:"win_rate_high"`
+	// MeanSpread is the mean of the entrant's score less the best score of
+	// their opponents
+	MeanSpread float64 `json:"mean_spread"`
+	MeanScore  float64 `json:"mean_score"`
+	// BingoRate is the mean number of bingos the entrant played per game
+	BingoRate float64 `json:"bingo_rate"`
+	// MeanLength is the mean number of turns in the games
+	MeanLength float64 `json:"mean_length"`
+}
+
+// totals are the sums from which Stats are calculated
+type totals struct {
+	games, wins, draws, losses    int
+	spread, score, bingos, length int
+}
+
+func (t *totals) add(game GameResult, seat int) {
+	player := game.Players[seat]
+	best := math.MinInt32
+	for other, opponent := range game.Players {
+		if other != seat && opponent.Score > best {
+			best = opponent.Score
+		}
+	}
+	t.games++
+	switch player.Outcome {
+	case Win:
+		t.wins++
+	case Draw:
+		t.draws++
+	default:
+		t.losses++
+	}
+	t.spread += player.Score - best
+	t.score += player.Score
+	t.bingos += player.Bingos
+	t.length += game.Turns
+}
+
+func (t totals) stats(entrant, opponent string) Stats {
+	stats := Stats{Entrant: entrant, Opponent: opponent, Games: t.games, Wins: t.wins, Draws: t.draws, Losses: t.losses}
+	if t.games == 0 {
+		return stats
+	}
+	n := float64(t.games)
+	stats.WinRate = (float64(t.wins) + float64(t.draws)/2) / n
+	stats.WinRateLow, stats.WinRateHigh = wilsonInterval(stats.WinRate, n)
+	stats.MeanSpread = float64(t.spread) / n
+	stats.MeanScore = float64(t.score) / n
+	stats.BingoRate = float64(t.bingos) / n
+	stats.MeanLength = float64(t.length) / n
+	return stats
+}
+
+// wilsonInterval returns the Wilson score interval of a proportion p of n
+// trials, which unlike the normal approximation stays within [0, 1] and is
+// useful for proportions near 0 or 1
+func wilsonInterval(p, n float64) (float64, float64) {
+	denominator := 1 + z*z/n
+	centre := (p + z*z/(2*n)) / denominator
+	halfWidth := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+	return math.Max(0, centre-halfWidth), math.Min(1, centre+halfWidth)
+}
+
+// Summarise returns the stats of each entrant in games, and of each entrant
+// against each of their opponents. Entrants and opponents are in the order in
+// which they first appear in the games.
+func Summarise(games []GameResult) (entrants, matchups []Stats) {
+	var names []string
+	overall := make(map[string]*totals)
+	byOpponent := make(map[string]map[string]*totals)
+	opponentNames := make(map[string][]string)
+	for _, game := range games {
+		for seat, player := range game.Players {
+			if overall[player.Entrant] == nil {
+				names = append(names, player.Entrant)
+				overall[player.Entrant] = &totals{}
+				byOpponent[player.Entrant] = make(map[string]*totals)
+			}
+			overall[player.Entrant].add(game, seat)
+			for other, opponent := range game.Players {
+				if other == seat {
+					continue
+				}
+				matchup := byOpponent[player.Entrant][opponent.Entrant]
+				if matchup == nil {
+					matchup = &totals{}
+					byOpponent[player.Entrant][opponent.Entrant] = matchup
+					opponentNames[player.Entrant] = append(opponentNames[player.Entrant], opponent.Entrant)
+				}
+				matchup.add(game, seat)
+			}
+		}
+	}
+
+	for _, name := range names {
+		entrants = append(entrants, overall[name].stats(name, ""))
+		for _, opponent := range opponentNames[name] {
+			matchups = append(matchups, byOpponent[name][opponent].stats(name, opponent))
+		}
+	}
+	return entrants, matchups
+}
+
+// WriteCSV writes stats as CSV with a header row
+func WriteCSV(w io.Writer, stats []Stats) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"entrant", "opponent", "games", "wins", "draws", "losses",
+		"win_rate", "win_rate_low", "win_rate_high",
+		"mean_spread", "mean_score", "bingo_rate", "mean_length",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 4, 64)
+	}
+	for _, s := range stats {
+		record := []string{
+			s.Entrant, s.Opponent, strconv.Itoa(s.Games), strconv.Itoa(s.Wins), strconv.Itoa(s.Draws), strconv.Itoa(s.Losses),
+			format(s.WinRate), format(s.WinRateLow), format(s.WinRateHigh),
+			format(s.MeanSpread), format(s.MeanScore), format(s.BingoRate), format(s.MeanLength),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,234 @@
+// Package tournament plays strategies against each other to compare them.
+// Every pair of entrants plays a match of games, and the games are played in
+// parallel with seeded bags, so a tournament is reproduced by its seed.
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"example.com/unscrabble/unscrabble/model"
+	"example.com/unscrabble/unscrabble/strategy"
+)
+
+// Entrant is a strategy taking part in a tournament
+type Entrant struct {
+	Name      string
+	NewPicker strategy.NewPicker
+}
+
+// Tournament is a round robin between entrants
+type Tournament struct {
+	Config  model.Configuration
+	Lexicon model.Lexicon
+	// NewMoveGenerator returns the move generator of a game. Each game has its
+	// own, as games are played concurrently.
+	NewMoveGenerator func() strategy.MoveGenerator
+	Entrants         []Entrant
+	// Games is the number of games in each match. The entrants of a match
+	// take turns to go first, and each pair of games is played with the same
+	// seed, so both entrants are dealt the same racks when going first.
+	Games int
+	Seed  int64
+	// Workers is the number of games played at once, or the number of CPUs if
+	// it is 0
+	Workers int
+	// TurnTime is the time each strategy is given to pick its move, or 0 to
+	// let them take as long as they take. Strategies that search for as long
+	// as they are given make games depend on the speed of the machine, and
+	// are then not reproduced exactly by the seed.
+	TurnTime time.Duration
+	// Progress is called, if it is not nil, after each game with the number of
+	// games played and the number of games in the tournament
+	Progress func(played, total int)
+}
+
+// GameResult is the result of a game in a tournament. The players are in the
+// order in which they took their turns.
+type GameResult struct {
+	Seed    int64          `json:"seed"`
+	Players []PlayerResult `json:"players"`
+	// Turns is the number of plays, exchanges and passes in the game
+	Turns int `json:"turns"`
+}
+
+// Outcome is how a game ended for a player
+type Outcome string
+
+// The outcomes of a game
+const (
+	Win  Outcome = "win"
+	Draw Outcome = "draw"
+	Loss Outcome = "loss"
+)
+
+// PlayerResult is the result of a game for one of its players
+type PlayerResult struct {
+	Entrant string  `json:"entrant"`
+	Score   int     `json:"score"`
+	Bingos  int     `json:"bingos"`
+	Outcome Outcome `json:"outcome"`
+}
+
+// Report is the result of a tournament. Entrants summarises each entrant's
+// games, and Matchups summarises the games between each entrant and each of
+// their opponents.
+type Report struct {
+	Seed     int64        `json:"seed"`
+	Entrants []Stats      `json:"entrants"`
+	Matchups []Stats      `json:"matchups"`
+	Games    []GameResult `json:"games"`
+}
+
+// fixture is a game to be played in a tournament. Entrants are the indices of
+// the players' entrants.
+type fixture struct {
+	seed     int64
+	entrants []int
+}
+
+// Run plays the tournament and returns its report. An error is returned if
+// any game could not be played.
+func (t Tournament) Run() (*Report, error) {
+	if len(t.Entrants) < 2 {
+		return nil, errors.New("a tournament needs at least two entrants")
+	}
+	if t.Games < 1 {
+		return nil, errors.New("a tournament needs at least one game per match")
+	}
+	names := make(map[string]bool, len(t.Entrants))
+	for _, entrant := range t.Entrants {
+		if names[entrant.Name] {
+			return nil, fmt.Errorf("entrant %q is in the tournament twice", entrant.Name)
+		}
+		names[entrant.Name] = true
+	}
+
+	fixtures := t.fixtures()
+	results := make([]GameResult, len(fixtures))
+	errs := make([]error, len(fixtures))
+	workers := t.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan int)
+	played := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = t.play(fixtures[i])
+				played <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range fixtures {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(played)
+	}()
+	count := 0
+	for range played {
+		count++
+		if t.Progress != nil {
+			t.Progress(count, len(fixtures))
+		}
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("game %v: %w", i+1, err)
+		}
+	}
+
+	entrants, matchups := Summarise(results)
+	return &Report{Seed: t.Seed, Entrants: entrants, Matchups: matchups, Games: results}, nil
+}
+
+// fixtures returns the games of the tournament. The seeds are drawn from the
+// tournament's seed in order, so the games do not depend on the order in which
+// they are played.
+func (t Tournament) fixtures() []fixture {
+	random := rand.New(rand.NewSource(t.Seed))
+	var fixtures []fixture
+	for i := range t.Entrants {
+		for j := i + 1; j < len(t.Entrants); j++ {
+			var seed int64
+			for game := 0; game < t.Games; game++ {
+				if game%2 == 0 {
+					seed = random.Int63()
+					fixtures = append(fixtures, fixture{seed: seed, entrants: []int{i, j}})
+				} else {
+					fixtures = append(fixtures, fixture{seed: seed, entrants: []int{j, i}})
+				}
+			}
+		}
+	}
+	return fixtures
+}
+
+// play plays a game of the tournament
+func (t Tournament) play(fixture fixture) (GameResult, error) {
+	moveGenerator := t.NewMoveGenerator()
+	players := make([]*model.Player, len(fixture.entrants))
+	for seat, entrant := range fixture.entrants {
+		random := rand.New(rand.NewSource(fixture.seed + int64(seat) + 1))
+		players[seat] = model.NewPlayer(t.Entrants[entrant].Name, t.Entrants[entrant].NewPicker(moveGenerator, random))
+	}
+	game, err := model.NewSeededGame(t.Config, t.Lexicon, fixture.seed, players...)
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	result := GameResult{Seed: fixture.seed, Players: make([]PlayerResult, len(players))}
+	// the player of the last play if it was a bingo, or -1. Only the last play
+	// can be challenged, and a bingo withdrawn after a challenge does not count.
+	bingoPlayer := -1
+	game.AddObserver(model.ObserverFunc(func(game *model.Game, event model.GameEvent) {
+		switch event.Type {
+		case model.MoveChosen:
+			bingoPlayer = -1
+			// the board does not have the move on it yet
+			if len(game.Board().TilesPlaced(*event.Move)) == t.Config.RackSize {
+				result.Players[event.Player].Bingos++
+				bingoPlayer = event.Player
+			}
+		case model.PlayChallenged:
+			if event.Successful && bingoPlayer >= 0 {
+				result.Players[bingoPlayer].Bingos--
+			}
+			bingoPlayer = -1
+		case model.MoveScored, model.TilesExchanged, model.TurnPassed:
+			result.Turns++
+		}
+	}))
+	winners, err := game.PlayContext(context.Background(), t.TurnTime)
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	for seat, player := range players {
+		outcome := Loss
+		for _, winner := range winners {
+			if winner == player {
+				outcome = Win
+				if len(winners) > 1 {
+					outcome = Draw
+				}
+			}
+		}
+		result.Players[seat].Entrant = player.Name()
+		result.Players[seat].Score = player.Score()
+		result.Players[seat].Outcome = outcome
+	}
+	return result, nil
+}
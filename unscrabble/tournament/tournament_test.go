@@ -0,0 +1,172 @@
+package tournament_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"example.com/unscrabble/unscrabble/model"
+	triemovegen "example.com/unscrabble/unscrabble/movegen/trie"
+	"example.com/unscrabble/unscrabble/strategy"
+	"example.com/unscrabble/unscrabble/testutil"
+	"example.com/unscrabble/unscrabble/tournament"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPicker(t *testing.T, spec string) strategy.NewPicker {
+	newPicker, err := strategy.DefaultRegistry.New(spec, nil)
+	require.NoError(t, err)
+	return newPicker
+}
+
+func newTestTournament(t *testing.T) tournament.Tournament {
+	config := testutil.Configuration(t, 3, map[string]int{"a": 6, "c": 3, "s": 3, "t": 6, "*": 1})
+	lex := testutil.Lexicon("act", "acts", "as", "at", "cat", "cats", "sat", "scat", "ta", "tas", "tat", "tats")
+	letterScores, err := model.EnglishAlphabet.LetterMap(config.LetterScores)
+	require.NoError(t, err)
+	return tournament.Tournament{
+		Config:  config,
+		Lexicon: lex,
+		NewMoveGenerator: func() strategy.MoveGenerator {
+			trieMoveGenerator := triemovegen.NewTrieMoveGenertator(lex)
+			return strategy.NewScoringMoveGenerator(&trieMoveGenerator, letterScores, config.RackSize, config.BingoPremium)
+		},
+		Entrants: []tournament.Entrant{
+			{Name: "highscore", NewPicker: newPicker(t, "highscore")},
+			{Name: "random", NewPicker: newPicker(t, "random")},
+			{Name: "random2", NewPicker: newPicker(t, "random")},
+		},
+		Games:   4,
+		Seed:    1,
+		Workers: 3,
+	}
+}
+
+func TestTournamentIsReproducedBySeed(t *testing.T) {
+	tour := newTestTournament(t)
+	played := 0
+	tour.Progress = func(n, total int) {
+		played = n
+		assert.Equal(t, 12, total)
+	}
+	report, err := tour.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 12, played)
+	require.Len(t, report.Games, 12)
+
+	// each pair of games has the same seed, with the other entrant going first
+	first, second := report.Games[0], report.Games[1]
+	assert.Equal(t, first.Seed, second.Seed)
+	assert.Equal(t, "highscore", first.Players[0].Entrant)
+	assert.Equal(t, "random", second.Players[0].Entrant)
+	assert.NotEqual(t, first.Seed, report.Games[2].Seed)
+	for _, game := range report.Games {
+		assert.Greater(t, game.Turns, 0)
+	}
+
+	again, err := tour.Run()
+	require.NoError(t, err)
+	assert.Equal(t, report, again)
+
+	require.Len(t, report.Entrants, 3)
+	assert.Equal(t, "highscore", report.Entrants[0].Entrant)
+	assert.Equal(t, 8, report.Entrants[0].Games)
+	require.Len(t, report.Matchups, 6)
+	assert.Empty(t, report.Entrants[0].Opponent)
+	assert.Equal(t, "random", report.Matchups[0].Opponent)
+	assert.Equal(t, 4, report.Matchups[0].Games)
+}
+
+func TestTournamentRejectsBadEntrants(t *testing.T) {
+	tour := newTestTournament(t)
+	tour.Entrants[2].Name = "random"
+	_, err := tour.Run()
+	assert.EqualError(t, err, `entrant "random" is in the tournament twice`)
+
+	tour.Entrants = tour.Entrants[:1]
+	_, err = tour.Run()
+	assert.EqualError(t, err, "a tournament needs at least two entrants")
+}
+
+// phonyBingo plays its whole rack on the start square of an empty board,
+// whether or not it is a word, passes otherwise and challenges every play
+type phonyBingo struct{}
+
+func (phonyBingo) PickMove(board model.Board, rack model.Rack) *model.Move {
+	if !board.IsEmpty() {
+		return nil
+	}
+	return &model.Move{
+		StartPosition: &model.Position{Row: 7, Column: 7},
+		Horizontal:    true,
+		Word:          model.Word{Chars: string(rack.Letters()), BlankTiles: make([]bool, rack.TileCount())},
+	}
+}
+
+func (phonyBingo) ShouldChallenge(board model.Board, move model.Move) bool {
+	return true
+}
+
+func TestTournamentDoesNotCountPhonyBingos(t *testing.T) {
+	tour := newTestTournament(t)
+	tour.Config.ChallengeRule = model.SingleChallenge
+	// no rack of these tiles is a word
+	tour.Config.LetterCounts["c"] = 0
+	tour.Config.LetterCounts["s"] = 0
+	tour.Config.LetterCounts["*"] = 0
+	newPhonyBingo := func(strategy.MoveGenerator, *rand.Rand) model.MovePicker { return phonyBingo{} }
+	tour.Entrants = []tournament.Entrant{
+		{Name: "a", NewPicker: newPhonyBingo},
+		{Name: "b", NewPicker: newPhonyBingo},
+	}
+	tour.Games = 1
+	report, err := tour.Run()
+	require.NoError(t, err)
+
+	// each play is challenged off
+	require.Len(t, report.Games, 1)
+	for _, player := range report.Games[0].Players {
+		assert.Equal(t, 0, player.Bingos)
+	}
+}
+
+func TestSummariseGames(t *testing.T) {
+	games := []tournament.GameResult{
+		{Turns: 20, Players: []tournament.PlayerResult{
+			{Entrant: "a", Score: 300, Bingos: 2, Outcome: tournament.Win},
+			{Entrant: "b", Score: 250, Outcome: tournament.Loss},
+		}},
+		{Turns: 30, Players: []tournament.PlayerResult{
+			{Entrant: "b", Score: 280, Bingos: 1, Outcome: tournament.Draw},
+			{Entrant: "a", Score: 280, Outcome: tournament.Draw},
+		}},
+	}
+	entrants, matchups := tournament.Summarise(games)
+	require.Len(t, entrants, 2)
+	a := entrants[0]
+	assert.Equal(t, "a", a.Entrant)
+	assert.Equal(t, 2, a.Games)
+	assert.Equal(t, 1, a.Wins)
+	assert.Equal(t, 1, a.Draws)
+	assert.Equal(t, 0.75, a.WinRate)
+	assert.InDelta(t, 0.1979, a.WinRateLow, 0.0001)
+	assert.InDelta(t, 0.9733, a.WinRateHigh, 0.0001)
+	assert.Equal(t, 25.0, a.MeanSpread)
+	assert.Equal(t, 290.0, a.MeanScore)
+	assert.Equal(t, 1.0, a.BingoRate)
+	assert.Equal(t, 25.0, a.MeanLength)
+	assert.Equal(t, -25.0, entrants[1].MeanSpread)
+
+	require.Len(t, matchups, 2)
+	assert.Equal(t, "b", matchups[0].Opponent)
+	assert.Equal(t, a.WinRate, matchups[0].WinRate)
+
+	var csv bytes.Buffer
+	require.NoError(t, tournament.WriteCSV(&csv, entrants))
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "entrant,opponent,games,wins,draws,losses,win_rate,win_rate_low,win_rate_high,mean_spread,mean_score,bingo_rate,mean_length", lines[0])
+	assert.Equal(t, "a,,2,1,1,0,0.7500,0.1979,0.9733,25.0000,290.0000,1.0000,25.0000", lines[1])
+}
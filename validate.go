@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+// runValidateConfig checks a configuration and prints a summary of it, or the
+// whole configuration as JSON. It returns the exit code.
+func runValidateConfig(args []string) int {
+	flags := newFlagSet("validate-config", "[-preset name | -config config.yaml] [-format text]")
+	rules := addRuleFlags(flags)
+	format := addFormatFlag(flags, "text", "json")
+	if !parseFlags(flags, args, 0) {
+		return exitUsage
+	}
+	return exit(validateConfig(rules, format))
+}
+
+func validateConfig(rules ruleFlags, format formatFlag) error {
+	if err := format.check(); err != nil {
+		return err
+	}
+	config, alphabet, err := rules.load()
+	if err != nil {
+		return err
+	}
+	if *format.format == "json" {
+		return writeJSON(config)
+	}
+
+	name := *rules.preset
+	if *rules.configPath != "" {
+		name = *rules.configPath
+	}
+	tiles := 0
+	for _, count := range config.LetterCounts {
+		tiles += count
+	}
+	rows, columns := config.Dimensions()
+	fmt.Printf(
+		"%v is valid: %vx%v board, %v tile racks, %v tiles with %v letters\n",
+		name, rows, columns, config.RackSize, tiles, alphabet.Size(),
+	)
+	return nil
+}